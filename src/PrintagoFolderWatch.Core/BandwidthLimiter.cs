@@ -0,0 +1,126 @@
+using System;
+using System.Diagnostics;
+using System.IO;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Token bucket shared by every upload worker, so the combined upload speed (not each file's) stays under
+    /// the limit. The limit is read on every call, so a reloaded config applies to uploads already running.
+    /// A limit of 0 or less means unlimited.
+    /// </summary>
+    public class BandwidthLimiter
+    {
+        private readonly Func<long> getBytesPerSecond;
+        private readonly object sync = new();
+        private double availableBytes;
+        private long lastRefillTicks = Stopwatch.GetTimestamp();
+
+        public BandwidthLimiter(Func<long> getBytesPerSecond)
+        {
+            this.getBytesPerSecond = getBytesPerSecond;
+        }
+
+        public bool IsLimited => getBytesPerSecond() > 0;
+
+        /// <summary>
+        /// Take bytes from the bucket, waiting for as long as it takes to refill if it's overdrawn.
+        /// At most one second's worth of unused bandwidth is saved up, so idle time doesn't allow a burst.
+        /// </summary>
+        public async Task WaitAsync(int bytes, CancellationToken ct = default)
+        {
+            var rate = getBytesPerSecond();
+            if (rate <= 0)
+                return;
+
+            TimeSpan delay;
+            lock (sync)
+            {
+                var now = Stopwatch.GetTimestamp();
+                var elapsedSeconds = (double)(now - lastRefillTicks) / Stopwatch.Frequency;
+                lastRefillTicks = now;
+
+                availableBytes = Math.Min(rate, availableBytes + elapsedSeconds * rate) - bytes;
+                delay = availableBytes < 0 ? TimeSpan.FromSeconds(-availableBytes / rate) : TimeSpan.Zero;
+            }
+
+            if (delay > TimeSpan.Zero)
+                await Task.Delay(delay, ct);
+        }
+    }
+
+    /// <summary>
+    /// Read-only stream wrapper that draws from a BandwidthLimiter before handing out each chunk.
+    /// Reads are kept small so a low limit still sends a steady trickle instead of long bursts.
+    /// onRead is called with the size of each chunk once it has been handed out.
+    /// </summary>
+    public class ThrottledStream : Stream
+    {
+        private const int MAX_CHUNK_BYTES = 16 * 1024;
+
+        private readonly Stream inner;
+        private readonly BandwidthLimiter limiter;
+        private readonly bool leaveOpen;
+        private readonly Action<int>? onRead;
+
+        public ThrottledStream(Stream inner, BandwidthLimiter limiter, bool leaveOpen = false, Action<int>? onRead = null)
+        {
+            this.inner = inner;
+            this.limiter = limiter;
+            this.leaveOpen = leaveOpen;
+            this.onRead = onRead;
+        }
+
+        public override bool CanRead => inner.CanRead;
+        public override bool CanSeek => false;
+        public override bool CanWrite => false;
+        public override long Length => inner.Length;
+
+        public override long Position
+        {
+            get => inner.Position;
+            set => throw new NotSupportedException();
+        }
+
+        public override int Read(byte[] buffer, int offset, int count)
+        {
+            var read = inner.Read(buffer, offset, Math.Min(count, MAX_CHUNK_BYTES));
+            if (read > 0)
+            {
+                limiter.WaitAsync(read).GetAwaiter().GetResult();
+                onRead?.Invoke(read);
+            }
+            return read;
+        }
+
+        public override Task<int> ReadAsync(byte[] buffer, int offset, int count, CancellationToken cancellationToken)
+        {
+            return ReadAsync(buffer.AsMemory(offset, count), cancellationToken).AsTask();
+        }
+
+        public override async ValueTask<int> ReadAsync(Memory<byte> buffer, CancellationToken cancellationToken = default)
+        {
+            var read = await inner.ReadAsync(buffer.Slice(0, Math.Min(buffer.Length, MAX_CHUNK_BYTES)), cancellationToken);
+            if (read > 0)
+            {
+                await limiter.WaitAsync(read, cancellationToken);
+                onRead?.Invoke(read);
+            }
+            return read;
+        }
+
+        public override void Flush() { }
+        public override long Seek(long offset, SeekOrigin origin) => throw new NotSupportedException();
+        public override void SetLength(long value) => throw new NotSupportedException();
+        public override void Write(byte[] buffer, int offset, int count) => throw new NotSupportedException();
+
+        protected override void Dispose(bool disposing)
+        {
+            if (disposing && !leaveOpen)
+                inner.Dispose();
+            base.Dispose(disposing);
+        }
+    }
+}
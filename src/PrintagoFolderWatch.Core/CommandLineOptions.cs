@@ -0,0 +1,155 @@
+using System;
+using System.Collections.Generic;
+using System.IO;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Command-line flags shared by the tray apps and headless mode.
+    /// Settings given here override config.json and PRINTAGO_* environment variables for this run only,
+    /// unless --save is also passed. --config likewise beats PRINTAGO_CONFIG.
+    /// </summary>
+    public class CommandLineOptions
+    {
+        public string? ConfigPath { get; set; }
+        public string? WatchPath { get; set; }
+        public string? ApiUrl { get; set; }
+        public string? ApiKey { get; set; }
+        public string? StoreId { get; set; }
+        public bool Save { get; set; }
+        public bool Headless { get; set; }
+        public bool DryRun { get; set; }
+        public bool SkipInitialScan { get; set; }
+        // Keep the API key in config.json and never touch the OS credential store (e.g. Linux without a keyring)
+        public bool NoKeychain { get; set; }
+        public bool ShowHelp { get; set; }
+
+        // "upload <file> [<file>...]" - one-shot upload, e.g. from a slicer post-processing script
+        public string? Command { get; set; }
+        public List<string> Files { get; } = new();
+
+        // Problems found while parsing (missing values, unknown flags)
+        public List<string> Errors { get; } = new();
+
+        public static CommandLineOptions Parse(string[] args)
+        {
+            var options = new CommandLineOptions();
+
+            for (int i = 0; i < args.Length; i++)
+            {
+                var arg = args[i];
+
+                // Accept both "--flag value" and "--flag=value"
+                string? inlineValue = null;
+                var eq = arg.IndexOf('=');
+                if (arg.StartsWith("--") && eq > 0)
+                {
+                    inlineValue = arg.Substring(eq + 1);
+                    arg = arg.Substring(0, eq);
+                }
+
+                string? NextValue()
+                {
+                    if (inlineValue != null)
+                        return inlineValue;
+                    if (i + 1 < args.Length && !args[i + 1].StartsWith("--"))
+                        return args[++i];
+
+                    options.Errors.Add($"{arg} needs a value");
+                    return null;
+                }
+
+                switch (arg)
+                {
+                    case "--config":
+                        var configPath = NextValue();
+                        options.ConfigPath = configPath != null ? Path.GetFullPath(configPath) : null;
+                        break;
+                    case "--watch":
+                        options.WatchPath = NextValue();
+                        break;
+                    case "--api-url":
+                        options.ApiUrl = NextValue();
+                        break;
+                    case "--api-key":
+                        options.ApiKey = NextValue();
+                        break;
+                    case "--store-id":
+                        options.StoreId = NextValue();
+                        break;
+                    case "--save":
+                        options.Save = true;
+                        break;
+                    case "--dry-run":
+                        options.DryRun = true;
+                        break;
+                    case "--skip-initial-scan":
+                        options.SkipInitialScan = true;
+                        break;
+                    case "--no-keychain":
+                        options.NoKeychain = true;
+                        break;
+                    case "--help":
+                    case "-h":
+                    case "-?":
+                        options.ShowHelp = true;
+                        break;
+                    case "--headless":
+                    case "--no-tray":
+                        options.Headless = true;
+                        break;
+                    default:
+                        if (arg.StartsWith("--"))
+                            options.Errors.Add($"Unknown option: {arg}");
+                        else if (options.Command == null && arg == "upload")
+                            options.Command = arg;
+                        else if (options.Command != null)
+                            options.Files.Add(arg);
+                        break;
+                }
+            }
+
+            // Read here rather than in Config so the single-instance check sees it too
+            var configFromEnvironment = Environment.GetEnvironmentVariable("PRINTAGO_CONFIG");
+            if (options.ConfigPath == null && !string.IsNullOrWhiteSpace(configFromEnvironment))
+                options.ConfigPath = Path.GetFullPath(configFromEnvironment);
+
+            return options;
+        }
+
+        public const string HELP_TEXT = @"Usage: PrintagoFolderWatch [options]
+       PrintagoFolderWatch upload <file> [<file>...]
+
+Options:
+  --config <path>       Use this config file instead of the default one
+  --watch <path>        Override WatchPath
+  --api-url <url>       Override ApiUrl
+  --api-key <key>       Override ApiKey
+  --store-id <id>       Override StoreId
+  --save                Write the --watch/--api-url/--api-key/--store-id values into the config file
+  --headless            Run without a tray icon (also --no-tray)
+  --dry-run             Log what would be uploaded, moved or deleted without changing anything
+  --skip-initial-scan   Start watching without walking the watch folders first
+  --no-keychain         Keep the API key in the config file, never in the OS credential store
+  --help                Show this help
+
+Environment variables:
+  PRINTAGO_CONFIG       Config file to use (like --config)
+  PRINTAGO_WATCH_PATH, PRINTAGO_API_URL, PRINTAGO_API_KEY, PRINTAGO_STORE_ID
+                        Override the matching settings
+
+Precedence: command-line options > environment variables > config file.
+Environment values are never written to the config file; option values only with --save.";
+
+        /// <summary>
+        /// Setting overrides as (Config property name, value), for the flags that were given
+        /// </summary>
+        public IEnumerable<(string property, string value)> GetSettingOverrides()
+        {
+            if (WatchPath != null) yield return (nameof(Config.WatchPath), WatchPath);
+            if (ApiUrl != null) yield return (nameof(Config.ApiUrl), ApiUrl);
+            if (ApiKey != null) yield return (nameof(Config.ApiKey), ApiKey);
+            if (StoreId != null) yield return (nameof(Config.StoreId), StoreId);
+        }
+    }
+}
@@ -1,33 +1,795 @@
 using System;
 using System.Collections.Generic;
 using System.IO;
+using System.Linq;
+using System.Text;
 using Newtonsoft.Json;
+using Newtonsoft.Json.Linq;
+using PrintagoFolderWatch.Core.Models;
 
 namespace PrintagoFolderWatch.Core
 {
     public class Config
     {
-        private static readonly string ConfigDir = Path.Combine(
+        private static readonly string DefaultConfigFile = Path.Combine(
             Environment.GetFolderPath(Environment.SpecialFolder.UserProfile),
-            ".printago-folder-watch"
+            ".printago-folder-watch",
+            "config.json"
         );
-        private static readonly string ConfigFile = Path.Combine(ConfigDir, "config.json");
+
+        // Path of the config file in use (--config overrides the default)
+        public static string ConfigFile { get; private set; } = DefaultConfigFile;
+        private static string ConfigDir => Path.GetDirectoryName(ConfigFile)!;
+
+        // Rotating log file, kept next to the config file it belongs to
+        public static string LogFile => Path.Combine(ConfigDir, "logs", "app.log");
+
+        // Copy of the failed-uploads list, rewritten whenever it changes (for headless installs)
+        public static string FailedUploadsFile => Path.Combine(ConfigDir, "failed.json");
+
+        // Where an upload queue lane ("live" or "scan") spills past MaxQueuedInMemory, e.g. config.scan.queue
+        public static string GetQueueJournalFile(string lane) =>
+            Path.Combine(ConfigDir, $"{Path.GetFileNameWithoutExtension(ConfigFile)}.{lane}.queue");
+
+        // Modified time of the config file after our last Save, so the file watcher can ignore our own writes
+        public static DateTime LastSavedWriteUtc { get; private set; }
+
+        // False with --no-keychain: the API key stays in config.json and the OS credential store isn't used
+        private static bool UseKeychain => CommandLine?.NoKeychain != true;
+        // A plaintext key found in the file is moved to the credential store at most once per run
+        private static bool keychainMigrationTried;
+
+        // Flags for this run (set once at startup, before the first Load)
+        public static CommandLineOptions? CommandLine { get; private set; }
+
+        // Environment variables that override settings (command-line flags win over these)
+        private static readonly (string variable, string property)[] EnvironmentOverrides =
+        {
+            ("PRINTAGO_WATCH_PATH", nameof(WatchPath)),
+            ("PRINTAGO_API_URL", nameof(ApiUrl)),
+            ("PRINTAGO_API_KEY", nameof(ApiKey)),
+            ("PRINTAGO_STORE_ID", nameof(StoreId))
+        };
+
+        // Settings replaced by environment variables or flags: property -> (value from file, override value, whether the
+        // override came from the environment rather than a flag)
+        private readonly Dictionary<string, (string fileValue, string overrideValue, bool fromEnvironment)> overriddenSettings = new();
+
+        private const string DEFAULT_CONTENT_TYPE = "application/octet-stream";
+        private static readonly Dictionary<string, string> DefaultContentTypes = new(StringComparer.OrdinalIgnoreCase)
+        {
+            [".stl"] = "model/stl",
+            [".3mf"] = "model/3mf",
+            [".step"] = "model/step",
+            [".stp"] = "model/step",
+            [".gcode"] = "text/x.gcode",
+            [".scad"] = "application/x-openscad",
+            [".png"] = "image/png"
+        };
 
         public string WatchPath { get; set; } = "";
+
+        // Every watched folder, each with optional settings of its own. The first entry is WatchPath (kept in
+        // sync, so the Settings window, PRINTAGO_WATCH_PATH and --watch still set it); more entries add folders.
+        public List<WatchEntry> Watches { get; set; } = new();
         public string ApiUrl { get; set; } = "";
         public string ApiKey { get; set; } = "";
         public string StoreId { get; set; } = "";
 
+        // Set when Save put ApiKey in the OS credential store; the file then has an empty ApiKey
+        public bool ApiKeyInKeychain { get; set; }
+
+        // Optional extension whitelist (e.g. ".stl", ".3mf"). Empty = upload every supported type.
+        public List<string> IncludeExtensions { get; set; } = new();
+
+        // Glob patterns (relative to WatchPath) to never sync, e.g. "*.tmp", "**/drafts/**".
+        // Patterns from a .printagoignore file in the watch folder are added to these.
+        public List<string> ExcludePatterns { get; set; } = new();
+
+        // Also sync dotfiles and dot-folders, and (on Windows) files and folders with the hidden attribute.
+        // Temp, backup and OS files (PathFilter.BUILT_IN_PATTERNS) are skipped either way.
+        public bool UploadHiddenFiles { get; set; } = false;
+
+        // Cloud-only files on Windows (OneDrive Files On-Demand): "skip" them, "hydrate" them (read them anyway,
+        // which downloads them), or "notify" - skip them and show how many were skipped
+        public string PlaceholderAction { get; set; } = "skip";
+
+        // How many times a failed upload is retried (with exponential backoff) before giving up
+        public int MaxRetries { get; set; } = 5;
+
+        // Number of files uploaded at the same time
+        public int MaxParallelUploads { get; set; } = 10;
+
+        // Combined upload speed of all parallel uploads, in bytes per second. 0 = unlimited.
+        public long MaxUploadBytesPerSec { get; set; } = 0;
+
+        // Most files whose signed upload URLs are fetched in one API request
+        public int SignedUrlBatchSize { get; set; } = 50;
+
+        // Most Printago API requests per second, across all workers (storage uploads aren't counted). 0 = no limit.
+        public double RequestsPerSecond { get; set; } = 0.5;
+        public const double MIN_REQUESTS_PER_SECOND = 0.01; // One request every 100s - slower is almost certainly a typo
+
+        // After each upload, fetch the Part back and check Printago has the file's hash (one more API call per upload)
+        public bool VerifyUploads { get; set; } = false;
+
+        // Files kept in memory per upload queue lane; the rest wait in a journal file next to the config file
+        public int MaxQueuedInMemory { get; set; } = 10000;
+
+        // Where the API lives under ApiUrl and how requests authenticate - for a newer API version or a mock server.
+        // Every endpoint is ApiUrl + ApiBasePath + its path; SignedUploadUrlsPath is relative to ApiBasePath.
+        public string ApiBasePath { get; set; } = "/v1";
+        public string SignedUploadUrlsPath { get; set; } = "storage/signed-upload-urls";
+        public string ApiKeyHeader { get; set; } = "authorization";
+        // Sent before the key: "ApiKey <key>". Empty = just the key.
+        public string ApiKeyScheme { get; set; } = "ApiKey";
+        public string StoreIdHeader { get; set; } = "x-printago-storeid";
+
+        // A file is only queued once it has had no change events, and is only uploaded once its size and
+        // modified time have stayed the same, for this long. 0 = no wait.
+        public int DebounceMs { get; set; } = 2000;
+
+        // Give up waiting for a file that keeps changing (e.g. a growing log) after this long
+        public int MaxStableWaitSeconds { get; set; } = 300;
+
+        // How long Exit / Ctrl-C waits for queued and in-progress uploads to finish
+        public int ShutdownTimeoutSeconds { get; set; } = 30;
+
+        // Re-list Printago and re-walk the watch folders this often, to catch changes the watchers missed (0 = never)
+        public int RescanMinutes { get; set; } = 30;
+
+        // Also look for new and changed files by listing the watch folders this often, for network shares whose changes
+        // made by other machines never raise events (0 = only for folders that can't be watched at all)
+        public int PollIntervalSeconds { get; set; } = 0;
+
+        // A watch folder that isn't there (unmounted network drive, OneDrive not signed in yet) is checked for
+        // again this often instead of failing the start, and one that disappears is re-watched when it returns.
+        // 0 = a missing watch folder stops watching from starting.
+        public int WatchPathRetrySeconds { get; set; } = 30;
+
+        // Abort an upload to storage when no bytes have been sent for this long (0 = never). There is no limit
+        // on the total time, so large files on a slow link aren't cut off.
+        public int UploadTimeoutSeconds { get; set; } = 60;
+
+        // Delete the Part in Printago when its local file is deleted (or moved out of WatchPath). Off by default
+        // so removing files locally never loses Part settings unexpectedly.
+        public bool SyncDeletes { get; set; } = false;
+
+        // With SyncDeletes, only log the Parts that would be deleted (uploads still happen) - to check it first
+        public bool DryRunDeletes { get; set; } = false;
+
+        // What to do with a local file once it has been uploaded: "none", "delete", or "move" (to MoveToDir,
+        // keeping its folder structure). For intake folders that should empty themselves. Watches entries can
+        // set their own. MoveToDir may be inside a watch folder - it is never watched.
+        public string PostUploadAction { get; set; } = "none";
+        public string MoveToDir { get; set; } = "";
+
+        // Desktop notifications: "all", "errors" (failures only), "none", or a comma-separated list of events
+        // like "startstop,files,errors" (see the NOTIFY_* names)
+        public string Notifications { get; set; } = "all";
+
+        public const string NOTIFY_START_STOP = "startstop"; // Watching started/stopped, config reloaded
+        public const string NOTIFY_UPLOADS = "uploads";      // One summary per batch of uploads
+        public const string NOTIFY_FILES = "files";          // Every uploaded file
+        public const string NOTIFY_ERRORS = "errors";        // Failed uploads/deletes, config problems
+
+        // Log what would be uploaded, moved or deleted instead of changing anything in Printago (also --dry-run)
+        public bool DryRun { get; set; } = false;
+
+        // Start watching straight away instead of walking the watch folders, once a full scan has completed
+        // (also --skip-initial-scan). Changes made while the app wasn't running wait for Sync Now.
+        public bool SkipInitialScan { get; set; } = false;
+
+        // Off = files already in the watch folders the first time it starts with this off are left alone; only files
+        // created, copied in or changed after that are uploaded
+        public bool UploadExisting { get; set; } = true;
+
+        // Tray apps start with uploads paused (Resume Uploads in the tray menu); changes are still queued
+        public bool PauseOnStart { get; set; } = false;
+
+        // Least severe level written to the log file: "DEBUG", "INFO", "WARN" or "ERROR"
+        public string LogLevel { get; set; } = "INFO";
+
+        // Extension -> Content-Type for storage uploads, e.g. { ".gcode": "text/x-gcode" }. Checked before the built-in types.
+        public Dictionary<string, string> ContentTypeOverrides { get; set; } = new();
+
+        // Storage path for uploads built from {relpath}, {filename}, {ext}, {date} (YYYY-MM-DD) and {hostname},
+        // e.g. "incoming/{date}/{relpath}". Empty = the relative path. Printago folders still follow the local folders.
+        public string CloudPathTemplate { get; set; } = "";
+
+        private static readonly string[] CloudPathPlaceholders = { "relpath", "filename", "ext", "date", "hostname" };
+
+        // Send a Content-MD5 header with storage uploads so the storage service rejects corrupted transfers.
+        // Turn off for providers whose signed URLs don't allow the extra header.
+        public bool SendContentMd5 { get; set; } = true;
+
         public bool IsValid()
         {
-            return !string.IsNullOrWhiteSpace(WatchPath) &&
-                   !string.IsNullOrWhiteSpace(ApiUrl) &&
-                   !string.IsNullOrWhiteSpace(ApiKey) &&
-                   !string.IsNullOrWhiteSpace(StoreId);
+            return Validate().Count == 0;
+        }
+
+        /// <summary>
+        /// Everything that would stop watching from working, as messages to show the user. Empty when usable.
+        /// </summary>
+        public List<string> Validate() => Validate(requireWatchFolders: false);
+
+        /// <summary>
+        /// Validate, and with requireWatchFolders also treat a missing watch folder as a problem even though it
+        /// would be waited for (Settings windows: a folder that isn't there is more likely a typo). LoadError isn't
+        /// reported then, as saving from the window replaces the unreadable file.
+        /// </summary>
+        public List<string> Validate(bool requireWatchFolders)
+        {
+            var waitForFolders = WatchPathRetrySeconds > 0 && !requireWatchFolders;
+            var errors = GetMissingSettings().Select(name => $"{name} is not set").ToList();
+
+            // Otherwise the settings it contains would just be reported as missing
+            if (LoadError != null && !requireWatchFolders)
+                errors.Insert(0, $"{Path.GetFileName(ConfigFile)} could not be read: {LoadError}");
+
+            // Missing folders are waited for, unless that's turned off
+            if (!string.IsNullOrWhiteSpace(WatchPath) && File.Exists(WatchPath))
+                errors.Add($"WatchPath is a file, not a folder: {WatchPath}");
+            else if (!string.IsNullOrWhiteSpace(WatchPath) && !Directory.Exists(WatchPath) && !waitForFolders)
+                errors.Add($"WatchPath does not exist: {WatchPath}");
+
+            if (!string.IsNullOrWhiteSpace(ApiUrl) &&
+                !(Uri.TryCreate(ApiUrl, UriKind.Absolute, out var uri) && (uri.Scheme == Uri.UriSchemeHttps || uri.Scheme == Uri.UriSchemeHttp)))
+            {
+                errors.Add($"ApiUrl is not an http(s) URL: {ApiUrl}");
+            }
+
+            var watches = GetWatches();
+            for (int i = 1; i < watches.Count; i++)
+            {
+                var path = watches[i].Path;
+                if (string.IsNullOrWhiteSpace(path))
+                    errors.Add($"Watches[{i}].Path is not set");
+                else if (File.Exists(path))
+                    errors.Add($"Watch folder is a file, not a folder: {path}");
+                else if (!Directory.Exists(path) && !waitForFolders)
+                    errors.Add($"Watch folder does not exist: {path}");
+            }
+
+            for (int i = 0; i < watches.Count; i++)
+            {
+                var prefix = watches[i].GetCloudPrefix();
+                if (prefix.Split('/').Any(segment => segment == "." || segment == ".."))
+                {
+                    errors.Add($"Watches[{i}].CloudPrefix can't contain \".\" or \"..\": {watches[i].CloudPrefix}");
+                }
+
+                for (int j = i + 1; j < watches.Count; j++)
+                {
+                    // Files with the same relative path in both would overwrite each other's Parts
+                    if (string.Equals(prefix, watches[j].GetCloudPrefix(), StringComparison.OrdinalIgnoreCase))
+                    {
+                        errors.Add($"Watches[{i}] and Watches[{j}] have the same CloudPrefix - each folder needs its own: \"{prefix}\"");
+                    }
+
+                    if (string.IsNullOrWhiteSpace(watches[i].Path) || string.IsNullOrWhiteSpace(watches[j].Path))
+                        continue;
+
+                    // Files in the inner folder would be seen twice
+                    if (IsSameOrInside(watches[i].Path, watches[j].Path) || IsSameOrInside(watches[j].Path, watches[i].Path))
+                    {
+                        errors.Add($"Watch folders can't be inside each other: {watches[i].Path}, {watches[j].Path}");
+                    }
+                }
+            }
+
+            var templateError = ValidateCloudPathTemplate();
+            if (templateError != null)
+            {
+                errors.Add($"CloudPathTemplate {templateError}: {CloudPathTemplate}");
+            }
+
+            foreach (var (name, header) in new[] { (nameof(ApiKeyHeader), ApiKeyHeader), (nameof(StoreIdHeader), StoreIdHeader) })
+            {
+                if (!IsHeaderName(header))
+                    errors.Add($"{name} is not a valid HTTP header name: \"{header}\"");
+            }
+
+            if (RequestsPerSecond < 0 || double.IsNaN(RequestsPerSecond))
+            {
+                errors.Add($"{nameof(RequestsPerSecond)} can't be negative: {RequestsPerSecond}");
+            }
+            else if (RequestsPerSecond > 0 && RequestsPerSecond < MIN_REQUESTS_PER_SECOND)
+            {
+                errors.Add($"{nameof(RequestsPerSecond)} must be 0 (no limit) or at least {MIN_REQUESTS_PER_SECOND}: {RequestsPerSecond}");
+            }
+
+            if (string.IsNullOrWhiteSpace(SignedUploadUrlsPath))
+            {
+                errors.Add($"{nameof(SignedUploadUrlsPath)} is not set");
+            }
+
+            if (GetPlaceholderAction() is not ("skip" or "hydrate" or "notify"))
+            {
+                errors.Add($"PlaceholderAction must be skip, hydrate or notify: {PlaceholderAction}");
+            }
+
+            ValidatePostUploadAction(null, "", errors);
+            for (int i = 0; i < watches.Count; i++)
+            {
+                if (!string.IsNullOrWhiteSpace(watches[i].PostUploadAction) || !string.IsNullOrWhiteSpace(watches[i].MoveToDir))
+                {
+                    ValidatePostUploadAction(watches[i], $"Watches[{i}].", errors);
+                }
+            }
+
+            return errors;
+        }
+
+        private void ValidatePostUploadAction(WatchEntry? watch, string label, List<string> errors)
+        {
+            var postUploadAction = GetPostUploadAction(watch);
+            var moveToDir = GetMoveToDir(watch);
+            if (postUploadAction is not ("none" or "delete" or "move"))
+            {
+                errors.Add($"{label}PostUploadAction must be none, delete or move: {watch?.PostUploadAction ?? PostUploadAction}");
+            }
+            else if (postUploadAction != "none" && SyncDeletes)
+            {
+                // Removing the file after upload would then delete the Part that was just uploaded
+                errors.Add($"{label}PostUploadAction can't be used together with SyncDeletes");
+            }
+            else if (postUploadAction == "move")
+            {
+                if (string.IsNullOrWhiteSpace(moveToDir))
+                {
+                    errors.Add($"{label}MoveToDir is not set (needed for PostUploadAction \"move\")");
+                }
+                else if (GetWatches().Any(w => !string.IsNullOrWhiteSpace(w.Path) && IsSameOrInside(w.Path, moveToDir)))
+                {
+                    // It's left unwatched, so the watch folder itself would be skipped
+                    errors.Add($"{label}MoveToDir can't be a watch folder or contain one: {moveToDir}");
+                }
+            }
+        }
+
+        /// <summary>
+        /// The watched folders: Watches with WatchPath as the first one, or just WatchPath for a config
+        /// from before Watches existed. Empty when WatchPath isn't set. A new list (and first entry) each time -
+        /// Watches itself is never changed, since workers look folders up while Settings or a reload replace it.
+        /// </summary>
+        public List<WatchEntry> GetWatches()
+        {
+            var watches = (Watches ?? new List<WatchEntry>()).Where(w => w != null).ToList();
+
+            if (watches.Count == 0)
+            {
+                if (string.IsNullOrWhiteSpace(WatchPath))
+                    return new List<WatchEntry>();
+
+                return new List<WatchEntry> { new WatchEntry { Path = WatchPath } };
+            }
+
+            watches[0] = watches[0].WithPath(WatchPath);
+            return watches;
+        }
+
+        /// <summary>
+        /// The watch folder a path is in (the innermost, if they were nested), or null if it's outside all of them
+        /// </summary>
+        public WatchEntry? FindWatch(string path)
+        {
+            return GetWatches()
+                .Where(w => !string.IsNullOrWhiteSpace(w.Path) && IsSameOrInside(path, w.Path))
+                .OrderByDescending(w => w.Path.Length)
+                .FirstOrDefault();
+        }
+
+        /// <summary>
+        /// True if both configs watch the same folders with the same cloud prefixes
+        /// </summary>
+        public bool HasSameWatches(Config other)
+        {
+            static string Describe(Config config) => string.Join("|", config.GetWatches()
+                .Select(w => $"{w.Path.Trim().TrimEnd('/', '\\')}>{w.GetCloudPrefix()}".ToLowerInvariant()));
+
+            return Describe(this) == Describe(other);
+        }
+
+        /// <summary>
+        /// Names of the required settings that are empty
+        /// </summary>
+        public List<string> GetMissingSettings()
+        {
+            var missing = new List<string>();
+            if (string.IsNullOrWhiteSpace(WatchPath)) missing.Add(nameof(WatchPath));
+            if (string.IsNullOrWhiteSpace(ApiUrl)) missing.Add(nameof(ApiUrl));
+            if (string.IsNullOrWhiteSpace(ApiKey)) missing.Add(nameof(ApiKey));
+            if (string.IsNullOrWhiteSpace(StoreId)) missing.Add(nameof(StoreId));
+            return missing;
+        }
+
+        /// <summary>
+        /// PlaceholderAction, trimmed and lower-case ("skip" when empty)
+        /// </summary>
+        public string GetPlaceholderAction()
+        {
+            var action = PlaceholderAction?.Trim().ToLowerInvariant();
+            return string.IsNullOrEmpty(action) ? "skip" : action;
+        }
+
+        /// <summary>
+        /// PostUploadAction for a watch folder (its own, or the global one), trimmed and lower-case ("none" when empty)
+        /// </summary>
+        public string GetPostUploadAction(WatchEntry? watch = null)
+        {
+            var action = (string.IsNullOrWhiteSpace(watch?.PostUploadAction) ? PostUploadAction : watch.PostUploadAction)?.Trim().ToLowerInvariant();
+            return string.IsNullOrEmpty(action) ? "none" : action;
+        }
+
+        public string GetMoveToDir(WatchEntry? watch = null)
+        {
+            return string.IsNullOrWhiteSpace(watch?.MoveToDir) ? MoveToDir : watch.MoveToDir!;
+        }
+
+        /// <summary>
+        /// True for paths in a folder that uploaded files are moved to. These are never watched or uploaded,
+        /// so an archive inside a watch folder doesn't feed its files back into the queue.
+        /// </summary>
+        public bool IsInMoveToDir(string path)
+        {
+            return GetWatches()
+                .Where(w => GetPostUploadAction(w) == "move")
+                .Select(GetMoveToDir)
+                .Append(GetPostUploadAction() == "move" ? MoveToDir : "")
+                .Where(dir => !string.IsNullOrWhiteSpace(dir))
+                .Any(dir => IsSameOrInside(path, dir));
+        }
+
+        // RFC 9110 token characters
+        private static bool IsHeaderName(string name)
+        {
+            return name.Length > 0 && name.All(c => char.IsAsciiLetterOrDigit(c) || "!#$%&'*+-.^_`|~".Contains(c));
+        }
+
+        /// <summary>
+        /// Shortest gap between two API requests for RequestsPerSecond (zero when unlimited), clamped so a
+        /// tiny value that skipped validation can't overflow TimeSpan
+        /// </summary>
+        public TimeSpan GetMinimumApiInterval()
+        {
+            if (!(RequestsPerSecond > 0))
+                return TimeSpan.Zero;
+
+            return TimeSpan.FromSeconds(1 / Math.Max(RequestsPerSecond, MIN_REQUESTS_PER_SECOND));
+        }
+
+        /// <summary>
+        /// An API endpoint's path under ApiUrl: GetApiPath("parts") = "/v1/parts" with the default ApiBasePath
+        /// </summary>
+        public string GetApiPath(string path)
+        {
+            var basePath = ApiBasePath.Trim().Trim('/');
+            return (basePath.Length > 0 ? $"/{basePath}/" : "/") + path.Trim().TrimStart('/');
         }
 
+        /// <summary>
+        /// The ApiKeyHeader value: "ApiKey &lt;key&gt;", or just the key without an ApiKeyScheme
+        /// </summary>
+        public string GetApiKeyHeaderValue()
+        {
+            return string.IsNullOrWhiteSpace(ApiKeyScheme) ? ApiKey : $"{ApiKeyScheme.Trim()} {ApiKey}";
+        }
+
+        private static bool IsSameOrInside(string path, string folder)
+        {
+            var relative = Path.GetRelativePath(Path.GetFullPath(folder), Path.GetFullPath(path));
+            return relative == "." || (!relative.StartsWith("..") && !Path.IsPathRooted(relative));
+        }
+
+        /// <summary>
+        /// DryRun from the config file or --dry-run for this run. The flag is never written to the file.
+        /// </summary>
+        public bool IsDryRun()
+        {
+            return DryRun || CommandLine?.DryRun == true;
+        }
+
+        /// <summary>
+        /// SkipInitialScan from the config file or --skip-initial-scan for this run
+        /// </summary>
+        public bool IsSkipInitialScan()
+        {
+            return SkipInitialScan || CommandLine?.SkipInitialScan == true;
+        }
+
+        /// <summary>
+        /// Whether a notification for this event (a NOTIFY_* name) should be shown under the Notifications setting.
+        /// "all" is everything except per-file notifications; unknown values behave like "all".
+        /// </summary>
+        public bool ShouldNotify(string notifyEvent)
+        {
+            var setting = Notifications?.Trim().ToLowerInvariant() ?? "";
+            switch (setting)
+            {
+                case "none":
+                    return false;
+                case "errors":
+                    return notifyEvent == NOTIFY_ERRORS;
+                case "":
+                case "all":
+                    return notifyEvent != NOTIFY_FILES;
+            }
+
+            var events = setting.Split(',', StringSplitOptions.RemoveEmptyEntries | StringSplitOptions.TrimEntries);
+            var known = new[] { NOTIFY_START_STOP, NOTIFY_UPLOADS, NOTIFY_FILES, NOTIFY_ERRORS };
+            if (!events.Any(known.Contains))
+                return notifyEvent != NOTIFY_FILES;
+
+            return events.Contains(notifyEvent);
+        }
+
+        /// <summary>
+        /// Whether a message at this level goes to the log file under the LogLevel setting. Levels other than
+        /// DEBUG/WARN/ERROR (SUCCESS, MOVE, RENAME...) count as INFO, as do unknown LogLevel values.
+        /// </summary>
+        public bool ShouldLog(string level)
+        {
+            return GetLogSeverity(level) >= GetLogSeverity(LogLevel);
+        }
+
+        private static int GetLogSeverity(string? level)
+        {
+            return level?.Trim().ToUpperInvariant() switch
+            {
+                "DEBUG" => 0,
+                "WARN" or "WARNING" => 2,
+                "ERROR" => 3,
+                _ => 1
+            };
+        }
+
+        /// <summary>
+        /// Check a file against IncludeExtensions (case-insensitive), or its watch folder's own list when it has one.
+        /// Always true when the list is empty. Uses EndsWith so compound extensions like ".gcode.3mf" can be listed.
+        /// </summary>
+        public bool IsExtensionIncluded(string filePath)
+        {
+            var includeExtensions = FindWatch(filePath)?.IncludeExtensions ?? IncludeExtensions;
+            if (includeExtensions == null || includeExtensions.Count == 0)
+                return true;
+
+            var fileName = Path.GetFileName(filePath).ToLowerInvariant();
+            if (!fileName.Contains('.'))
+                return false;
+
+            return includeExtensions
+                .Where(ext => !string.IsNullOrWhiteSpace(ext))
+                .Select(ext => ext.Trim().ToLowerInvariant())
+                .Select(ext => ext.StartsWith(".") ? ext : $".{ext}")
+                .Any(ext => fileName.EndsWith(ext));
+        }
+
+        /// <summary>
+        /// Why CloudPathTemplate can't be used (unknown placeholder, stray brace...), or null if it's fine
+        /// </summary>
+        public string? ValidateCloudPathTemplate()
+        {
+            if (string.IsNullOrWhiteSpace(CloudPathTemplate))
+                return null;
+
+            var template = CloudPathTemplate.Trim();
+            var placeholders = new List<string>();
+            for (int i = 0; i < template.Length; i++)
+            {
+                if (template[i] == '}')
+                    return "has a \"}\" without a matching \"{\"";
+                if (template[i] != '{')
+                    continue;
+
+                var end = template.IndexOf('}', i + 1);
+                if (end < 0)
+                    return "has a \"{\" without a matching \"}\"";
+
+                var name = template.Substring(i + 1, end - i - 1);
+                if (!CloudPathPlaceholders.Contains(name))
+                    return $"has an unknown placeholder {{{name}}} (use {string.Join(", ", CloudPathPlaceholders.Select(p => $"{{{p}}}"))})";
+
+                placeholders.Add(name);
+                i = end;
+            }
+
+            // Otherwise every file would be uploaded to the same path
+            if (!placeholders.Contains("relpath") && !placeholders.Contains("filename"))
+                return "must contain {relpath} or {filename}";
+
+            if (template.Replace('\\', '/').Split('/').Any(segment => segment == ".."))
+                return "can't contain \"..\"";
+
+            return null;
+        }
+
+        /// <summary>
+        /// Storage path for a file from CloudPathTemplate, with forward slashes and no empty segments.
+        /// relativePath is the file's path under its watch folder (and CloudPrefix).
+        /// </summary>
+        public string FormatCloudPath(string relativePath)
+        {
+            relativePath = ToCloudPath(relativePath);
+            if (string.IsNullOrWhiteSpace(CloudPathTemplate) || ValidateCloudPathTemplate() != null)
+                return relativePath;
+
+            var fileName = Path.GetFileName(relativePath);
+            var path = CloudPathTemplate.Trim()
+                .Replace("{relpath}", relativePath)
+                .Replace("{filename}", fileName)
+                .Replace("{ext}", Path.GetExtension(fileName).TrimStart('.'))
+                .Replace("{date}", DateTime.Now.ToString("yyyy-MM-dd"))
+                .Replace("{hostname}", Environment.MachineName);
+
+            return ToCloudPath(path);
+        }
+
+        /// <summary>
+        /// A relative path as a storage/Printago path: "/" between folders whichever OS it came from, with empty and
+        /// "." segments dropped, so "a\\b//./c.stl" and "a/b/c.stl" are the same file on Windows, macOS and Linux.
+        /// A backslash counts as a separator everywhere, since Windows can't have one in a name.
+        /// </summary>
+        public static string ToCloudPath(string path)
+        {
+            return string.Join("/", path.Split('/', '\\').Where(segment => segment.Length > 0 && segment != "."));
+        }
+
+        /// <summary>
+        /// The folder part of a cloud path ("" at the root): "a/b/c.stl" -> "a/b"
+        /// </summary>
+        public static string GetCloudFolder(string cloudPath)
+        {
+            cloudPath = ToCloudPath(cloudPath);
+            var slash = cloudPath.LastIndexOf('/');
+            return slash < 0 ? "" : cloudPath.Substring(0, slash);
+        }
+
+        /// <summary>
+        /// Content-Type for a storage upload: ContentTypeOverrides (longest matching extension wins),
+        /// then the built-in model types, then a guess from the file's first bytes.
+        /// </summary>
+        public string GetContentType(string filePath)
+        {
+            var fileName = Path.GetFileName(filePath).ToLowerInvariant();
+
+            if (ContentTypeOverrides != null)
+            {
+                var match = ContentTypeOverrides
+                    .Where(kvp => !string.IsNullOrWhiteSpace(kvp.Key) && !string.IsNullOrWhiteSpace(kvp.Value))
+                    .Select(kvp => (ext: kvp.Key.Trim().ToLowerInvariant(), type: kvp.Value.Trim()))
+                    .Select(o => (ext: o.ext.StartsWith(".") ? o.ext : $".{o.ext}", o.type))
+                    .Where(o => fileName.EndsWith(o.ext))
+                    .OrderByDescending(o => o.ext.Length)
+                    .FirstOrDefault();
+
+                if (match.type != null)
+                    return match.type;
+            }
+
+            return DefaultContentTypes.TryGetValue(Path.GetExtension(fileName), out var contentType)
+                ? contentType
+                : SniffContentType(filePath);
+        }
+
+        /// <summary>
+        /// Content-Type from a file's signature for extensions with no known type: zip (3MF-style packages),
+        /// PNG, ASCII STL, and other plain text. application/octet-stream if nothing matches or it can't be read.
+        /// </summary>
+        private static string SniffContentType(string filePath)
+        {
+            var header = new byte[512];
+            int read;
+            try
+            {
+                using var stream = new FileStream(filePath, FileMode.Open, FileAccess.Read, FileShare.ReadWrite | FileShare.Delete);
+                read = stream.Read(header, 0, header.Length);
+            }
+            catch (Exception)
+            {
+                return DEFAULT_CONTENT_TYPE;
+            }
+
+            var bytes = header.AsSpan(0, read);
+            if (bytes.StartsWith(new byte[] { 0x50, 0x4B, 0x03, 0x04 }))
+                return "application/zip";
+            if (bytes.StartsWith(new byte[] { 0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A }))
+                return "image/png";
+            if (read == 0)
+                return DEFAULT_CONTENT_TYPE;
+
+            // Text if there are no control characters other than whitespace
+            foreach (var b in bytes)
+            {
+                if (b < 0x20 && b != (byte)'\t' && b != (byte)'\n' && b != (byte)'\r' && b != 0x0C)
+                    return DEFAULT_CONTENT_TYPE;
+            }
+
+            return Encoding.ASCII.GetString(bytes).TrimStart().StartsWith("solid ", StringComparison.OrdinalIgnoreCase)
+                ? "model/stl"
+                : "text/plain";
+        }
+
+        public static void UseCommandLine(CommandLineOptions options)
+        {
+            CommandLine = options;
+            if (!string.IsNullOrWhiteSpace(options.ConfigPath))
+            {
+                ConfigFile = options.ConfigPath;
+            }
+        }
+
+        /// <summary>
+        /// Load at startup. A file that can't be read or parsed gives an empty config, with the reason in LoadError.
+        /// </summary>
         public static Config Load()
         {
+            var config = LoadFromFile(out var error);
+            LoadError = error;
+            config.ApplyOverrides();
+            return config;
+        }
+
+        /// <summary>
+        /// Why the config file couldn't be used at startup (e.g. a JSON error with its line and position), until
+        /// it's saved or reloaded successfully. Reported first by Validate.
+        /// </summary>
+        public static string? LoadError { get; private set; }
+
+        /// <summary>
+        /// Load for a reload: unlike Load, a file that can't be read or parsed is reported instead of
+        /// falling back to an empty config. Returns null with the reason in error.
+        /// </summary>
+        public static Config? TryLoad(out string? error)
+        {
+            var config = LoadFromFile(out error);
+            if (error != null)
+                return null;
+
+            LoadError = null;
+            config.ApplyOverrides();
+            return config;
+        }
+
+        /// <summary>
+        /// Precedence is flags > environment > file. With --save the flag values are written back straight away;
+        /// environment values are never saved.
+        /// </summary>
+        private void ApplyOverrides()
+        {
+            var overrides = new List<(string property, string value, bool fromEnvironment)>();
+
+            foreach (var (variable, property) in EnvironmentOverrides)
+            {
+                var value = Environment.GetEnvironmentVariable(variable);
+                if (!string.IsNullOrEmpty(value))
+                    overrides.Add((property, value, true));
+            }
+
+            if (CommandLine != null)
+            {
+                overrides.AddRange(CommandLine.GetSettingOverrides().Select(o => (o.property, o.value, false)));
+            }
+
+            foreach (var (property, value, fromEnvironment) in overrides)
+            {
+                var prop = typeof(Config).GetProperty(property)!;
+                var fileValue = overriddenSettings.TryGetValue(property, out var earlier)
+                    ? earlier.fileValue
+                    : (string?)prop.GetValue(this) ?? "";
+                overriddenSettings[property] = (fileValue, value, fromEnvironment);
+                prop.SetValue(this, value);
+            }
+
+            if (CommandLine?.Save == true && overriddenSettings.Values.Any(o => !o.fromEnvironment))
+            {
+                Save();
+            }
+        }
+
+        private static Config LoadFromFile(out string? error)
+        {
+            error = null;
             try
             {
                 if (!Directory.Exists(ConfigDir))
@@ -41,7 +803,9 @@ namespace PrintagoFolderWatch.Core
                     var settings = new JsonSerializerSettings
                     {
                         // Handle both camelCase (old config) and PascalCase (new config)
-                        ContractResolver = new Newtonsoft.Json.Serialization.DefaultContractResolver()
+                        ContractResolver = new Newtonsoft.Json.Serialization.DefaultContractResolver(),
+                        // Replace list defaults instead of appending to them
+                        ObjectCreationHandling = ObjectCreationHandling.Replace
                     };
 
                     // Try loading - Newtonsoft.Json handles case-insensitive by default with JsonProperty
@@ -51,29 +815,93 @@ namespace PrintagoFolderWatch.Core
                         // If still empty, try manual mapping for legacy camelCase format
                         if (string.IsNullOrEmpty(config.WatchPath))
                         {
-                            var dict = JsonConvert.DeserializeObject<Dictionary<string, string>>(json);
-                            if (dict != null)
-                            {
-                                config.WatchPath = dict.GetValueOrDefault("watchPath", dict.GetValueOrDefault("WatchPath", "")) ?? "";
-                                config.ApiUrl = dict.GetValueOrDefault("apiUrl", dict.GetValueOrDefault("ApiUrl", "")) ?? "";
-                                config.ApiKey = dict.GetValueOrDefault("apiKey", dict.GetValueOrDefault("ApiKey", "")) ?? "";
-                                config.StoreId = dict.GetValueOrDefault("storeId", dict.GetValueOrDefault("StoreId", "")) ?? "";
-                            }
+                            // JObject rather than Dictionary<string, string> so non-string settings don't throw
+                            var obj = JObject.Parse(json);
+                            config.WatchPath = (string?)(obj["watchPath"] ?? obj["WatchPath"]) ?? "";
+                            config.ApiUrl = (string?)(obj["apiUrl"] ?? obj["ApiUrl"]) ?? "";
+                            config.ApiKey = (string?)(obj["apiKey"] ?? obj["ApiKey"]) ?? "";
+                            config.StoreId = (string?)(obj["storeId"] ?? obj["StoreId"]) ?? "";
+                        }
+
+                        // An old single WatchPath becomes the first entry of Watches. A config written with only
+                        // Watches gets its WatchPath from the first entry.
+                        config.Watches ??= new List<WatchEntry>();
+                        config.Watches.RemoveAll(w => w == null);
+                        if (string.IsNullOrEmpty(config.WatchPath) && config.Watches.Count > 0)
+                        {
+                            config.WatchPath = config.Watches[0].Path ?? "";
+                        }
+
+                        // A key typed into the file by hand wins over the stored one, and is moved to the store below
+                        if (string.IsNullOrEmpty(config.ApiKey) && UseKeychain)
+                        {
+                            config.ApiKey = CredentialStore.TryLoad(ConfigFile) ?? "";
+                            config.ApiKeyInKeychain |= config.ApiKey.Length > 0;
+                        }
+                        else if (!string.IsNullOrEmpty(config.ApiKey) && UseKeychain && !keychainMigrationTried)
+                        {
+                            // Plaintext key in the file: move it to the store (once per run, in case there is no store)
+                            keychainMigrationTried = true;
+                            config.Save();
                         }
                         return config;
                     }
                 }
+                else
+                {
+                    IsFirstRun = true;
+                    WriteTemplate();
+                }
             }
             catch (Exception ex)
             {
                 System.Diagnostics.Debug.WriteLine($"Error loading config: {ex.Message}");
+                error = ex.Message;
             }
 
             return new Config();
         }
 
-        public void Save()
+        // Written on first run: the required settings, empty, with where to find each. Comments are fine in
+        // config.json (they're dropped the first time the app saves it).
+        private const string CONFIG_TEMPLATE = @"{
+  // Folder with your print files, e.g. ""C:\Users\you\3D Prints"" (backslashes doubled) or ""/home/you/prints"".
+  // Easier: leave these empty and use Settings... in the tray menu.
+  ""WatchPath"": """",
+
+  // Printago API address, normally ""https://api.printago.io""
+  ""ApiUrl"": """",
+
+  // From your Printago account settings. The key is moved to the OS credential store on the next start.
+  ""ApiKey"": """",
+  ""StoreId"": """"
+}
+";
+
+        /// <summary>
+        /// True when there was no config file at startup (a template has been written) - the tray apps open Settings
+        /// </summary>
+        public static bool IsFirstRun { get; private set; }
+
+        private static void WriteTemplate()
         {
+            File.WriteAllText(ConfigFile, CONFIG_TEMPLATE);
+            LastSavedWriteUtc = File.GetLastWriteTimeUtc(ConfigFile);
+
+            if (!OperatingSystem.IsWindows())
+            {
+                File.SetUnixFileMode(ConfigFile, UnixFileMode.UserRead | UnixFileMode.UserWrite);
+            }
+        }
+
+        public void Save() => Save(out _);
+
+        /// <summary>
+        /// Save, returning false with the reason in error if the file couldn't be written
+        /// </summary>
+        public bool Save(out string? error)
+        {
+            error = null;
             try
             {
                 if (!Directory.Exists(ConfigDir))
@@ -81,12 +909,73 @@ namespace PrintagoFolderWatch.Core
                     Directory.CreateDirectory(ConfigDir);
                 }
 
-                var json = JsonConvert.SerializeObject(this, Formatting.Indented);
-                File.WriteAllText(ConfigFile, json);
+                // Written as GetWatches has them (an old WatchPath-only config gets its one entry) without changing Watches
+                var obj = JObject.Parse(JsonConvert.SerializeObject(this));
+                obj[nameof(Watches)] = JArray.FromObject(GetWatches());
+
+                // Keep environment-supplied values (e.g. an API key in a container) out of the file, and flag values
+                // unless --save was passed. A setting changed since startup (e.g. in the Settings window) is saved as normal.
+                if (overriddenSettings.Values.Any(o => o.fromEnvironment || CommandLine?.Save != true))
+                {
+                    foreach (var (property, (fileValue, overrideValue, fromEnvironment)) in overriddenSettings)
+                    {
+                        if ((fromEnvironment || CommandLine?.Save != true) &&
+                            (string?)typeof(Config).GetProperty(property)!.GetValue(this) == overrideValue)
+                        {
+                            obj[property] = fileValue;
+                        }
+                    }
+
+                    // The first watch folder is WatchPath - keep the file's own value there too
+                    if (obj[nameof(Watches)] is JArray { Count: > 0 } watches)
+                    {
+                        watches[0][nameof(WatchEntry.Path)] = obj[nameof(WatchPath)];
+                    }
+                }
+
+                // Only a flag stays in the file when the OS credential store takes the key; otherwise it's saved as before.
+                // Once it's in the store it never goes back in the file - if the store can't be written now, the
+                // stored key stays in use. --no-keychain writes it to the file as before.
+                var apiKey = (string?)obj[nameof(ApiKey)] ?? "";
+                if (!UseKeychain)
+                {
+                    ApiKeyInKeychain = false;
+                }
+                else if (string.IsNullOrEmpty(apiKey))
+                {
+                    // Cleared (e.g. in Settings) - clear the stored one too, or the next Load would pick it up again
+                    if (ApiKeyInKeychain)
+                        CredentialStore.TrySave(ConfigFile, "");
+                    ApiKeyInKeychain = false;
+                }
+                else if (CredentialStore.TrySave(ConfigFile, apiKey))
+                {
+                    ApiKeyInKeychain = true;
+                }
+                else if (ApiKeyInKeychain)
+                {
+                    System.Diagnostics.Debug.WriteLine("Could not update the API key in the credential store - keeping the stored one");
+                }
+                obj[nameof(ApiKey)] = ApiKeyInKeychain ? "" : apiKey;
+                obj[nameof(ApiKeyInKeychain)] = ApiKeyInKeychain;
+
+                File.WriteAllText(ConfigFile, obj.ToString(Formatting.Indented));
+                LastSavedWriteUtc = File.GetLastWriteTimeUtc(ConfigFile);
+
+                if (!OperatingSystem.IsWindows())
+                {
+                    // Owner-only, in case the key is in the file
+                    File.SetUnixFileMode(ConfigFile, UnixFileMode.UserRead | UnixFileMode.UserWrite);
+                }
+
+                LoadError = null;
+                return true;
             }
             catch (Exception ex)
             {
                 System.Diagnostics.Debug.WriteLine($"Error saving config: {ex.Message}");
+                error = ex.Message;
+                return false;
             }
         }
     }
@@ -0,0 +1,193 @@
+using System;
+using System.Collections.Generic;
+using System.Diagnostics;
+using System.Runtime.InteropServices;
+using System.Text;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Secrets in the OS credential store: Windows Credential Manager, the macOS Keychain (security),
+    /// or libsecret on Linux (secret-tool). Every method fails softly - callers fall back to the config file.
+    /// </summary>
+    public static class CredentialStore
+    {
+        private const string SERVICE_NAME = "PrintagoFolderWatch";
+        private const int TOOL_TIMEOUT_MS = 10000;
+
+        /// <summary>
+        /// Store a secret under the given account (replacing any existing one). Returns false if no credential store is available.
+        /// </summary>
+        public static bool TrySave(string account, string secret)
+        {
+            try
+            {
+                if (OperatingSystem.IsWindows())
+                    return WindowsWrite($"{SERVICE_NAME}:{account}", secret);
+
+                if (OperatingSystem.IsMacOS())
+                {
+                    // Interactive mode reads the command from stdin, so the secret never appears in the process list
+                    return RunTool("security", new[] { "-i" },
+                        $"add-generic-password -U -s {Quote(SERVICE_NAME)} -a {Quote(account)} -w {Quote(secret)}\n").exitCode == 0;
+                }
+
+                if (OperatingSystem.IsLinux())
+                {
+                    return RunTool("secret-tool", new[] { "store", $"--label={SERVICE_NAME} API key", "service", SERVICE_NAME, "account", account },
+                        secret).exitCode == 0;
+                }
+            }
+            catch (Exception ex)
+            {
+                Debug.WriteLine($"Error saving to credential store: {ex.Message}");
+            }
+
+            return false;
+        }
+
+        /// <summary>
+        /// Read a secret saved by TrySave, or null if there isn't one or the store can't be reached
+        /// </summary>
+        public static string? TryLoad(string account)
+        {
+            try
+            {
+                if (OperatingSystem.IsWindows())
+                    return WindowsRead($"{SERVICE_NAME}:{account}");
+
+                (int exitCode, string output) result = (-1, "");
+                if (OperatingSystem.IsMacOS())
+                    result = RunTool("security", new[] { "find-generic-password", "-s", SERVICE_NAME, "-a", account, "-w" }, null);
+                else if (OperatingSystem.IsLinux())
+                    result = RunTool("secret-tool", new[] { "lookup", "service", SERVICE_NAME, "account", account }, null);
+
+                var secret = result.output.TrimEnd('\r', '\n');
+                return result.exitCode == 0 && secret.Length > 0 ? secret : null;
+            }
+            catch (Exception ex)
+            {
+                Debug.WriteLine($"Error reading from credential store: {ex.Message}");
+                return null;
+            }
+        }
+
+        private static string Quote(string value)
+        {
+            return "\"" + value.Replace("\\", "\\\\").Replace("\"", "\\\"") + "\"";
+        }
+
+        private static (int exitCode, string output) RunTool(string fileName, IEnumerable<string> args, string? input)
+        {
+            var startInfo = new ProcessStartInfo(fileName)
+            {
+                RedirectStandardInput = true,
+                RedirectStandardOutput = true,
+                RedirectStandardError = true,
+                UseShellExecute = false,
+                CreateNoWindow = true
+            };
+            foreach (var arg in args)
+            {
+                startInfo.ArgumentList.Add(arg);
+            }
+
+            // Throws if the tool isn't installed - handled by the callers
+            using var process = Process.Start(startInfo)!;
+            if (input != null)
+            {
+                process.StandardInput.Write(input);
+            }
+            process.StandardInput.Close();
+
+            var output = process.StandardOutput.ReadToEndAsync();
+            _ = process.StandardError.ReadToEndAsync();
+            if (!process.WaitForExit(TOOL_TIMEOUT_MS))
+            {
+                // e.g. secret-tool waiting on a keyring unlock prompt that nobody will answer
+                process.Kill();
+                return (-1, "");
+            }
+
+            return (process.ExitCode, output.Result);
+        }
+
+        #region Windows Credential Manager
+
+        private const uint CRED_TYPE_GENERIC = 1;
+        private const uint CRED_PERSIST_LOCAL_MACHINE = 2;
+
+        [StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+        private struct CREDENTIAL
+        {
+            public uint Flags;
+            public uint Type;
+            public string TargetName;
+            public string? Comment;
+            public System.Runtime.InteropServices.ComTypes.FILETIME LastWritten;
+            public uint CredentialBlobSize;
+            public IntPtr CredentialBlob;
+            public uint Persist;
+            public uint AttributeCount;
+            public IntPtr Attributes;
+            public string? TargetAlias;
+            public string? UserName;
+        }
+
+        [DllImport("advapi32.dll", EntryPoint = "CredWriteW", CharSet = CharSet.Unicode, SetLastError = true)]
+        private static extern bool CredWrite(ref CREDENTIAL credential, uint flags);
+
+        [DllImport("advapi32.dll", EntryPoint = "CredReadW", CharSet = CharSet.Unicode, SetLastError = true)]
+        private static extern bool CredRead(string target, uint type, uint flags, out IntPtr credential);
+
+        [DllImport("advapi32.dll")]
+        private static extern void CredFree(IntPtr credential);
+
+        private static bool WindowsWrite(string target, string secret)
+        {
+            var blob = Encoding.Unicode.GetBytes(secret);
+            var blobPtr = Marshal.AllocHGlobal(blob.Length);
+            try
+            {
+                Marshal.Copy(blob, 0, blobPtr, blob.Length);
+                var credential = new CREDENTIAL
+                {
+                    Type = CRED_TYPE_GENERIC,
+                    TargetName = target,
+                    CredentialBlobSize = (uint)blob.Length,
+                    CredentialBlob = blobPtr,
+                    Persist = CRED_PERSIST_LOCAL_MACHINE,
+                    UserName = Environment.UserName
+                };
+                return CredWrite(ref credential, 0);
+            }
+            finally
+            {
+                Marshal.FreeHGlobal(blobPtr);
+            }
+        }
+
+        private static string? WindowsRead(string target)
+        {
+            if (!CredRead(target, CRED_TYPE_GENERIC, 0, out var credentialPtr))
+                return null;
+
+            try
+            {
+                var credential = Marshal.PtrToStructure<CREDENTIAL>(credentialPtr);
+                if (credential.CredentialBlobSize == 0)
+                    return null;
+
+                var blob = new byte[credential.CredentialBlobSize];
+                Marshal.Copy(credential.CredentialBlob, blob, 0, blob.Length);
+                return Encoding.Unicode.GetString(blob);
+            }
+            finally
+            {
+                CredFree(credentialPtr);
+            }
+        }
+
+        #endregion
+    }
+}
@@ -12,7 +12,7 @@ namespace PrintagoFolderWatch.Core
     /// </summary>
     public class FileTrackingDb : IDisposable
     {
-        private const int CURRENT_SCHEMA_VERSION = 1;
+        private const int CURRENT_SCHEMA_VERSION = 5;
         private readonly SqliteConnection connection;
         private readonly string dbPath;
 
@@ -47,7 +47,9 @@ namespace PrintagoFolderWatch.Core
                     part_name TEXT NOT NULL,
                     folder_path TEXT NOT NULL,
                     last_seen_at TEXT NOT NULL,
-                    created_at TEXT NOT NULL
+                    created_at TEXT NOT NULL,
+                    file_size INTEGER NOT NULL DEFAULT -1,
+                    last_write_utc TEXT NOT NULL DEFAULT ''
                 );
 
                 CREATE INDEX IF NOT EXISTS idx_hash ON file_tracking(file_hash);
@@ -62,6 +64,10 @@ namespace PrintagoFolderWatch.Core
             using var command = new SqliteCommand(createTableSql, connection);
             command.ExecuteNonQuery();
 
+            CreateFailedUploadsTable();
+            AddFailedUploadDetails();
+            CreatePendingUploadsTable();
+
             // Set schema version
             SetSchemaVersion(CURRENT_SCHEMA_VERSION);
         }
@@ -83,14 +89,104 @@ namespace PrintagoFolderWatch.Core
                 using var cmd = new SqliteCommand(createSchemaTable, connection);
                 cmd.ExecuteNonQuery();
 
-                // Database from v2.6 or earlier - schema is compatible, just mark version
-                SetSchemaVersion(CURRENT_SCHEMA_VERSION);
+                // Database from v2.6 or earlier - schema is compatible with v1, just mark version
+                SetSchemaVersion(1);
                 System.Diagnostics.Debug.WriteLine("Migrated database from pre-v2.7 (added schema tracking)");
             }
 
+            if (currentVersion < 2) { MigrateToV2(); }
+            if (currentVersion < 3) { MigrateToV3(); }
+            if (currentVersion < 4) { MigrateToV4(); }
+            if (currentVersion < 5) { MigrateToV5(); }
+
             // Future migrations would go here:
-            // if (currentVersion < 2) { MigrateToV2(); }
-            // if (currentVersion < 3) { MigrateToV3(); }
+            // if (currentVersion < 6) { MigrateToV6(); }
+        }
+
+        /// <summary>
+        /// v2: failed_uploads table (uploads that ran out of retries or were rejected)
+        /// </summary>
+        private void MigrateToV2()
+        {
+            CreateFailedUploadsTable();
+            SetSchemaVersion(2);
+            System.Diagnostics.Debug.WriteLine("Migrated database to v2 (added failed_uploads)");
+        }
+
+        /// <summary>
+        /// v3: file_size + last_write_utc, so unchanged files can reuse their recorded hash instead of being re-read
+        /// </summary>
+        private void MigrateToV3()
+        {
+            var sql = @"
+                ALTER TABLE file_tracking ADD COLUMN file_size INTEGER NOT NULL DEFAULT -1;
+                ALTER TABLE file_tracking ADD COLUMN last_write_utc TEXT NOT NULL DEFAULT '';
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.ExecuteNonQuery();
+
+            SetSchemaVersion(3);
+            System.Diagnostics.Debug.WriteLine("Migrated database to v3 (added file_size, last_write_utc)");
+        }
+
+        /// <summary>
+        /// v4: cloud_path + http_status on failed_uploads, for the tray's Failed Uploads list and failed.json
+        /// </summary>
+        private void MigrateToV4()
+        {
+            AddFailedUploadDetails();
+            SetSchemaVersion(4);
+            System.Diagnostics.Debug.WriteLine("Migrated database to v4 (added failed_uploads.cloud_path, http_status)");
+        }
+
+        /// <summary>
+        /// v5: pending_uploads table (uploads cut off by Stop or Exit, retried on the next start)
+        /// </summary>
+        private void MigrateToV5()
+        {
+            CreatePendingUploadsTable();
+            SetSchemaVersion(5);
+            System.Diagnostics.Debug.WriteLine("Migrated database to v5 (added pending_uploads)");
+        }
+
+        private void AddFailedUploadDetails()
+        {
+            var sql = @"
+                ALTER TABLE failed_uploads ADD COLUMN cloud_path TEXT NOT NULL DEFAULT '';
+                ALTER TABLE failed_uploads ADD COLUMN http_status INTEGER NOT NULL DEFAULT 0;
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.ExecuteNonQuery();
+        }
+
+        private void CreateFailedUploadsTable()
+        {
+            var sql = @"
+                CREATE TABLE IF NOT EXISTS failed_uploads (
+                    file_path TEXT PRIMARY KEY,
+                    reason TEXT NOT NULL,
+                    attempts INTEGER NOT NULL,
+                    failed_at TEXT NOT NULL
+                );
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.ExecuteNonQuery();
+        }
+
+        private void CreatePendingUploadsTable()
+        {
+            var sql = @"
+                CREATE TABLE IF NOT EXISTS pending_uploads (
+                    file_path TEXT PRIMARY KEY,
+                    interrupted_at TEXT NOT NULL
+                );
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.ExecuteNonQuery();
         }
 
         private int GetSchemaVersion()
@@ -123,6 +219,25 @@ namespace PrintagoFolderWatch.Core
             command.ExecuteNonQuery();
         }
 
+        private static FileTrackingEntry ReadEntry(SqliteDataReader reader)
+        {
+            var lastWrite = reader.GetString(8);
+            return new FileTrackingEntry
+            {
+                FilePath = reader.GetString(0),
+                FileHash = reader.GetString(1),
+                PartId = reader.GetString(2),
+                PartName = reader.GetString(3),
+                FolderPath = reader.GetString(4),
+                LastSeenAt = DateTime.Parse(reader.GetString(5)),
+                CreatedAt = DateTime.Parse(reader.GetString(6)),
+                FileSize = reader.GetInt64(7),
+                LastWriteUtc = string.IsNullOrEmpty(lastWrite)
+                    ? null
+                    : DateTime.Parse(lastWrite, null, System.Globalization.DateTimeStyles.RoundtripKind)
+            };
+        }
+
         /// <summary>
         /// Get tracked entry by file path
         /// </summary>
@@ -135,16 +250,7 @@ namespace PrintagoFolderWatch.Core
             using var reader = command.ExecuteReader();
             if (reader.Read())
             {
-                return new FileTrackingEntry
-                {
-                    FilePath = reader.GetString(0),
-                    FileHash = reader.GetString(1),
-                    PartId = reader.GetString(2),
-                    PartName = reader.GetString(3),
-                    FolderPath = reader.GetString(4),
-                    LastSeenAt = DateTime.Parse(reader.GetString(5)),
-                    CreatedAt = DateTime.Parse(reader.GetString(6))
-                };
+                return ReadEntry(reader);
             }
 
             return null;
@@ -162,16 +268,25 @@ namespace PrintagoFolderWatch.Core
             using var reader = command.ExecuteReader();
             if (reader.Read())
             {
-                return new FileTrackingEntry
-                {
-                    FilePath = reader.GetString(0),
-                    FileHash = reader.GetString(1),
-                    PartId = reader.GetString(2),
-                    PartName = reader.GetString(3),
-                    FolderPath = reader.GetString(4),
-                    LastSeenAt = DateTime.Parse(reader.GetString(5)),
-                    CreatedAt = DateTime.Parse(reader.GetString(6))
-                };
+                return ReadEntry(reader);
+            }
+
+            return null;
+        }
+
+        /// <summary>
+        /// Get the tracked entry for a Part (for forgetting a file once its Part is deleted)
+        /// </summary>
+        public FileTrackingEntry? GetByPartId(string partId)
+        {
+            var sql = "SELECT * FROM file_tracking WHERE part_id = @partId LIMIT 1";
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@partId", partId);
+
+            using var reader = command.ExecuteReader();
+            if (reader.Read())
+            {
+                return ReadEntry(reader);
             }
 
             return null;
@@ -184,9 +299,10 @@ namespace PrintagoFolderWatch.Core
         {
             var sql = @"
                 INSERT OR REPLACE INTO file_tracking
-                (file_path, file_hash, part_id, part_name, folder_path, last_seen_at, created_at)
+                (file_path, file_hash, part_id, part_name, folder_path, last_seen_at, created_at, file_size, last_write_utc)
                 VALUES (@path, @hash, @partId, @partName, @folderPath, @lastSeen,
-                    COALESCE((SELECT created_at FROM file_tracking WHERE file_path = @path), @created))
+                    COALESCE((SELECT created_at FROM file_tracking WHERE file_path = @path), @created),
+                    @fileSize, @lastWrite)
             ";
 
             using var command = new SqliteCommand(sql, connection);
@@ -197,6 +313,8 @@ namespace PrintagoFolderWatch.Core
             command.Parameters.AddWithValue("@folderPath", entry.FolderPath ?? "");
             command.Parameters.AddWithValue("@lastSeen", DateTime.UtcNow.ToString("o"));
             command.Parameters.AddWithValue("@created", DateTime.UtcNow.ToString("o"));
+            command.Parameters.AddWithValue("@fileSize", entry.FileSize);
+            command.Parameters.AddWithValue("@lastWrite", entry.LastWriteUtc?.ToString("o") ?? "");
 
             command.ExecuteNonQuery();
         }
@@ -227,7 +345,7 @@ namespace PrintagoFolderWatch.Core
         {
             var sql = @"
                 UPDATE file_tracking
-                SET file_hash = @hash, last_seen_at = @lastSeen
+                SET file_hash = @hash, last_seen_at = @lastSeen, file_size = -1, last_write_utc = ''
                 WHERE file_path = @path
             ";
 
@@ -239,6 +357,35 @@ namespace PrintagoFolderWatch.Core
             return command.ExecuteNonQuery() > 0;
         }
 
+        /// <summary>
+        /// Record the size and modified time the stored hash was computed from
+        /// </summary>
+        public bool UpdateFileStat(string filePath, long fileSize, DateTime lastWriteUtc)
+        {
+            var sql = @"
+                UPDATE file_tracking
+                SET file_size = @fileSize, last_write_utc = @lastWrite
+                WHERE file_path = @path
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@fileSize", fileSize);
+            command.Parameters.AddWithValue("@lastWrite", lastWriteUtc.ToString("o"));
+            command.Parameters.AddWithValue("@path", filePath);
+
+            return command.ExecuteNonQuery() > 0;
+        }
+
+        /// <summary>
+        /// Forget every recorded size/modified time so all files are re-hashed (Part bindings are kept)
+        /// </summary>
+        public int ClearFileStats()
+        {
+            var sql = "UPDATE file_tracking SET file_size = -1, last_write_utc = ''";
+            using var command = new SqliteCommand(sql, connection);
+            return command.ExecuteNonQuery();
+        }
+
         /// <summary>
         /// Delete tracking entry
         /// </summary>
@@ -264,16 +411,7 @@ namespace PrintagoFolderWatch.Core
 
             while (reader.Read())
             {
-                entries.Add(new FileTrackingEntry
-                {
-                    FilePath = reader.GetString(0),
-                    FileHash = reader.GetString(1),
-                    PartId = reader.GetString(2),
-                    PartName = reader.GetString(3),
-                    FolderPath = reader.GetString(4),
-                    LastSeenAt = DateTime.Parse(reader.GetString(5)),
-                    CreatedAt = DateTime.Parse(reader.GetString(6))
-                });
+                entries.Add(ReadEntry(reader));
             }
 
             return entries;
@@ -293,6 +431,152 @@ namespace PrintagoFolderWatch.Core
             return command.ExecuteNonQuery();
         }
 
+        /// <summary>
+        /// Record an upload that was given up on (replaces any earlier entry for the path).
+        /// httpStatus is the last HTTP status received, or null if it never got a response (network error, timeout)
+        /// </summary>
+        public void AddFailedUpload(string filePath, string cloudPath, string reason, int? httpStatus, int attempts)
+        {
+            var sql = @"
+                INSERT OR REPLACE INTO failed_uploads (file_path, cloud_path, reason, http_status, attempts, failed_at)
+                VALUES (@path, @cloudPath, @reason, @httpStatus, @attempts, @failedAt)
+            ";
+
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@path", filePath);
+            command.Parameters.AddWithValue("@cloudPath", cloudPath);
+            command.Parameters.AddWithValue("@reason", reason);
+            command.Parameters.AddWithValue("@httpStatus", httpStatus ?? 0);
+            command.Parameters.AddWithValue("@attempts", attempts);
+            command.Parameters.AddWithValue("@failedAt", DateTime.UtcNow.ToString("o"));
+
+            command.ExecuteNonQuery();
+        }
+
+        /// <summary>
+        /// Remove a path from the failed list (after it uploads successfully)
+        /// </summary>
+        public bool RemoveFailedUpload(string filePath)
+        {
+            var sql = "DELETE FROM failed_uploads WHERE file_path = @path";
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@path", filePath);
+
+            return command.ExecuteNonQuery() > 0;
+        }
+
+        /// <summary>
+        /// Get all failed uploads, most recent first
+        /// </summary>
+        public List<FailedUploadEntry> GetFailedUploads()
+        {
+            var entries = new List<FailedUploadEntry>();
+            var sql = "SELECT file_path, cloud_path, reason, http_status, attempts, failed_at FROM failed_uploads ORDER BY failed_at DESC";
+
+            using var command = new SqliteCommand(sql, connection);
+            using var reader = command.ExecuteReader();
+
+            while (reader.Read())
+            {
+                entries.Add(new FailedUploadEntry
+                {
+                    FilePath = reader.GetString(0),
+                    CloudPath = reader.GetString(1),
+                    Reason = reader.GetString(2),
+                    HttpStatus = reader.GetInt32(3) == 0 ? null : reader.GetInt32(3),
+                    Attempts = reader.GetInt32(4),
+                    FailedAt = DateTime.Parse(reader.GetString(5))
+                });
+            }
+
+            return entries;
+        }
+
+        /// <summary>
+        /// Remember an upload that was cut off part-way (Stop, Exit) - not a failure, just not done yet
+        /// </summary>
+        public void AddPendingUpload(string filePath)
+        {
+            var sql = "INSERT OR REPLACE INTO pending_uploads (file_path, interrupted_at) VALUES (@path, @interruptedAt)";
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@path", filePath);
+            command.Parameters.AddWithValue("@interruptedAt", DateTime.UtcNow.ToString("o"));
+            command.ExecuteNonQuery();
+        }
+
+        public bool RemovePendingUpload(string filePath)
+        {
+            var sql = "DELETE FROM pending_uploads WHERE file_path = @path";
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@path", filePath);
+
+            return command.ExecuteNonQuery() > 0;
+        }
+
+        public List<string> GetPendingUploads()
+        {
+            var paths = new List<string>();
+            var sql = "SELECT file_path FROM pending_uploads ORDER BY interrupted_at";
+
+            using var command = new SqliteCommand(sql, connection);
+            using var reader = command.ExecuteReader();
+
+            while (reader.Read())
+            {
+                paths.Add(reader.GetString(0));
+            }
+
+            return paths;
+        }
+
+        /// <summary>
+        /// When the last full scan of the watch folders finished, or null if none has
+        /// </summary>
+        public DateTime? GetLastScanUtc()
+        {
+            var sql = "SELECT value FROM schema_info WHERE key = 'last_scan_utc'";
+            using var command = new SqliteCommand(sql, connection);
+            var value = command.ExecuteScalar() as string;
+            return DateTime.TryParse(value, null, System.Globalization.DateTimeStyles.RoundtripKind, out var lastScan)
+                ? lastScan
+                : null;
+        }
+
+        public void SetLastScanUtc(DateTime lastScanUtc)
+        {
+            var sql = "INSERT OR REPLACE INTO schema_info (key, value) VALUES ('last_scan_utc', @value)";
+            using var command = new SqliteCommand(sql, connection);
+            command.Parameters.AddWithValue("@value", lastScanUtc.ToString("o"));
+            command.ExecuteNonQuery();
+        }
+
+        /// <summary>
+        /// With UploadExisting off: files last changed before this are left alone. Null if it isn't set.
+        /// </summary>
+        public DateTime? GetUploadNewSinceUtc()
+        {
+            var sql = "SELECT value FROM schema_info WHERE key = 'upload_new_since_utc'";
+            using var command = new SqliteCommand(sql, connection);
+            var value = command.ExecuteScalar() as string;
+            return DateTime.TryParse(value, null, System.Globalization.DateTimeStyles.RoundtripKind, out var since)
+                ? since
+                : null;
+        }
+
+        /// <summary>
+        /// Null clears it, so turning UploadExisting off again later starts from that time instead
+        /// </summary>
+        public void SetUploadNewSinceUtc(DateTime? sinceUtc)
+        {
+            var sql = sinceUtc == null
+                ? "DELETE FROM schema_info WHERE key = 'upload_new_since_utc'"
+                : "INSERT OR REPLACE INTO schema_info (key, value) VALUES ('upload_new_since_utc', @value)";
+            using var command = new SqliteCommand(sql, connection);
+            if (sinceUtc != null)
+                command.Parameters.AddWithValue("@value", sinceUtc.Value.ToString("o"));
+            command.ExecuteNonQuery();
+        }
+
         public void Dispose()
         {
             connection?.Close();
@@ -312,5 +596,24 @@ namespace PrintagoFolderWatch.Core
         public string FolderPath { get; set; } = "";
         public DateTime LastSeenAt { get; set; }
         public DateTime CreatedAt { get; set; }
+
+        // Size/modified time FileHash was computed from (-1/null = unknown, re-hash)
+        public long FileSize { get; set; } = -1;
+        public DateTime? LastWriteUtc { get; set; }
+    }
+
+    /// <summary>
+    /// An upload that ran out of retries or was rejected by the server
+    /// </summary>
+    public class FailedUploadEntry
+    {
+        public string FilePath { get; set; } = "";
+        public string CloudPath { get; set; } = "";
+        // Last error, e.g. "Upload failed: BadRequest"
+        public string Reason { get; set; } = "";
+        // Last HTTP status received (null: no response - network error or timeout)
+        public int? HttpStatus { get; set; }
+        public int Attempts { get; set; }
+        public DateTime FailedAt { get; set; }
     }
 }
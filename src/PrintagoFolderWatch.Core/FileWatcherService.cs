@@ -24,14 +24,44 @@ namespace PrintagoFolderWatch.Core
     {
         public Config Config { get; private set; }
         public event Action<string, string>? OnLog;
-
-        private FileSystemWatcher? watcher;
-        private readonly ConcurrentQueue<string> uploadQueue = new();
+        public event Action<bool, string>? OnConfigReloaded;
+        // (title, message, isError) for a desktop notification - already filtered by Config.Notifications
+        public event Action<string, string, bool>? OnNotification;
+
+        private List<FileSystemWatcher> watchers = new(); // One per Config.GetWatches() entry that's available
+        private List<WatchEntry> unavailableWatches = new(); // The rest - waited for (Config.WatchPathRetrySeconds)
+        private HashSet<string> pollOnlyWatches = new(); // Available but can't be watched (no change notifications) - polled
+        // Poll interval for those when Config.PollIntervalSeconds is 0
+        private const int FALLBACK_POLL_SECONDS = 30;
+
+        // The clock is checked every CLOCK_CHECK_SECONDS; jumping ahead by SLEEP_GAP_SECONDS more than that means the
+        // computer was asleep. At most one catch-up every CATCH_UP_MIN_MINUTES.
+        private const int CLOCK_CHECK_SECONDS = 10;
+        private const int SLEEP_GAP_SECONDS = 60;
+        private const int CATCH_UP_MIN_MINUTES = 5;
+        // Two lanes, each FIFO: changes seen by the watchers go ahead of files queued by a scan (the initial
+        // sync or a full re-sync), so a file saved now doesn't wait behind a backlog of thousands. Past
+        // Config.MaxQueuedInMemory a lane spills to a journal file, so enqueuing never waits on a full buffer.
+        private readonly SpillQueue uploadQueue;
+        private readonly SpillQueue scanUploadQueue;
         private readonly ConcurrentQueue<PartCache> deleteQueue = new();
         private readonly ConcurrentQueue<MoveOperation> moveQueue = new();
-        private readonly HttpClient httpClient = new();
+        // Both clients give up on a connection (TCP connect plus TLS handshake) after CONNECT_TIMEOUT_SECONDS, so a
+        // dead host fails fast instead of waiting on the OS's own timeout
+        private const int CONNECT_TIMEOUT_SECONDS = 15;
+        private readonly HttpClient httpClient;
+        // Storage PUTs have no overall timeout - a stalled transfer is aborted instead (Config.UploadTimeoutSeconds),
+        // and that includes waiting for the response headers once the body has been sent
+        private readonly HttpClient storageHttpClient = new(new SocketsHttpHandler { ConnectTimeout = TimeSpan.FromSeconds(CONNECT_TIMEOUT_SECONDS) })
+        {
+            Timeout = Timeout.InfiniteTimeSpan
+        };
+        private readonly IPrintagoApiClient apiClient;
+        private readonly IPartUploader partUploader;
         private CancellationTokenSource? cts;
-        private bool isRunning = false;
+        private volatile bool isRunning = false;
+        // Guards isRunning, cts and watchers across Start / Stop from the UI, auto-start and shutdown
+        private readonly object lifecycleLock = new();
 
         // Caches
         private readonly ConcurrentDictionary<string, List<PartCache>> remoteParts = new();
@@ -42,16 +72,46 @@ namespace PrintagoFolderWatch.Core
         // Tracking database for preserving Part bindings across file moves/renames
         private FileTrackingDb? trackingDb;
 
+        // Exclude patterns per watch folder path (Config.ExcludePatterns + that folder's .printagoignore)
+        private Dictionary<string, PathFilter> pathFilters = new(StringComparer.OrdinalIgnoreCase);
+        private static readonly PathFilter builtInFilter = new(PathFilter.BUILT_IN_PATTERNS);
+        private static readonly PathFilter hiddenFilter = new(new[] { PathFilter.HIDDEN_PATTERN });
+
         // Pending deletions: track delete events with a grace period for atomic saves
-        private readonly ConcurrentDictionary<string, (PartCache part, DateTime deleteTime, string oldHash)> pendingDeletions = new();
+        private readonly ConcurrentDictionary<string, (PartCache part, DateTime deleteTime, string oldHash, long oldSize)> pendingDeletions = new();
         private const int DELETION_GRACE_PERIOD_MS = 1000;
+        // A move inside the watch tree arrives as Deleted + Created, so a deletion also waits out the new
+        // path's quiet period plus this long for its hash, letting the two be paired into one Part move
+        private const int MOVE_MATCH_WINDOW_MS = 3000;
+
+        // Debouncing: one pending timer per path, restarted by every new event for that path
+        private readonly ConcurrentDictionary<string, CancellationTokenSource> debounceTimers = new();
+        private const int STABLE_POLL_MS = 500;
 
-        // Debouncing
-        private readonly ConcurrentDictionary<string, DateTime> lastEventTime = new();
-        private const int DEBOUNCE_MS = 500;
+        // Longest wait between checks for a watch folder that stays missing
+        private const int WATCH_RETRY_MAX_SECONDS = 300;
 
-        // Track files currently being processed
+        // Paths queued or in flight - every enqueue goes through EnqueueUpload, which skips paths already here.
+        // The value is true while the path is only in scanUploadQueue.
         private readonly ConcurrentDictionary<string, bool> filesInUploadQueue = new();
+        // Size of each of those when it was queued, for the time-left estimate
+        private readonly ConcurrentDictionary<string, long> queuedUploadBytes = new();
+
+        // Paths a worker is uploading right now - a second queue entry for the same path is coalesced
+        private readonly ConcurrentDictionary<string, bool> uploadingPaths = new();
+
+        // Uploading paths that changed again meanwhile - queued once more when the current upload finishes
+        private readonly ConcurrentDictionary<string, bool> changedWhileUploading = new();
+
+        // Paths queued by ForceFullResync - uploaded even if the remote hash already matches
+        private readonly ConcurrentDictionary<string, bool> forceUploadPaths = new();
+
+        // Files deleted/moved away by PostUploadAction - their delete events aren't local deletions
+        private readonly ConcurrentDictionary<string, bool> postUploadRemovals = new();
+
+        // Coalesces folder-level changes (rename/delete/overflow) into a single resync
+        private int resyncScheduled = 0;
+        private const int RESYNC_DELAY_MS = 1000;
 
         // Lock for upload operations on same key
         private readonly ConcurrentDictionary<string, SemaphoreSlim> uploadKeyLocks = new();
@@ -63,26 +123,332 @@ namespace PrintagoFolderWatch.Core
         // Folder creation lock
         private readonly SemaphoreSlim folderCreationLock = new SemaphoreSlim(1, 1);
 
-        // Rate limiting
-        private const int MAX_PARALLEL_UPLOADS = 10;
-        private readonly SemaphoreSlim uploadSemaphore = new SemaphoreSlim(MAX_PARALLEL_UPLOADS, MAX_PARALLEL_UPLOADS);
+        // Config hot reload. Reloads are serialized; Config is swapped as one reference, so a worker
+        // sees either the old or the new settings, and watch folder/API changes drain the queue first.
+        private FileSystemWatcher? configWatcher;
+        private CancellationTokenSource? configReloadDebounce;
+        private readonly SemaphoreSlim configReloadLock = new SemaphoreSlim(1, 1);
+        private const int CONFIG_RELOAD_DEBOUNCE_MS = 1000;
+
+        private const int CONNECTION_TEST_TIMEOUT_SECONDS = 15;
+
+        // Successful uploads since the last notification - reported together once the queue is empty
+        private int uploadsSinceNotification = 0;
+        // Cloud-only files skipped since the last PlaceholderAction "notify" summary
+        private int placeholdersSkipped = 0;
+        private string lastUploadedName = "";
+
+        // Last finished uploads, newest last - the tray's Recent Uploads menu and failed count
+        private readonly object recentUploadsLock = new();
+        private readonly Queue<RecentUpload> recentUploads = new();
+        private const int RECENT_UPLOADS_MAX = 10;
+
+        // Short reason for an upload's last failure (its progress status), for the failure notification
+        private readonly ConcurrentDictionary<string, string> uploadFailureReasons = new();
+        // And the HTTP status that came with it, if any (for the failed-uploads list)
+        private readonly ConcurrentDictionary<string, int> uploadFailureStatuses = new();
+        private readonly object failedUploadsFileLock = new();
+
+        // Failed deletes use the upload retry backoff - attempts so far by Part ID
+        private readonly ConcurrentDictionary<string, int> deleteAttempts = new();
+
+        // Set while uploads are paused because the API rejected the key or store (the reason, for the tray and logs)
+        private volatile string? uploadsPausedReason;
 
-        // Global API rate limiter
-        private readonly SemaphoreSlim apiRateLimiter = new SemaphoreSlim(1, 1);
-        private DateTime lastApiCallTime = DateTime.MinValue;
+        // Pause Uploads in the tray: changes are still watched and queued, but no new upload starts
+        private volatile bool uploadsPausedByUser;
+
+        // 1 while the API host can't be reached: no new upload starts, and the one that found out waits (not counted
+        // as an attempt) until a check every OFFLINE_CHECK_SECONDS gets through
+        private int offline;
+        private const int OFFLINE_CHECK_SECONDS = 15;
+
+        // UploadExisting off: files not changed since this time are left alone by scans (null = upload everything)
+        private DateTime? uploadNewSinceUtc;
+
+        // Rate limiting
+        private const int MAX_PARALLEL_UPLOADS_LIMIT = 20;
+        private readonly int maxParallelUploads;
+        private int inFlightUploads = 0;
 
         // Statistics
         private int syncedFilesCount = 0;
+        private volatile bool isScanning = false;
+
+        // Scan progress for the tray: set while the scanned files are compared with the tracking database
+        private volatile bool isCheckingFiles;
+        private int scanFilesChecked;
+
+        // The walk lists this many top-level subfolders of a watch folder at once (helps most on network shares)
+        private const int SCAN_PARALLELISM = 4;
 
         // Recent activity log
         private readonly ConcurrentQueue<string> recentLogs = new();
         private const int MAX_RECENT_LOGS = 50;
 
+        // When the last ERROR was logged (DateTime ticks, 0 = none) - the tray shows an error state for a while after,
+        // until the next successful upload
+        private long lastErrorTicks;
+        private long lastUploadSuccessTicks;
+        private const int ERROR_STATUS_MINUTES = 5;
+
         // Public properties for status tracking
-        public int UploadQueueCount => uploadQueue.Count;
+        public int UploadQueueCount => uploadQueue.Count + scanUploadQueue.Count;
         public int DeleteQueueCount => deleteQueue.Count;
         public int FoldersCreatedCount => remoteFolders.Count;
         public int SyncedFilesCount => syncedFilesCount;
+        public bool IsScanning => isScanning;
+        public int MaxParallelUploads => maxParallelUploads;
+        public bool IsRunning => isRunning;
+        // Why the last Start returned false (invalid settings, rejected API key...), null once started
+        public string? StartError { get; private set; }
+        public string? UploadsPausedReason => uploadsPausedReason;
+        public bool UploadsPaused => uploadsPausedByUser;
+        public bool IsOffline => Volatile.Read(ref offline) == 1;
+        // A 429 (or 503 with Retry-After) from the API: no new upload starts and API requests wait
+        public bool IsRateLimited => apiClient.IsRateLimited;
+        public int RetryingCount => partUploader.RetryingCount;
+        public int ActiveUploadCount => activeUploads.Count;
+        // Queued plus in progress (including files waiting on a retry) - what ShutdownAsync waits for
+        public int PendingUploadCount => UploadQueueCount + Volatile.Read(ref inFlightUploads);
+        // Combined speed of the storage PUTs over the last few seconds
+        public double UploadBytesPerSecond => partUploader.BytesPerSecond;
+        // Queued and in-progress bytes not sent yet
+        public long RemainingUploadBytes => Math.Max(0, queuedUploadBytes.Values.Sum() - activeUploads.Values.Sum(u => u.BytesSent));
+
+        /// <summary>
+        /// Time left for the queued and in-progress uploads at the current speed; null when nothing is
+        /// being sent or there's nothing left
+        /// </summary>
+        public TimeSpan? UploadTimeRemaining
+        {
+            get
+            {
+                var rate = UploadBytesPerSecond;
+                var remaining = RemainingUploadBytes;
+                if (rate < 1 || remaining == 0)
+                    return null;
+                var seconds = remaining / rate;
+                return seconds < TimeSpan.MaxValue.TotalSeconds ? TimeSpan.FromSeconds(seconds) : null;
+            }
+        }
+        public DateTime? LastErrorTime
+        {
+            get
+            {
+                var ticks = Interlocked.Read(ref lastErrorTicks);
+                return ticks > 0 ? new DateTime(ticks) : null;
+            }
+        }
+
+        private bool HasRecentError()
+        {
+            var errorTicks = Interlocked.Read(ref lastErrorTicks);
+            return errorTicks > DateTime.Now.AddMinutes(-ERROR_STATUS_MINUTES).Ticks &&
+                   errorTicks > Interlocked.Read(ref lastUploadSuccessTicks);
+        }
+
+        public WatcherStatus Status
+        {
+            get
+            {
+                if (!isRunning)
+                    return WatcherStatus.Stopped;
+                if (IsOffline || IsRateLimited)
+                    return WatcherStatus.Paused;
+                if (uploadsPausedReason != null || RetryingCount > 0 || HasRecentError() || GetUnavailableWatchPaths().Count > 0)
+                    return WatcherStatus.Error;
+                if (uploadsPausedByUser)
+                    return WatcherStatus.Paused;
+                if (ActiveUploadCount > 0 || UploadQueueCount > 0)
+                    return WatcherStatus.Uploading;
+                return WatcherStatus.Watching;
+            }
+        }
+
+        /// <summary>
+        /// One-line status for the tray tooltip, e.g. "Watching D:\3DPrinting - 12 queued, 3 uploading, last error 14:02"
+        /// </summary>
+        public string GetStatusSummary()
+        {
+            if (!isRunning)
+                return CredentialsRejectedReason != null ? $"Stopped - {CredentialsRejectedReason}" : "Stopped";
+
+            var details = new List<string>();
+            if (IsOffline)
+                details.Add("offline - uploads paused until the connection is back");
+            else if (IsRateLimited)
+                details.Add($"rate limited - resuming at {DescribeRateLimitEnd()}");
+            else if (uploadsPausedReason != null)
+                details.Add("uploads paused (API key or Store ID rejected)");
+            else if (uploadsPausedByUser)
+                details.Add("uploads paused");
+            var unavailable = GetUnavailableWatchPaths();
+            if (unavailable.Count > 0)
+                details.Add($"waiting for {string.Join(", ", unavailable)}");
+            if (UploadQueueCount > 0)
+                details.Add($"{UploadQueueCount} queued");
+            if (ActiveUploadCount > 0)
+            {
+                details.Add($"{ActiveUploadCount} uploading");
+                var speed = DescribeUploadSpeed();
+                if (speed != null)
+                    details.Add(speed);
+            }
+            if (RetryingCount > 0)
+                details.Add($"{RetryingCount} retrying");
+            var failed = RecentFailedCount;
+            if (failed > 0)
+                details.Add($"{failed} failed");
+            if (LastErrorTime is DateTime lastError)
+                details.Add($"last error {lastError:HH:mm}");
+
+            var summary = $"Watching {DescribeWatches()}";
+            return details.Count > 0 ? $"{summary} - {string.Join(", ", details)}" : summary;
+        }
+
+        /// <summary>
+        /// Live progress for the tray menu: "Scanning… 120 files found" then "Checking files… 80 of 120" during a scan,
+        /// otherwise e.g. "12 queued, 3 uploading, 40 uploaded - 2.4 MB/s, ~5 min left"
+        /// </summary>
+        public string GetActivitySummary()
+        {
+            if (isScanning)
+                return $"Scanning… {localFiles.Count} files found";
+            if (isCheckingFiles)
+                return $"Checking files… {Volatile.Read(ref scanFilesChecked)} of {localFiles.Count}";
+            if (!isRunning)
+                return "Not watching";
+
+            var activity = $"{UploadQueueCount} queued, {ActiveUploadCount} uploading, {SyncedFilesCount} uploaded";
+            var speed = ActiveUploadCount > 0 ? DescribeUploadSpeed() : null;
+            if (speed != null)
+                activity += $" - {speed}";
+            if (IsOffline)
+                return $"Offline - paused - {activity}";
+            if (IsRateLimited)
+                return $"Rate limited, resuming at {DescribeRateLimitEnd()} - {activity}";
+            var unavailable = GetUnavailableWatchPaths().Count;
+            return unavailable > 0 ? $"Waiting for {unavailable} watch folder(s) - {activity}" : activity;
+        }
+
+        /// <summary>
+        /// "2.4 MB/s, ~5 min left", or null while nothing has been sent for a while
+        /// </summary>
+        private string? DescribeUploadSpeed()
+        {
+            var rate = UploadBytesPerSecond;
+            if (rate < 1)
+                return null;
+
+            var speed = rate >= 1024 * 1024 ? $"{rate / 1024.0 / 1024.0:F1} MB/s" : $"{rate / 1024.0:F0} KB/s";
+            if (UploadTimeRemaining is not TimeSpan left)
+                return speed;
+
+            var eta = left.TotalMinutes < 1 ? "<1 min"
+                : left.TotalHours < 1 ? $"~{Math.Ceiling(left.TotalMinutes):0} min"
+                : $"~{(int)left.TotalHours} h {left.Minutes} min";
+            return $"{speed}, {eta} left";
+        }
+
+        /// <summary>
+        /// Stop or restart taking files off the upload queue. Uploads already running finish; while paused,
+        /// changes keep being queued, and resuming works through the backlog.
+        /// </summary>
+        public void SetUploadsPaused(bool paused)
+        {
+            if (uploadsPausedByUser == paused)
+                return;
+
+            uploadsPausedByUser = paused;
+            Log(paused
+                ? $"Uploads paused - changes are still watched and queued ({UploadQueueCount} queued)"
+                : $"Uploads resumed - {UploadQueueCount} queued file(s) to upload", "INFO");
+        }
+
+        /// <summary>
+        /// The last few finished uploads, newest first
+        /// </summary>
+        public List<RecentUpload> GetRecentUploads()
+        {
+            lock (recentUploadsLock)
+            {
+                return recentUploads.Reverse().ToList();
+            }
+        }
+
+        /// <summary>
+        /// Failed uploads among the recent ones that haven't been retried since
+        /// </summary>
+        public int RecentFailedCount
+        {
+            get
+            {
+                lock (recentUploadsLock)
+                {
+                    return recentUploads.Count(u => !u.Success);
+                }
+            }
+        }
+
+        /// <summary>
+        /// Queue a failed upload again (ahead of any scan backlog). Its Recent Uploads entry goes away;
+        /// the new attempt adds a fresh one. False if the watcher isn't running or the file is gone.
+        /// </summary>
+        public bool RetryUpload(string filePath)
+        {
+            if (!isRunning || !File.Exists(filePath))
+                return false;
+
+            Log($"Retrying upload: {Path.GetFileName(filePath)}", "INFO");
+            RequeueFailedUpload(filePath);
+            return true;
+        }
+
+        /// <summary>
+        /// Queue everything on the failed-uploads list again ("Retry All Failed"). Entries whose file is gone
+        /// are dropped from the list. Returns how many were queued.
+        /// </summary>
+        public int RetryAllFailed()
+        {
+            if (!isRunning)
+                return 0;
+
+            int queued = 0, dropped = 0;
+            foreach (var failed in GetFailedUploads())
+            {
+                if (!File.Exists(failed.FilePath))
+                {
+                    if (trackingDb?.RemoveFailedUpload(failed.FilePath) == true)
+                        dropped++;
+                    continue;
+                }
+
+                RequeueFailedUpload(failed.FilePath);
+                queued++;
+            }
+
+            if (dropped > 0)
+                WriteFailedUploadsFile();
+
+            Log($"Retrying {queued} failed upload(s){(dropped > 0 ? $" - {dropped} no longer exist and were removed from the list" : "")}", "INFO");
+            return queued;
+        }
+
+        private void RequeueFailedUpload(string filePath)
+        {
+            lock (recentUploadsLock)
+            {
+                var kept = recentUploads.Where(u => u.Success || u.FilePath != filePath).ToList();
+                recentUploads.Clear();
+                foreach (var entry in kept)
+                    recentUploads.Enqueue(entry);
+            }
+
+            EnqueueUpload(filePath);
+        }
+
+        public List<FailedUploadEntry> GetFailedUploads() => trackingDb?.GetFailedUploads() ?? new List<FailedUploadEntry>();
         public List<UploadProgress> GetActiveUploads() => activeUploads.Values.ToList();
 
         public List<string> GetDeleteQueueItems()
@@ -108,174 +474,662 @@ namespace PrintagoFolderWatch.Core
             return recentLogs.Reverse().Take(count).Reverse().ToList();
         }
 
-        public FileWatcherService()
+        /// <param name="storageUploader">Sends files to storage; an HttpStorageUploader when not given</param>
+        public FileWatcherService(IStorageUploader? storageUploader = null)
+            : this(Config.Load(), GetDefaultTrackingDbPath(), null, storageUploader)
+        {
+            if (Config.LoadError != null)
+                Log($"Could not read {Config.ConfigFile}: {Config.LoadError} - fix the file (it's reloaded when saved) or use Settings", "ERROR");
+        }
+
+        /// <summary>
+        /// For tests: the given settings and tracking database, and API requests sent to apiHandler instead of the
+        /// network
+        /// </summary>
+        internal FileWatcherService(Config config, string trackingDbPath, HttpMessageHandler? apiHandler, IStorageUploader? storageUploader)
         {
-            Config = Config.Load();
+            Config = config;
+            httpClient = new HttpClient(apiHandler ?? new SocketsHttpHandler { ConnectTimeout = TimeSpan.FromSeconds(CONNECT_TIMEOUT_SECONDS) });
+            apiClient = new PrintagoApiClient(httpClient, () => Config);
+            apiClient.OnLog += Log;
+            apiClient.OnRateLimited += _ =>
+            {
+                Log($"Printago is rate limiting requests - uploads paused until {DescribeRateLimitEnd()}", "WARN");
+                Notify("Rate limited", $"Resuming at {DescribeRateLimitEnd()}", Config.NOTIFY_ERRORS);
+            };
+            trackingDb = new FileTrackingDb(trackingDbPath);
+
+            LoadPathFilters();
+
+            // Capped so a typo in config.json can't flood the API
+            maxParallelUploads = Math.Clamp(Config.MaxParallelUploads, 1, MAX_PARALLEL_UPLOADS_LIMIT);
+            partUploader = new PartUploader(apiClient, storageUploader ?? new HttpStorageUploader(storageHttpClient), maxParallelUploads, () => Config);
+            partUploader.OnLog += Log;
+            uploadQueue = new SpillQueue(Config.GetQueueJournalFile("live"), () => Config.MaxQueuedInMemory, message => Log(message, "ERROR"));
+            scanUploadQueue = new SpillQueue(Config.GetQueueJournalFile("scan"), () => Config.MaxQueuedInMemory, message => Log(message, "ERROR"));
+
+            WatchConfigFile();
+        }
 
-            // Initialize tracking database in AppData (writable location)
+        /// <summary>
+        /// Tracking database in AppData (writable location)
+        /// </summary>
+        private static string GetDefaultTrackingDbPath()
+        {
             var appDataPath = Path.Combine(
                 Environment.GetFolderPath(Environment.SpecialFolder.LocalApplicationData),
                 "PrintagoFolderWatch"
             );
             Directory.CreateDirectory(appDataPath);
-            var dbPath = Path.Combine(appDataPath, "file-tracking.db");
-            trackingDb = new FileTrackingDb(dbPath);
+            return Path.Combine(appDataPath, "file-tracking.db");
         }
 
         public async Task<bool> Start()
         {
-            if (isRunning || !Config.IsValid())
+            if (isRunning)
                 return false;
 
-            try
+            var problems = Config.Validate();
+            if (problems.Count > 0)
+            {
+                foreach (var problem in problems)
+                {
+                    Log($"Cannot start - {problem}", "ERROR");
+                }
+                StartError = string.Join("\n", problems);
+                return false;
+            }
+
+            // This run's token - Stop cancels it, so a Start still in its initial sync notices and backs out
+            CancellationTokenSource runCts;
+            lock (lifecycleLock)
             {
+                if (isRunning)
+                    return false; // Another Start got here first
+
                 isRunning = true;
-                cts = new CancellationTokenSource();
+                runCts = new CancellationTokenSource();
+                cts = runCts;
+            }
 
+            try
+            {
                 Log("Starting file watcher service...", "INFO");
+                StartError = null;
+                uploadsPausedReason = null;
+                Volatile.Write(ref offline, 0);
+                if (Config.IsDryRun())
+                {
+                    Log("Dry run - nothing will be uploaded, moved or deleted in Printago", "WARN");
+                }
+
+                // A rejected key or store would only fail every request from here on. Network problems don't
+                // stop the start - uploads are retried once the API is reachable again.
+                var connection = await CheckConnection();
+                if (connection.rejected)
+                {
+                    Log($"Cannot start - {connection.message}", "ERROR");
+                    StartError = connection.message;
+                    Notify("Could not start watching", connection.message, Config.NOTIFY_ERRORS);
+                    StopRun(runCts);
+                    return false;
+                }
+                runCts.Token.ThrowIfCancellationRequested();
+
+                LoadPathFilters();
+
+                WriteFailedUploadsFile();
+                var failedCount = GetFailedUploads().Count;
+                if (failedCount > 0)
+                {
+                    Log($"{failedCount} file(s) failed to upload previously - they will be retried if still out of sync", "WARN");
+                }
 
                 // PHASE 1: Build initial cache
                 await BuildInitialCache();
+                runCts.Token.ThrowIfCancellationRequested();
 
                 // PHASE 1.5: Ensure root sync folder exists
                 await EnsureRootSyncFolder();
+                runCts.Token.ThrowIfCancellationRequested();
+
+                SetUploadNewSince();
+
+                var lastScan = trackingDb?.GetLastScanUtc();
+                if (Config.IsSkipInitialScan() && lastScan != null)
+                {
+                    Log($"Skipping the initial scan (last full scan {lastScan.Value.ToLocalTime():g}) - use Sync Now to catch up on offline changes", "INFO");
+                    QueueFailedUploads();
+                }
+                else
+                {
+                    if (Config.IsSkipInitialScan())
+                    {
+                        Log("SkipInitialScan is on, but no full scan has completed yet - scanning now", "INFO");
+                    }
 
-                // PHASE 2: Scan local files
-                await ScanLocalFileSystem();
+                    var scanTimer = System.Diagnostics.Stopwatch.StartNew();
 
-                // PHASE 3: Perform initial sync
-                await PerformInitialSync();
+                    // PHASE 2: Scan local files
+                    await ScanLocalFileSystem(runCts.Token);
 
-                // PHASE 4: Start file system watcher
-                watcher = new FileSystemWatcher(Config.WatchPath)
+                    // PHASE 3: Perform initial sync
+                    var (queuedUploads, queuedDeletions) = await PerformInitialSync(runCts.Token);
+                    trackingDb?.SetLastScanUtc(DateTime.UtcNow);
+
+                    var summary = $"{localFiles.Count} files checked in {scanTimer.Elapsed.TotalSeconds:0.#}s - {queuedUploads} to upload, {queuedDeletions} to delete";
+                    Log($"Initial scan complete: {summary}", "INFO");
+                    Notify("Initial scan complete", summary, Config.NOTIFY_START_STOP);
+                }
+                QueueInterruptedUploads();
+
+                // PHASE 4: Start file system watcher (under the lock, so a concurrent Stop either sees it or we see the Stop)
+                lock (lifecycleLock)
                 {
-                    NotifyFilter = NotifyFilters.FileName | NotifyFilters.DirectoryName | NotifyFilters.LastWrite | NotifyFilters.CreationTime,
-                    EnableRaisingEvents = true,
-                    IncludeSubdirectories = true
-                };
+                    runCts.Token.ThrowIfCancellationRequested();
 
-                watcher.Created += OnFileChanged;
-                watcher.Changed += OnFileChanged;
-                watcher.Deleted += OnFileDeleted;
-                watcher.Renamed += OnFileRenamed;
+                    var newWatchers = new List<FileSystemWatcher>();
+                    var missing = new List<WatchEntry>();
+                    var pollOnly = new HashSet<string>();
+                    foreach (var watch in Config.GetWatches())
+                    {
+                        if (Directory.Exists(watch.Path))
+                        {
+                            try
+                            {
+                                newWatchers.Add(CreateWatcher(watch.Path));
+                            }
+                            catch (Exception ex) when (ex is IOException or PlatformNotSupportedException or ArgumentException)
+                            {
+                                // e.g. the inotify watch limit, or a filesystem without change notifications
+                                var interval = Config.PollIntervalSeconds > 0 ? Config.PollIntervalSeconds : FALLBACK_POLL_SECONDS;
+                                Log($"Can't watch {watch.Path} for changes ({ex.Message}) - checking it every {interval}s instead", "WARN");
+                                pollOnly.Add(watch.Path);
+                            }
+                        }
+                        else
+                        {
+                            Log($"Watch folder not available: {watch.Path} - checking again every {Config.WatchPathRetrySeconds}s", "WARN");
+                            missing.Add(watch);
+                        }
+                    }
+                    watchers = newWatchers;
+                    unavailableWatches = missing;
+                    pollOnlyWatches = pollOnly;
+                }
 
                 // PHASE 5: Start delete processor
-                Task.Run(() => ProcessDeleteQueue(cts.Token));
+                Task.Run(() => ProcessDeleteQueue(runCts.Token));
 
                 // PHASE 6: Start upload processor
-                Task.Run(() => ProcessUploadQueue(cts.Token));
+                Task.Run(() => ProcessUploadQueue(runCts.Token));
+
+                // PHASE 7: Start periodic cache refresh and rescan (every Config.RescanMinutes)
+                Task.Run(() => PeriodicCacheRefresh(runCts.Token));
+
+                // PHASE 8: Re-watch watch folders that come back (every Config.WatchPathRetrySeconds)
+                if (Config.WatchPathRetrySeconds > 0)
+                    Task.Run(() => MonitorWatchFolders(runCts.Token));
+
+                // PHASE 9: List the watch folders for changes the watchers don't see (Config.PollIntervalSeconds)
+                Task.Run(() => PollWatchFolders(runCts.Token));
 
-                // PHASE 7: Start periodic cache refresh (every 30 min)
-                Task.Run(() => PeriodicCacheRefresh(cts.Token));
+                // PHASE 10: Catch up on changes made while the computer was asleep
+                Task.Run(() => WatchForSleep(runCts.Token));
 
-                Log($"Started watching: {Config.WatchPath}", "SUCCESS");
+                Log($"Started watching: {string.Join(", ", Config.GetWatches().Select(w => w.Path))}", "SUCCESS");
+                Notify("Printago", $"Watching {DescribeWatches()}", Config.NOTIFY_START_STOP);
                 return true;
             }
+            catch (OperationCanceledException) when (runCts.IsCancellationRequested)
+            {
+                Log("Start cancelled", "INFO");
+                return false;
+            }
             catch (Exception ex)
             {
                 Log($"Failed to start: {ex.Message}", "ERROR");
+                StartError = ex.Message;
+                Notify("Could not start watching", ex.Message, Config.NOTIFY_ERRORS);
+                StopRun(runCts);
                 return false;
             }
         }
 
-        public void Stop()
+        /// <summary>
+        /// Pick up changed settings (e.g. from the Settings window): finish queued uploads like Exit would, then
+        /// start again. Just starts if not watching.
+        /// </summary>
+        public async Task<bool> RestartAsync()
         {
-            if (!isRunning)
-                return;
-
-            isRunning = false;
-            cts?.Cancel();
-            watcher?.Dispose();
-            watcher = null;
+            if (isRunning)
+            {
+                Log("Restarting with the new settings - finishing queued uploads first", "INFO");
+                await ShutdownAsync(TimeSpan.FromSeconds(Config.ShutdownTimeoutSeconds));
+            }
 
-            Log("Stopped watching", "INFO");
+            return await Start();
         }
 
-        public async Task TriggerSyncNow()
+        public void Stop()
         {
-            Log("Manual sync triggered", "INFO");
-            await BuildInitialCache();
-            await ScanLocalFileSystem();
-            await PerformInitialSync();
+            CancellationTokenSource? runCts;
+            lock (lifecycleLock)
+            {
+                runCts = cts;
+            }
+
+            if (runCts != null && StopRun(runCts))
+            {
+                Log("Stopped watching", "INFO");
+                Notify("Printago", "Stopped watching", Config.NOTIFY_START_STOP);
+            }
         }
 
-        public List<string> GetQueueItems()
+        /// <summary>
+        /// Tear down the run that owns runCts. Does nothing (and returns false) if that run was already stopped,
+        /// so overlapping Stop / failed Start calls are harmless.
+        /// </summary>
+        private bool StopRun(CancellationTokenSource runCts)
         {
-            return uploadQueue.Select(path =>
+            List<FileSystemWatcher> oldWatchers;
+            lock (lifecycleLock)
             {
-                try
-                {
-                    var relativePath = Path.GetRelativePath(Config.WatchPath, path);
-                    return relativePath.Replace("\\", "/");
-                }
-                catch
-                {
-                    return Path.GetFileName(path);
-                }
-            }).ToList();
-        }
+                if (!isRunning || cts != runCts)
+                    return false;
 
-        #region Rate Limiting Helper
+                isRunning = false;
+                runCts.Cancel();
+                oldWatchers = watchers;
+                watchers = new List<FileSystemWatcher>();
+                unavailableWatches = new List<WatchEntry>();
+            }
 
-        private async Task<HttpResponseMessage> SendApiRequestAsync(HttpRequestMessage request, int retryCount = 0)
-        {
-            await apiRateLimiter.WaitAsync();
-            try
+            // Disposing waits for in-progress event callbacks, so do it outside the lock
+            foreach (var oldWatcher in oldWatchers)
             {
-                var now = DateTime.UtcNow;
-                var timeSinceLastCall = now - lastApiCallTime;
-                var minimumDelay = TimeSpan.FromSeconds(2);
+                oldWatcher.Dispose();
+            }
+            partUploader.CancelSignedUrlRequests();
 
-                if (timeSinceLastCall < minimumDelay)
-                {
-                    var waitTime = minimumDelay - timeSinceLastCall;
-                    await Task.Delay(waitTime);
-                }
+            // Changes still waiting out their debounce are never queued - the next start's scan finds them
+            foreach (var path in debounceTimers.Keys)
+            {
+                if (debounceTimers.TryRemove(path, out var timerCts))
+                    timerCts.Cancel();
+            }
+            return true;
+        }
 
-                var response = await httpClient.SendAsync(request);
-                lastApiCallTime = DateTime.UtcNow;
+        /// <summary>
+        /// Stop reacting to file changes, let the workers finish the upload queue and any in-flight PUTs
+        /// (up to the timeout), then stop. Used on exit so queued files aren't abandoned.
+        /// Returns false if uploads were still pending when the timeout expired.
+        /// </summary>
+        public async Task<bool> ShutdownAsync(TimeSpan timeout)
+        {
+            if (!isRunning)
+                return true;
 
-                if (response.StatusCode == System.Net.HttpStatusCode.TooManyRequests && retryCount < 3)
+            // No new events from here on - changes made now are picked up by the initial sync next time
+            lock (lifecycleLock)
+            {
+                foreach (var watcher in watchers)
                 {
-                    var retryDelay = TimeSpan.FromSeconds(Math.Pow(2, retryCount + 2));
-                    Log($"Rate limited (429), retrying in {retryDelay.TotalSeconds}s (attempt {retryCount + 1}/3)", "WARN");
-                    await Task.Delay(retryDelay);
+                    watcher.EnableRaisingEvents = false;
+                }
+            }
 
-                    var retryRequest = new HttpRequestMessage(request.Method, request.RequestUri)
-                    {
-                        Content = request.Content
-                    };
-                    foreach (var header in request.Headers)
-                    {
-                        retryRequest.Headers.TryAddWithoutValidation(header.Key, header.Value);
-                    }
+            var pending = uploadsPausedByUser ? Volatile.Read(ref inFlightUploads) : PendingUploadCount;
+            if (pending > 0)
+            {
+                Log($"Finishing {pending} upload(s) before stopping...", "INFO");
+            }
 
-                    return await SendApiRequestAsync(retryRequest, retryCount + 1);
+            bool drained = true;
+            var deadline = DateTime.UtcNow + timeout;
+            // Paused uploads stay queued - the initial sync on the next start picks them up
+            while ((UploadQueueCount > 0 && !uploadsPausedByUser) || Volatile.Read(ref inFlightUploads) > 0)
+            {
+                if (DateTime.UtcNow >= deadline)
+                {
+                    Log($"Shutdown timed out - {UploadQueueCount} queued and {inFlightUploads} in-progress upload(s) not finished", "WARN");
+                    drained = false;
+                    break;
                 }
+                await Task.Delay(200);
+            }
 
-                return response;
+            Stop();
+            return drained;
+        }
+
+        /// <summary>
+        /// Sync Now: rescan everything and report what was queued, so the user sees it did something
+        /// </summary>
+        public async Task TriggerSyncNow()
+        {
+            Log("Manual sync triggered", "INFO");
+            int queuedUploads, queuedDeletions;
+            try
+            {
+                (queuedUploads, queuedDeletions) = await RunFullSync();
             }
-            finally
+            catch (OperationCanceledException)
             {
-                apiRateLimiter.Release();
+                Log("Sync Now cancelled - watching stopped", "INFO");
+                return;
             }
-        }
 
-        #endregion
+            var summary = queuedUploads == 0 && queuedDeletions == 0
+                ? "Everything is up to date"
+                : $"{queuedUploads} file(s) queued for upload" + (queuedDeletions > 0 ? $", {queuedDeletions} Part(s) to delete" : "");
+            Log($"Sync Now: {summary}", "INFO");
+            Notify("Sync Now", summary, Config.NOTIFY_START_STOP);
+        }
 
-        #region Phase 1: Build Initial Cache
+        /// <summary>
+        /// Re-list Printago, walk the watch folders and queue whatever is out of sync. Throws
+        /// OperationCanceledException if watching stops part-way, before anything is queued or deleted.
+        /// </summary>
+        private async Task<(int uploads, int deletions)> RunFullSync()
+        {
+            var ct = cts?.Token ?? CancellationToken.None;
+            await BuildInitialCache();
+            await ScanLocalFileSystem(ct);
+            var queued = await PerformInitialSync(ct);
+            trackingDb?.SetLastScanUtc(DateTime.UtcNow);
+            return queued;
+        }
+
+        /// <summary>
+        /// Forget every recorded file hash and re-upload every local file, even ones that look up-to-date.
+        /// Part bindings are kept, so existing Parts are updated rather than duplicated.
+        /// </summary>
+        public async Task ForceFullResync()
+        {
+            if (!isRunning)
+            {
+                Log("Force full re-sync ignored - not running", "WARN");
+                return;
+            }
+
+            Log("Force full re-sync: re-hashing and re-uploading every file", "WARN");
+            var cleared = trackingDb?.ClearFileStats() ?? 0;
+            Log($"Cleared recorded state for {cleared} tracked file(s)", "DEBUG");
+
+            await BuildInitialCache();
+            try
+            {
+                await ScanLocalFileSystem(cts?.Token ?? CancellationToken.None);
+            }
+            catch (OperationCanceledException)
+            {
+                Log("Force full re-sync cancelled - watching stopped", "INFO");
+                return;
+            }
+
+            int queued = 0;
+            foreach (var localFile in localFiles.Values)
+            {
+                forceUploadPaths[localFile.FilePath] = true;
+                if (EnqueueUpload(localFile.FilePath, fromScan: true))
+                {
+                    queued++;
+                }
+            }
+
+            Log($"Queued {queued} file(s) for re-upload", "INFO");
+        }
+
+        /// <summary>
+        /// Re-read the config file. If it can't be parsed or is missing required settings, the problem is
+        /// reported and the current settings are kept. If the watch folders or API settings changed while running,
+        /// queued uploads finish with the old settings before watching restarts with the new ones.
+        /// Raises OnConfigReloaded with the result. Also runs when the config file is saved.
+        /// </summary>
+        public async Task<(bool success, string message)> ReloadConfig()
+        {
+            await configReloadLock.WaitAsync();
+            try
+            {
+                var result = await ApplyReloadedConfig();
+                OnConfigReloaded?.Invoke(result.success, result.message);
+                return result;
+            }
+            finally
+            {
+                configReloadLock.Release();
+            }
+        }
+
+        private async Task<(bool success, string message)> ApplyReloadedConfig()
+        {
+            var newConfig = Config.TryLoad(out var error);
+            if (newConfig == null)
+            {
+                Log($"Config not reloaded - {Config.ConfigFile} could not be read: {error}", "ERROR");
+                return (false, $"Could not read {Path.GetFileName(Config.ConfigFile)}: {error}");
+            }
+
+            var problems = newConfig.Validate();
+            if (problems.Count > 0)
+            {
+                foreach (var problem in problems)
+                {
+                    Log($"Config not reloaded - {problem}", "ERROR");
+                }
+                return (false, problems.Count == 1 ? problems[0] : $"{problems[0]} (and {problems.Count - 1} more - see the logs)");
+            }
+
+            var oldConfig = Config;
+            bool needsRestart =
+                !oldConfig.HasSameWatches(newConfig) ||
+                oldConfig.ApiUrl != newConfig.ApiUrl ||
+                oldConfig.ApiKey != newConfig.ApiKey ||
+                oldConfig.StoreId != newConfig.StoreId;
+
+            if (Math.Clamp(newConfig.MaxParallelUploads, 1, MAX_PARALLEL_UPLOADS_LIMIT) != maxParallelUploads)
+            {
+                Log("MaxParallelUploads changes take effect after restarting the app", "WARN");
+            }
+
+            if (isRunning && needsRestart)
+            {
+                Log("Watch folder or API settings changed - finishing queued uploads, then restarting", "INFO");
+                await ShutdownAsync(TimeSpan.FromSeconds(oldConfig.ShutdownTimeoutSeconds));
+
+                Config = newConfig;
+                if (!await Start())
+                {
+                    return (false, $"Config reloaded, but watching could not be restarted: {StartError ?? "see the logs"}");
+                }
+
+                Log($"Reloaded config from {Config.ConfigFile}", "SUCCESS");
+                return (true, $"Now watching {DescribeWatches()}");
+            }
+
+            Config = newConfig;
+            LoadPathFilters();
+            Log($"Reloaded config from {Config.ConfigFile}", "SUCCESS");
+
+            // Start already checked the connection when it restarted above
+            var connection = await CheckConnection();
+            return connection.success
+                ? (true, "Config reloaded - connected to Printago")
+                : (false, $"Config reloaded, but the connection test failed: {connection.message}");
+        }
+
+        /// <summary>
+        /// Why the API refused the key or store at the last connection check (start, Test Connection, config reload),
+        /// or null. The tray apps keep Start Watching disabled until a check succeeds.
+        /// </summary>
+        public string? CredentialsRejectedReason { get; private set; }
+
+        /// <summary>
+        /// Make one lightweight authenticated request with the current settings and describe the result
+        /// (connected, key rejected, store not found, DNS/TLS failure...). Used by the Test Connection menu item.
+        /// </summary>
+        public async Task<(bool success, string message)> TestConnection()
+        {
+            var problems = Config.Validate();
+            if (problems.Count > 0)
+            {
+                return (false, problems[0]);
+            }
+
+            var result = await CheckConnection();
+            return (result.success, result.message);
+        }
+
+        /// <summary>
+        /// rejected is true when the API answered but refused the key or store, as opposed to not being reachable
+        /// </summary>
+        private async Task<(bool success, bool rejected, string message)> CheckConnection()
+        {
+            var apiUrl = Config.ApiUrl.TrimEnd('/');
+            var host = Uri.TryCreate(apiUrl, UriKind.Absolute, out var uri) ? uri.Host : apiUrl;
+
+            try
+            {
+                using var request = new HttpRequestMessage(HttpMethod.Get, $"{apiClient.GetUrl("folders")}?limit=1");
+                apiClient.AddApiHeaders(request);
+
+                using var timeout = new CancellationTokenSource(TimeSpan.FromSeconds(CONNECTION_TEST_TIMEOUT_SECONDS));
+                using var response = await httpClient.SendAsync(request, timeout.Token);
+
+                (bool success, bool rejected, string message) result = (int)response.StatusCode switch
+                {
+                    >= 200 and < 300 => (true, false, $"Connected to {host}"),
+                    401 => (false, true, "The API key was rejected (HTTP 401)"),
+                    403 => (false, true, "The API key has no access to this store (HTTP 403)"),
+                    404 => (false, true, "Store or API not found (HTTP 404) - check the Store ID and API URL"),
+                    var code => (false, false, $"Unexpected response from {host} (HTTP {code})")
+                };
+
+                Log($"Connection test: {result.message}", result.success ? "SUCCESS" : "ERROR");
+                if (result.rejected)
+                    CredentialsRejectedReason = result.message;
+                else if (result.success)
+                    CredentialsRejectedReason = null;
+                if (result.success && uploadsPausedReason != null)
+                {
+                    uploadsPausedReason = null;
+                    Log("Uploads resumed", "INFO");
+                }
+                return result;
+            }
+            catch (Exception ex)
+            {
+                var message = ex switch
+                {
+                    TaskCanceledException => $"No response from {host} after {CONNECTION_TEST_TIMEOUT_SECONDS}s",
+                    HttpRequestException { InnerException: System.Security.Authentication.AuthenticationException } =>
+                        $"Secure connection to {host} failed (TLS/certificate error)",
+                    HttpRequestException { InnerException: System.Net.Sockets.SocketException { SocketErrorCode: System.Net.Sockets.SocketError.HostNotFound } } =>
+                        $"Could not find {host} - check the API URL and your internet connection",
+                    _ => $"Could not reach {host}: {ex.Message}"
+                };
+
+                Log($"Connection test: {message}", "ERROR");
+                return (false, false, message);
+            }
+        }
+
+        private void WatchConfigFile()
+        {
+            try
+            {
+                configWatcher = new FileSystemWatcher(Path.GetDirectoryName(Config.ConfigFile)!, Path.GetFileName(Config.ConfigFile))
+                {
+                    NotifyFilter = NotifyFilters.LastWrite | NotifyFilters.FileName | NotifyFilters.Size,
+                    EnableRaisingEvents = true
+                };
+
+                configWatcher.Changed += OnConfigFileChanged;
+                configWatcher.Created += OnConfigFileChanged;
+                configWatcher.Renamed += OnConfigFileChanged; // Editors that save to a temp file and rename it over
+            }
+            catch (Exception ex)
+            {
+                Log($"Not watching {Config.ConfigFile} for changes: {ex.Message}", "WARN");
+            }
+        }
+
+        private async void OnConfigFileChanged(object sender, FileSystemEventArgs e)
+        {
+            // One save raises several events - reload once they stop
+            var debounce = new CancellationTokenSource();
+            Interlocked.Exchange(ref configReloadDebounce, debounce)?.Cancel();
+            try
+            {
+                await Task.Delay(CONFIG_RELOAD_DEBOUNCE_MS, debounce.Token);
+            }
+            catch (OperationCanceledException)
+            {
+                return;
+            }
+
+            try
+            {
+                // Written by our own Save (e.g. the Settings window) - nothing new to load
+                if (File.GetLastWriteTimeUtc(Config.ConfigFile) == Config.LastSavedWriteUtc)
+                    return;
+            }
+            catch
+            {
+                // Fall through and let ReloadConfig report the problem
+            }
+
+            Log("Config file changed - reloading", "INFO");
+            await ReloadConfig();
+        }
+
+        public List<string> GetQueueItems()
+        {
+            // In the order they'll be uploaded, up to the first files spilled to a journal - the rest are only counted
+            var items = uploadQueue.PeekInMemory();
+            int more;
+            if (uploadQueue.SpilledCount > 0)
+            {
+                more = uploadQueue.SpilledCount + scanUploadQueue.Count;
+            }
+            else
+            {
+                items.AddRange(scanUploadQueue.PeekInMemory().Where(path => filesInUploadQueue.TryGetValue(path, out var fromScan) && fromScan));
+                more = scanUploadQueue.SpilledCount;
+            }
+
+            var list = items.Select(path =>
+            {
+                try
+                {
+                    return GetRelativeUploadPath(path);
+                }
+                catch
+                {
+                    return Path.GetFileName(path);
+                }
+            }).ToList();
+
+            if (more > 0)
+            {
+                list.Add($"… and {more} more");
+            }
+            return list;
+        }
+
+        #region Phase 1: Build Initial Cache
 
         private async Task BuildInitialCache()
         {
             Log("========== PHASE 1: BUILD INITIAL CACHE ==========", "INFO");
             Log("Fetching all folders and parts from Printago...", "INFO");
 
-            var apiUrl = Config.ApiUrl.TrimEnd('/');
-
-            var folders = await FetchAllFolders(apiUrl);
+            var folders = await FetchAllFolders();
             Log($"✓ Fetched {folders.Count} folders from API", "INFO");
 
-            var parts = await FetchAllParts(apiUrl);
+            var parts = await FetchAllParts();
             Log($"✓ Fetched {parts.Count} parts from API", "INFO");
 
             remoteFolders.Clear();
@@ -353,20 +1207,18 @@ namespace PrintagoFolderWatch.Core
             Log($"========== CACHE COMPLETE ==========", "INFO");
         }
 
-        private async Task<List<FolderDto>> FetchAllFolders(string apiUrl)
+        private async Task<List<FolderDto>> FetchAllFolders()
         {
             try
             {
-                var request = new HttpRequestMessage(HttpMethod.Get, $"{apiUrl}/v1/folders?limit=10000");
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                var request = new HttpRequestMessage(HttpMethod.Get, $"{apiClient.GetUrl("folders")}?limit=10000");
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
 
                 if (!response.IsSuccessStatusCode)
                 {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Error fetching folders: HTTP {response.StatusCode}", "ERROR");
+                    Log($"Error fetching folders: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                     return new List<FolderDto>();
                 }
 
@@ -382,20 +1234,18 @@ namespace PrintagoFolderWatch.Core
             }
         }
 
-        private async Task<List<PartDto>> FetchAllParts(string apiUrl)
+        private async Task<List<PartDto>> FetchAllParts()
         {
             try
             {
-                var request = new HttpRequestMessage(HttpMethod.Get, $"{apiUrl}/v1/parts?limit=10000");
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                var request = new HttpRequestMessage(HttpMethod.Get, $"{apiClient.GetUrl("parts")}?limit=10000");
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
 
                 if (!response.IsSuccessStatusCode)
                 {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Error fetching parts: HTTP {response.StatusCode}", "ERROR");
+                    Log($"Error fetching parts: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                     return new List<PartDto>();
                 }
 
@@ -435,9 +1285,14 @@ namespace PrintagoFolderWatch.Core
                 return;
             }
 
+            if (Config.IsDryRun())
+            {
+                Log($"DRY RUN: would create '{ROOT_SYNC_FOLDER}' folder", "INFO");
+                return;
+            }
+
             try
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
                 var createBody = new
                 {
                     name = ROOT_SYNC_FOLDER,
@@ -445,14 +1300,13 @@ namespace PrintagoFolderWatch.Core
                     parentId = (string?)null
                 };
 
-                var request = new HttpRequestMessage(HttpMethod.Post, $"{apiUrl}/v1/folders")
+                var request = new HttpRequestMessage(HttpMethod.Post, apiClient.GetUrl("folders"))
                 {
                     Content = new StringContent(JsonConvert.SerializeObject(createBody), Encoding.UTF8, "application/json")
                 };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
                 var json = await response.Content.ReadAsStringAsync();
                 var created = JsonConvert.DeserializeAnonymousType(json, new { id = "" });
 
@@ -479,28 +1333,66 @@ namespace PrintagoFolderWatch.Core
 
         #region Phase 2: Scan Local Files
 
-        private async Task ScanLocalFileSystem()
+        /// <summary>
+        /// Walk the watch folders into localFiles, SCAN_PARALLELISM top-level subfolders at a time. Stops between
+        /// folders once ct is cancelled; nothing is written to the tracking database here, so a cancelled scan
+        /// leaves it as it was.
+        /// </summary>
+        private async Task ScanLocalFileSystem(CancellationToken ct)
         {
             Log("========== PHASE 2: SCAN LOCAL FILES ==========", "INFO");
-            Log($"Scanning directory: {Config.WatchPath}", "INFO");
             localFiles.Clear();
 
-            await Task.Run(() =>
+            isScanning = true;
+            try
+            {
+                await Task.Run(() =>
+                {
+                    foreach (var watch in Config.GetWatches())
+                    {
+                        // Its files are left alone in Printago until it's back (see PerformInitialSync)
+                        if (!Directory.Exists(watch.Path))
+                        {
+                            Log($"Not scanning {watch.Path} - not available", "WARN");
+                            continue;
+                        }
+
+                        Log($"Scanning directory: {watch.Path}", "INFO");
+                        var subDirs = ScanFiles(watch.Path);
+                        Parallel.ForEach(subDirs, new ParallelOptions { MaxDegreeOfParallelism = SCAN_PARALLELISM, CancellationToken = ct },
+                            subDir => ScanDirectory(subDir, ct));
+                    }
+                }, ct);
+            }
+            finally
             {
-                ScanDirectory(Config.WatchPath);
-            });
+                isScanning = false;
+            }
 
             Log($"✓ Found {localFiles.Count} local files", "INFO");
             Log($"========== SCAN COMPLETE ==========", "INFO");
         }
 
-        private void ScanDirectory(string dirPath)
+        private void ScanDirectory(string dirPath, CancellationToken ct)
+        {
+            ct.ThrowIfCancellationRequested();
+            foreach (var subDir in ScanFiles(dirPath))
+            {
+                ScanDirectory(subDir, ct);
+            }
+        }
+
+        /// <summary>
+        /// Add the supported files directly in dirPath and return its subfolders that aren't excluded
+        /// </summary>
+        private List<string> ScanFiles(string dirPath)
         {
+            var subDirs = new List<string>();
             try
             {
                 foreach (var file in Directory.GetFiles(dirPath))
                 {
-                    if (IsSupportedFile(file))
+                    if (IsSupportedFile(file, logExclusion: true))
                     {
                         AddLocalFile(file);
                     }
@@ -508,13 +1400,22 @@ namespace PrintagoFolderWatch.Core
 
                 foreach (var subDir in Directory.GetDirectories(dirPath))
                 {
-                    ScanDirectory(subDir);
+                    // Don't walk excluded subtrees at all
+                    var exclusionReason = GetExclusionReason(subDir);
+                    if (exclusionReason != null)
+                    {
+                        Log($"Skipping folder {Path.GetFileName(subDir)}: {exclusionReason}", "DEBUG");
+                        continue;
+                    }
+
+                    subDirs.Add(subDir);
                 }
             }
             catch (Exception ex)
             {
                 Log($"Error scanning {dirPath}: {ex.Message}", "ERROR");
             }
+            return subDirs;
         }
 
         private void AddLocalFile(string filePath)
@@ -522,15 +1423,15 @@ namespace PrintagoFolderWatch.Core
             try
             {
                 var fileInfo = new FileInfo(filePath);
-                var relativePath = Path.GetRelativePath(Config.WatchPath, filePath);
-                var folderPath = Path.GetDirectoryName(relativePath)?.Replace("\\", "/") ?? "";
+                var relativePath = GetRelativeUploadPath(filePath);
+                var folderPath = Config.GetCloudFolder(relativePath);
                 // PartName is WITHOUT extension (for Printago API)
                 var partName = Path.GetFileNameWithoutExtension(fileInfo.Name);
 
                 var localFile = new LocalFileInfo
                 {
                     FilePath = filePath,
-                    RelativePath = relativePath.Replace("\\", "/"),
+                    RelativePath = relativePath,
                     FolderPath = folderPath,
                     FileName = fileInfo.Name,
                     PartName = partName,
@@ -556,8 +1457,13 @@ namespace PrintagoFolderWatch.Core
 
         #region Phase 3: Initial Sync
 
-        private async Task PerformInitialSync()
+        /// <summary>
+        /// Compare local files with the Parts in Printago and queue the uploads and deletes needed.
+        /// Returns how many of each were queued.
+        /// </summary>
+        private async Task<(int uploads, int deletions)> PerformInitialSync(CancellationToken ct)
         {
+            int queuedUploads = 0, queuedDeletions = 0;
             Log("========== PHASE 3: INITIAL SYNC ==========", "INFO");
 
             int iteration = 0;
@@ -575,22 +1481,41 @@ namespace PrintagoFolderWatch.Core
                 var uploads = new List<LocalFileInfo>();
 
                 Log("STEP 1: Reconciling with tracking database...", "INFO");
-                int foldersDeleted = await ReconcileWithTrackingDb();
+                int foldersDeleted;
+                Volatile.Write(ref scanFilesChecked, 0);
+                isCheckingFiles = true;
+                try
+                {
+                    foldersDeleted = await ReconcileWithTrackingDb(ct);
+                }
+                finally
+                {
+                    isCheckingFiles = false;
+                }
+                ct.ThrowIfCancellationRequested();
 
                 Log("STEP 2: Finding remote parts to delete...", "INFO");
+                int keptWithoutLocalFile = 0;
+                int keptUntracked = 0;
+                int keptUnavailable = 0;
                 foreach (var kvp in remoteParts)
                 {
                     var key = kvp.Key;
                     var partsList = kvp.Value;
 
-                    if (!localFiles.ContainsKey(key))
+                    if (!localFiles.ContainsKey(key) && !Config.SyncDeletes)
+                    {
+                        keptWithoutLocalFile += partsList.Count;
+                    }
+                    else if (!localFiles.ContainsKey(key))
                     {
                         foreach (var remotePart in partsList)
                         {
                             var tracked = trackingDb?.GetAll().FirstOrDefault(t => t.PartId == remotePart.Id);
                             if (tracked == null)
                             {
-                                deletions.Add(remotePart);
+                                // Never uploaded from here (or the tracking database was reset) - not ours to delete
+                                keptUntracked++;
                             }
                             else
                             {
@@ -598,10 +1523,15 @@ namespace PrintagoFolderWatch.Core
                                 {
                                     Log($"  Skipping deletion of {key} - tracked to {tracked.FilePath}", "DEBUG");
                                 }
+                                else if (IsInUnavailableWatch(tracked.FilePath))
+                                {
+                                    // Not deleted - its drive just isn't connected
+                                    keptUnavailable++;
+                                }
                                 else
                                 {
+                                    // Its tracking entry goes once the delete has really happened (DeletePart)
                                     deletions.Add(remotePart);
-                                    trackingDb?.Delete(tracked.FilePath);
                                 }
                             }
                         }
@@ -630,9 +1560,38 @@ namespace PrintagoFolderWatch.Core
                     }
                 }
 
+                if (keptWithoutLocalFile > 0)
+                {
+                    Log($"  Keeping {keptWithoutLocalFile} Part(s) with no local file (SyncDeletes is off)", "INFO");
+                }
+                if (keptUnavailable > 0)
+                {
+                    Log($"  Keeping {keptUnavailable} Part(s) whose watch folder isn't available", "INFO");
+                }
+                if (keptUntracked > 0)
+                {
+                    Log($"  Keeping {keptUntracked} Part(s) with no local file that weren't uploaded from this computer", "INFO");
+                }
+
                 Log("STEP 3: Finding local files to upload...", "INFO");
+                int skippedUnavailable = 0;
+                int skippedPreExisting = 0;
                 foreach (var localFile in localFiles.Values)
                 {
+                    var skipReason = GetUploadSkipReason(localFile.FilePath);
+                    if (skipReason != null)
+                    {
+                        Log($"  Skipping {localFile.RelativePath}: {skipReason}", "INFO");
+                        skippedUnavailable++;
+                        continue;
+                    }
+
+                    if (IsPreExisting(localFile.FilePath))
+                    {
+                        skippedPreExisting++;
+                        continue;
+                    }
+
                     var key = string.IsNullOrEmpty(localFile.FolderPath)
                         ? localFile.PartName
                         : $"{localFile.FolderPath}/{localFile.PartName}";
@@ -667,6 +1626,15 @@ namespace PrintagoFolderWatch.Core
                     }
                 }
 
+                if (skippedUnavailable > 0)
+                {
+                    Log($"  Skipped {skippedUnavailable} empty or cloud-only file(s)", "INFO");
+                }
+                if (skippedPreExisting > 0)
+                {
+                    Log($"  Left {skippedPreExisting} file(s) unchanged since {uploadNewSinceUtc!.Value.ToLocalTime():g} alone (UploadExisting is off)", "INFO");
+                }
+
                 Log($"✓ Sync plan: {deletions.Count} deletions, {uploads.Count} uploads", "INFO");
 
                 if (foldersDeleted > 0)
@@ -684,17 +1652,101 @@ namespace PrintagoFolderWatch.Core
                 {
                     deleteQueue.Enqueue(part);
                 }
+                queuedDeletions += deletions.Count;
+
+                // Files already queued (e.g. Sync Now during a live upload) keep their place
+                var queued = uploads.Select(f => f.FilePath).Where(path => EnqueueUpload(path, fromScan: true)).ToList();
+                queuedUploads += queued.Count;
 
-                foreach (var file in uploads)
+                // The workers only ever need MaxParallelUploads URLs at once, so fetch full batches ahead of them
+                if (queued.Count > 1 && !Config.IsDryRun())
                 {
-                    uploadQueue.Enqueue(file.FilePath);
+                    _ = partUploader.PrefetchSignedUrlsAsync(queued, GetCloudPath, cts?.Token ?? CancellationToken.None);
                 }
             }
 
             Log("========== ALL SYNC ITERATIONS COMPLETE ==========", "INFO");
+            return (queuedUploads, queuedDeletions);
+        }
+
+        /// <summary>
+        /// UploadExisting off: the first start with it off records when, and files unchanged since then are never
+        /// uploaded by a scan. Turning it back on forgets the time, so everything is in sync again.
+        /// </summary>
+        private void SetUploadNewSince()
+        {
+            if (Config.UploadExisting)
+            {
+                uploadNewSinceUtc = null;
+                trackingDb?.SetUploadNewSinceUtc(null);
+                return;
+            }
+
+            uploadNewSinceUtc = trackingDb?.GetUploadNewSinceUtc() ?? uploadNewSinceUtc;
+            if (uploadNewSinceUtc == null)
+            {
+                uploadNewSinceUtc = DateTime.UtcNow;
+                trackingDb?.SetUploadNewSinceUtc(uploadNewSinceUtc);
+                Log("UploadExisting is off - files already in the watch folders won't be uploaded, only new and changed ones", "INFO");
+            }
+        }
+
+        /// <summary>
+        /// UploadExisting is off and the file hasn't been written or copied in since uploadNewSinceUtc.
+        /// A copy keeps the original's modified time but gets a new creation time, so the later of the two counts.
+        /// </summary>
+        private bool IsPreExisting(string filePath)
+        {
+            var since = uploadNewSinceUtc;
+            if (since == null)
+                return false;
+
+            try
+            {
+                var info = new FileInfo(filePath);
+                var changed = info.LastWriteTimeUtc > info.CreationTimeUtc ? info.LastWriteTimeUtc : info.CreationTimeUtc;
+                return changed < since.Value;
+            }
+            catch
+            {
+                return false;
+            }
+        }
+
+        /// <summary>
+        /// Uploads cut off by the last Stop or Exit. The scan usually finds them anyway, but not with SkipInitialScan.
+        /// Ones that turn out to be up to date are skipped by the hash check.
+        /// </summary>
+        private void QueueInterruptedUploads()
+        {
+            var pending = trackingDb?.GetPendingUploads() ?? new List<string>();
+            foreach (var filePath in pending.Where(path => !File.Exists(path) || !IsSupportedFile(path)))
+                trackingDb?.RemovePendingUpload(filePath);
+
+            int queued = pending.Count(path => File.Exists(path) && IsSupportedFile(path) && EnqueueUpload(path, fromScan: true));
+            if (queued > 0)
+            {
+                Log($"Queued {queued} upload(s) interrupted when watching last stopped", "INFO");
+            }
+        }
+
+        /// <summary>
+        /// Without an initial scan, files that failed last time would wait for their next change - queue them now
+        /// </summary>
+        private void QueueFailedUploads()
+        {
+            int queued = GetFailedUploads().Count(failed => IsSupportedFile(failed.FilePath) && EnqueueUpload(failed.FilePath, fromScan: true));
+            if (queued > 0)
+            {
+                Log($"Queued {queued} previously failed upload(s) for another try", "INFO");
+            }
         }
 
-        private async Task<int> ReconcileWithTrackingDb()
+        /// <summary>
+        /// Hash new and changed files and match them to tracked and remote Parts. Checks ct between files, so a
+        /// cancelled check never leaves a file's tracking entry half updated.
+        /// </summary>
+        private async Task<int> ReconcileWithTrackingDb(CancellationToken ct)
         {
             if (trackingDb == null)
                 return 0;
@@ -704,13 +1756,30 @@ namespace PrintagoFolderWatch.Core
 
             foreach (var localFile in localFiles.Values)
             {
+                ct.ThrowIfCancellationRequested();
+                Interlocked.Increment(ref scanFilesChecked);
+                var trackedByPath = trackingDb.GetByPath(localFile.FilePath);
+                bool hashed = false;
+
                 if (string.IsNullOrEmpty(localFile.FileHash))
                 {
-                    localFile.FileHash = await ComputeFileHash(localFile.FilePath);
+                    // Same size and modified time as when the recorded hash was computed - don't re-read the file
+                    if (trackedByPath != null && trackedByPath.FileSize == localFile.FileSize && trackedByPath.LastWriteUtc == localFile.LastModified)
+                    {
+                        localFile.FileHash = trackedByPath.FileHash;
+                    }
+                    else if (SkipsPlaceholder(localFile.FilePath))
+                    {
+                        // Hashing would download it - leave it until it's on this computer
+                        continue;
+                    }
+                    else
+                    {
+                        localFile.FileHash = await ComputeFileHash(localFile.FilePath);
+                        hashed = true;
+                    }
                 }
 
-                var trackedByPath = trackingDb.GetByPath(localFile.FilePath);
-
                 if (trackedByPath != null)
                 {
                     if (trackedByPath.FileHash != localFile.FileHash)
@@ -720,6 +1789,11 @@ namespace PrintagoFolderWatch.Core
                         updated++;
                     }
 
+                    if (hashed)
+                    {
+                        trackingDb.UpdateFileStat(localFile.FilePath, localFile.FileSize, localFile.LastModified);
+                    }
+
                     // Check if the Part's folder in Printago matches the current local folder
                     if (!string.IsNullOrEmpty(trackedByPath.PartId))
                     {
@@ -861,41 +1935,66 @@ namespace PrintagoFolderWatch.Core
         private async Task<string> ComputeFileHash(string filePath)
         {
             using var sha256 = SHA256.Create();
-            using var stream = File.OpenRead(filePath);
+            using var stream = await PartUploader.OpenFileForReading(filePath, Log, CancellationToken.None);
             var hash = await sha256.ComputeHashAsync(stream);
             return BitConverter.ToString(hash).Replace("-", "").ToLowerInvariant();
         }
 
-        private async Task DeletePart(PartCache part)
+        private async Task<UploadResult> DeletePart(PartCache part)
         {
+            var key = string.IsNullOrEmpty(part.FolderPath)
+                ? part.Name
+                : $"{part.FolderPath}/{part.Name}";
+
+            if (Config.IsDryRun() || Config.DryRunDeletes)
+            {
+                Log($"DRY RUN: would delete remote Part: {key}", "INFO");
+                return UploadResult.Success;
+            }
+
             try
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
-                var request = new HttpRequestMessage(HttpMethod.Delete, $"{apiUrl}/v1/parts/{part.Id}");
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                var request = new HttpRequestMessage(HttpMethod.Delete, apiClient.GetUrl($"parts/{part.Id}"));
+                apiClient.AddApiHeaders(request);
 
-                await SendApiRequestAsync(request);
+                using var response = await apiClient.SendAsync(request);
 
-                var key = string.IsNullOrEmpty(part.FolderPath)
-                    ? part.Name
-                    : $"{part.FolderPath}/{part.Name}";
+                // Already gone counts as deleted
+                if (!response.IsSuccessStatusCode && response.StatusCode != System.Net.HttpStatusCode.NotFound)
+                {
+                    Log($"Remote delete failed: {key} - {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
+                    return PartUploader.ClassifyFailure(response.StatusCode);
+                }
 
                 remoteParts.TryRemove(key, out _);
 
-                Log($"Deleted: {key}", "INFO");
+                // Forget the file only now the Part is really gone (never in a dry run), unless it's back again
+                var tracked = trackingDb?.GetByPartId(part.Id);
+                if (tracked != null && !File.Exists(tracked.FilePath))
+                {
+                    trackingDb?.Delete(tracked.FilePath);
+                }
+
+                Log($"Deleted remote Part: {key}", "INFO");
+                return UploadResult.Success;
             }
             catch (Exception ex)
             {
-                Log($"Error deleting part {part.Name}: {ex.Message}", "ERROR");
+                Log($"Remote delete failed: {key} - {ex.Message}", "ERROR");
+                return PartUploader.ClassifyFailure(ex);
             }
         }
 
         private async Task UpdatePartFolder(string partId, string newFolderPath)
         {
+            if (Config.IsDryRun())
+            {
+                Log($"DRY RUN: would move Part {partId} to '{newFolderPath}'", "INFO");
+                return;
+            }
+
             try
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
                 var newFolderId = await GetOrCreateFolder(newFolderPath);
 
                 if (newFolderId == null)
@@ -906,14 +2005,13 @@ namespace PrintagoFolderWatch.Core
 
                 var updateBody = new { folderId = newFolderId };
 
-                var request = new HttpRequestMessage(HttpMethod.Patch, $"{apiUrl}/v1/parts/{partId}")
+                var request = new HttpRequestMessage(HttpMethod.Patch, apiClient.GetUrl($"parts/{partId}"))
                 {
                     Content = new StringContent(JsonConvert.SerializeObject(updateBody), Encoding.UTF8, "application/json")
                 };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
 
                 if (response.IsSuccessStatusCode)
                 {
@@ -922,8 +2020,7 @@ namespace PrintagoFolderWatch.Core
                 }
                 else
                 {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Failed to move Part {partId}: HTTP {response.StatusCode}", "ERROR");
+                    Log($"Failed to move Part {partId}: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                 }
             }
             catch (Exception ex)
@@ -932,46 +2029,16 @@ namespace PrintagoFolderWatch.Core
             }
         }
 
-        private async Task<bool> UpdatePartFile(string partId, string filePath, string storagePath)
+        private async Task UpdatePartNameAndFolder(string partId, string newName, string newFolderPath)
         {
-            try
-            {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
-                var updateBody = new { fileUris = new[] { storagePath } };
-
-                var request = new HttpRequestMessage(HttpMethod.Patch, $"{apiUrl}/v1/parts/{partId}")
-                {
-                    Content = new StringContent(JsonConvert.SerializeObject(updateBody), Encoding.UTF8, "application/json")
-                };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
-
-                var response = await SendApiRequestAsync(request);
-
-                if (response.IsSuccessStatusCode)
-                {
-                    Log($"✓ Updated Part {partId} with new file", "SUCCESS");
-                    return true;
-                }
-                else
-                {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Failed to update Part {partId} file: HTTP {response.StatusCode}", "ERROR");
-                    return false;
-                }
-            }
-            catch (Exception ex)
+            if (Config.IsDryRun())
             {
-                Log($"Error updating Part {partId} file: {ex.Message}", "ERROR");
-                return false;
+                Log($"DRY RUN: would rename Part {partId} to '{newName}' in '{newFolderPath}'", "INFO");
+                return;
             }
-        }
 
-        private async Task UpdatePartNameAndFolder(string partId, string newName, string newFolderPath)
-        {
             try
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
                 var newFolderId = await GetOrCreateFolder(newFolderPath);
 
                 if (newFolderId == null)
@@ -982,14 +2049,13 @@ namespace PrintagoFolderWatch.Core
 
                 var updateBody = new { name = newName, folderId = newFolderId };
 
-                var request = new HttpRequestMessage(HttpMethod.Patch, $"{apiUrl}/v1/parts/{partId}")
+                var request = new HttpRequestMessage(HttpMethod.Patch, apiClient.GetUrl($"parts/{partId}"))
                 {
                     Content = new StringContent(JsonConvert.SerializeObject(updateBody), Encoding.UTF8, "application/json")
                 };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
 
                 if (response.IsSuccessStatusCode)
                 {
@@ -1004,8 +2070,7 @@ namespace PrintagoFolderWatch.Core
                 }
                 else
                 {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Failed to rename Part {partId}: HTTP {response.StatusCode}", "ERROR");
+                    Log($"Failed to rename Part {partId}: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                 }
             }
             catch (Exception ex)
@@ -1018,42 +2083,179 @@ namespace PrintagoFolderWatch.Core
 
         #region File System Events
 
-        private bool IsSupportedFile(string filePath)
+        private bool IsExcluded(string path)
+        {
+            return GetExclusionReason(path) != null;
+        }
+
+        /// <summary>
+        /// Why a path is never synced, or null if it isn't excluded
+        /// </summary>
+        private string? GetExclusionReason(string path)
+        {
+            if (Config.IsInMoveToDir(path))
+                return "inside MoveToDir";
+
+            var watch = Config.FindWatch(path);
+            if (watch == null)
+                return null;
+
+            var relativePath = Path.GetRelativePath(watch.Path, path);
+            if (builtInFilter.IsExcluded(relativePath))
+                return "temp, backup or system file";
+
+            // Only files - a folder called "Models (2)" is just a name
+            if (PathFilter.IsCopyName(Path.GetFileName(path), Environment.MachineName) && !Directory.Exists(path))
+                return "conflict or autosave copy";
+
+            if (!Config.UploadHiddenFiles && (hiddenFilter.IsExcluded(relativePath) || HasHiddenAttribute(watch.Path, path)))
+                return "hidden (see UploadHiddenFiles)";
+
+            if (pathFilters.TryGetValue(watch.Path, out var pathFilter) && pathFilter.IsExcluded(relativePath))
+                return $"matches ExcludePatterns or {PathFilter.IGNORE_FILE_NAME}";
+
+            return null;
+        }
+
+        /// <summary>
+        /// Windows hides files with an attribute rather than a leading dot - check the path and the folders
+        /// above it, up to the watch folder. Paths that no longer exist are skipped.
+        /// </summary>
+        private static bool HasHiddenAttribute(string watchPath, string path)
+        {
+            if (!OperatingSystem.IsWindows())
+                return false;
+
+            var root = Path.TrimEndingDirectorySeparator(Path.GetFullPath(watchPath));
+            for (var current = Path.GetFullPath(path);
+                 !string.IsNullOrEmpty(current) && current.Length > root.Length;
+                 current = Path.GetDirectoryName(current))
+            {
+                try
+                {
+                    if ((File.Exists(current) || Directory.Exists(current)) &&
+                        File.GetAttributes(current).HasFlag(FileAttributes.Hidden))
+                    {
+                        return true;
+                    }
+                }
+                catch (Exception)
+                {
+                    // Removed or not readable - treat as not hidden
+                }
+            }
+
+            return false;
+        }
+
+        private void LoadPathFilters()
+        {
+            var filters = new Dictionary<string, PathFilter>(StringComparer.OrdinalIgnoreCase);
+            foreach (var watch in Config.GetWatches())
+            {
+                filters[watch.Path] = PathFilter.Load(watch.Path, Config.ExcludePatterns);
+            }
+            pathFilters = filters;
+        }
+
+        /// <summary>
+        /// The watch folder for status text, or how many there are
+        /// </summary>
+        private string DescribeWatches()
+        {
+            var watches = Config.GetWatches();
+            return watches.Count == 1 ? watches[0].Path : $"{watches.Count} folders";
+        }
+
+        /// <summary>
+        /// With logExclusion, a file of a synced type that is skipped by an exclusion is logged (DEBUG) with the reason
+        /// </summary>
+        private bool IsSupportedFile(string filePath, bool logExclusion = false)
         {
             var fileName = Path.GetFileName(filePath).ToLower();
 
-            if (fileName.EndsWith(".gcode.3mf"))
-                return true;
+            if (!Config.IsExtensionIncluded(filePath))
+                return false;
 
             var ext = Path.GetExtension(filePath).ToLower();
-            return ext == ".stl" || ext == ".3mf" || ext == ".scad" || ext == ".step" || ext == ".stp";
+            if (!fileName.EndsWith(".gcode.3mf") &&
+                !(ext == ".stl" || ext == ".3mf" || ext == ".scad" || ext == ".step" || ext == ".stp"))
+                return false;
+
+            var exclusionReason = GetExclusionReason(filePath);
+            if (exclusionReason != null)
+            {
+                if (logExclusion)
+                    Log($"Skipping {Path.GetFileName(filePath)}: {exclusionReason}", "DEBUG");
+                return false;
+            }
+
+            return true;
         }
 
         private async void OnFileChanged(object sender, FileSystemEventArgs e)
         {
-            if (IsSupportedFile(e.FullPath))
+            // Folders are never uploaded themselves - a new one is walked for the files inside it
+            if (Directory.Exists(e.FullPath))
             {
-                var now = DateTime.UtcNow;
-                if (lastEventTime.TryGetValue(e.FullPath, out var lastTime))
+                if (e.ChangeType == WatcherChangeTypes.Created)
                 {
-                    if ((now - lastTime).TotalMilliseconds < DEBOUNCE_MS)
-                    {
-                        return;
-                    }
+                    OnDirectoryCreated(e.FullPath);
+                }
+                return;
+            }
+
+            var ignoreFileWatch = Config.FindWatch(e.FullPath);
+            if (ignoreFileWatch != null &&
+                string.Equals(e.FullPath, Path.Combine(ignoreFileWatch.Path, PathFilter.IGNORE_FILE_NAME), StringComparison.OrdinalIgnoreCase))
+            {
+                pathFilters = new Dictionary<string, PathFilter>(pathFilters, StringComparer.OrdinalIgnoreCase)
+                {
+                    [ignoreFileWatch.Path] = PathFilter.Load(ignoreFileWatch.Path, Config.ExcludePatterns)
+                };
+                Log($"Reloaded {PathFilter.IGNORE_FILE_NAME} in {ignoreFileWatch.Path}", "INFO");
+                return;
+            }
+
+            if (IsSupportedFile(e.FullPath, logExclusion: true))
+            {
+                if (!await WaitForQuietPeriod(e.FullPath))
+                {
+                    return; // A newer event for this path restarted the timer
+                }
+
+                // Deleted during the quiet period (e.g. a slicer's temp file)
+                if (!File.Exists(e.FullPath))
+                {
+                    return;
                 }
-                lastEventTime[e.FullPath] = now;
 
                 AddLocalFile(e.FullPath);
 
                 try
                 {
                     var fileInfo = new FileInfo(e.FullPath);
-                    var relativePath = Path.GetRelativePath(Config.WatchPath, e.FullPath);
-                    var folderPath = Path.GetDirectoryName(relativePath)?.Replace("\\", "/") ?? "";
+                    var relativePath = GetRelativeUploadPath(e.FullPath);
+                    var folderPath = Config.GetCloudFolder(relativePath);
                     // PartName is WITHOUT extension (for Printago API)
                     var partName = Path.GetFileNameWithoutExtension(fileInfo.Name);
                     var fileHash = await ComputeFileHash(e.FullPath);
 
+                    // Same content as the Part already tracked at this path (repeat Changed events for one
+                    // save, or a touch) - nothing to upload or move
+                    if (await TryCompleteMove(e.FullPath, fileInfo.Length, fileHash, partName, folderPath))
+                    {
+                        return;
+                    }
+
+                    var trackedByPath = trackingDb?.GetByPath(e.FullPath);
+                    if (trackedByPath != null && trackedByPath.FileHash == fileHash &&
+                        remoteParts.Values.Any(list => list.Any(p => p.Id == trackedByPath.PartId && p.FileHash == fileHash && p.FolderPath == folderPath)))
+                    {
+                        Log($"Unchanged: {partName}", "DEBUG");
+                        return;
+                    }
+
                     var tracked = trackingDb?.GetByHash(fileHash);
                     if (tracked != null)
                     {
@@ -1094,7 +2296,10 @@ namespace PrintagoFolderWatch.Core
                             CreatedAt = DateTime.UtcNow
                         });
 
-                        await UpdatePartFolder(existingByHash.Id, folderPath);
+                        if (existingByHash.FolderPath != folderPath)
+                        {
+                            await UpdatePartFolder(existingByHash.Id, folderPath);
+                        }
                         return;
                     }
                 }
@@ -1103,20 +2308,150 @@ namespace PrintagoFolderWatch.Core
                     Log($"Error checking for duplicate: {ex.Message}", "WARN");
                 }
 
-                if (filesInUploadQueue.TryAdd(e.FullPath, true))
+                if (EnqueueUpload(e.FullPath))
                 {
-                    uploadQueue.Enqueue(e.FullPath);
                     Log($"Detected change: {Path.GetFileName(e.FullPath)}", "INFO");
                 }
             }
         }
 
+        /// <summary>
+        /// How long a deletion waits before it's confirmed: long enough for an atomic save to put the
+        /// file back, or for the other half of a move to get through WaitForQuietPeriod and be hashed
+        /// </summary>
+        private int GetDeletionGracePeriodMs()
+        {
+            return Math.Max(DELETION_GRACE_PERIOD_MS, 2 * Math.Max(0, Config.DebounceMs) + MOVE_MATCH_WINDOW_MS);
+        }
+
+        /// <summary>
+        /// Pair a new file with a deletion still in its grace period (same size and hash, old path gone) -
+        /// a move within the watch tree. The existing Part is moved instead of deleting it and uploading a
+        /// new one, so its settings are kept. Returns false if there is nothing to pair with.
+        /// </summary>
+        private async Task<bool> TryCompleteMove(string filePath, long fileSize, string fileHash, string partName, string folderPath)
+        {
+            if (string.IsNullOrEmpty(fileHash))
+                return false;
+
+            var oldPath = pendingDeletions.FirstOrDefault(kvp =>
+                kvp.Key != filePath &&
+                kvp.Value.oldHash == fileHash &&
+                (kvp.Value.oldSize < 0 || kvp.Value.oldSize == fileSize) &&
+                !File.Exists(kvp.Key)).Key;
+
+            // TryRemove claims it, so the grace-period task can't also confirm the deletion
+            if (oldPath == null || !pendingDeletions.TryRemove(oldPath, out var moved))
+                return false;
+
+            var part = moved.part;
+            var oldName = part.Name;
+            Log($"Detected move: {GetRelativeUploadPath(oldPath)} → {GetRelativeUploadPath(filePath)}", "INFO");
+
+            trackingDb?.Delete(oldPath);
+            trackingDb?.Upsert(new FileTrackingEntry
+            {
+                FilePath = filePath,
+                FileHash = fileHash,
+                PartId = part.Id,
+                PartName = partName,
+                FolderPath = folderPath,
+                LastSeenAt = DateTime.UtcNow,
+                CreatedAt = DateTime.UtcNow
+            });
+
+            // Re-key the cached Part under its new path
+            var oldRelativePath = GetRelativeUploadPath(oldPath);
+            var oldFolderPath = Config.GetCloudFolder(oldRelativePath);
+            var oldKey = string.IsNullOrEmpty(oldFolderPath) ? Path.GetFileName(oldPath) : $"{oldFolderPath}/{Path.GetFileName(oldPath)}";
+            var newKey = string.IsNullOrEmpty(folderPath) ? Path.GetFileName(filePath) : $"{folderPath}/{Path.GetFileName(filePath)}";
+            remoteParts.TryRemove(oldKey, out _);
+            part.FolderPath = folderPath;
+            remoteParts[newKey] = new List<PartCache> { part };
+
+            if (oldName != partName)
+            {
+                // Same as a rename in place: the stored file takes the new name too
+                await ReuploadRenamedFile(filePath, part.Id, partName, folderPath);
+            }
+            else if (oldFolderPath != folderPath)
+            {
+                await UpdatePartFolder(part.Id, folderPath);
+            }
+
+            return true;
+        }
+
+        /// <summary>
+        /// Wait until no new events have arrived for the path for Config.DebounceMs.
+        /// Returns false if a newer event superseded this one, so a burst of writes
+        /// from a single save results in one upload.
+        /// </summary>
+        private async Task<bool> WaitForQuietPeriod(string filePath)
+        {
+            if (Config.DebounceMs <= 0)
+                return true;
+
+            var timerCts = new CancellationTokenSource();
+            debounceTimers.AddOrUpdate(filePath, timerCts, (_, previous) =>
+            {
+                previous.Cancel();
+                return timerCts;
+            });
+
+            try
+            {
+                await Task.Delay(Config.DebounceMs, timerCts.Token);
+            }
+            catch (TaskCanceledException)
+            {
+                return false;
+            }
+
+            debounceTimers.TryRemove(new KeyValuePair<string, CancellationTokenSource>(filePath, timerCts));
+            return true;
+        }
+
+        /// <summary>
+        /// A folder created (or moved/copied) into the watch tree only raises a single event for the
+        /// folder itself, so walk it and feed any files it already contains through the normal path.
+        /// </summary>
+        private void OnDirectoryCreated(string dirPath)
+        {
+            if (IsExcluded(dirPath))
+                return;
+
+            Log($"Detected new folder: {Path.GetFileName(dirPath)}", "INFO");
+
+            _ = Task.Run(async () =>
+            {
+                await Task.Delay(DELETION_GRACE_PERIOD_MS); // Let a copy/move settle before walking
+
+                try
+                {
+                    foreach (var file in Directory.EnumerateFiles(dirPath, "*", SearchOption.AllDirectories))
+                    {
+                        if (IsSupportedFile(file))
+                        {
+                            OnFileChanged(this, new FileSystemEventArgs(WatcherChangeTypes.Created, Path.GetDirectoryName(file) ?? "", Path.GetFileName(file)));
+                        }
+                    }
+                }
+                catch (Exception ex)
+                {
+                    Log($"Error scanning new folder {dirPath}: {ex.Message}", "WARN");
+                }
+            });
+        }
+
         private void OnFileDeleted(object sender, FileSystemEventArgs e)
         {
             if (IsSupportedFile(e.FullPath))
             {
-                var relativePath = Path.GetRelativePath(Config.WatchPath, e.FullPath);
-                var folderPath = Path.GetDirectoryName(relativePath)?.Replace("\\", "/") ?? "";
+                CancelPendingUpload(e.FullPath);
+
+                var relativePath = GetRelativeUploadPath(e.FullPath);
+                var folderPath = Config.GetCloudFolder(relativePath);
                 // Use full filename WITH extension for cache key lookup
                 var fileName = e.Name ?? Path.GetFileName(e.FullPath);
 
@@ -1126,18 +2461,25 @@ namespace PrintagoFolderWatch.Core
 
                 localFiles.TryRemove(key, out _);
 
+                // Cleared out by PostUploadAction - the Part stays as it is
+                if (postUploadRemovals.TryRemove(e.FullPath, out _))
+                {
+                    trackingDb?.Delete(e.FullPath);
+                    return;
+                }
+
                 var tracked = trackingDb?.GetByPath(e.FullPath);
                 var oldHash = tracked?.FileHash ?? "";
 
                 if (remoteParts.TryGetValue(key, out var remotePartList) && remotePartList.Any())
                 {
                     var remotePart = remotePartList.First();
-                    pendingDeletions[e.FullPath] = (remotePart, DateTime.UtcNow, oldHash);
+                    pendingDeletions[e.FullPath] = (remotePart, DateTime.UtcNow, oldHash, tracked?.FileSize ?? -1);
                     Log($"Detected deletion: {e.Name} (grace period)", "INFO");
 
                     _ = Task.Run(async () =>
                     {
-                        await Task.Delay(DELETION_GRACE_PERIOD_MS);
+                        await Task.Delay(GetDeletionGracePeriodMs());
 
                         if (File.Exists(e.FullPath))
                         {
@@ -1150,13 +2492,13 @@ namespace PrintagoFolderWatch.Core
                                     var newHash = await ComputeFileHash(e.FullPath);
                                     if (newHash != pendingInfo.oldHash)
                                     {
-                                        uploadQueue.Enqueue(e.FullPath);
+                                        EnqueueUpload(e.FullPath);
                                     }
                                 }
                                 catch (Exception ex)
                                 {
                                     Log($"Error checking hash: {ex.Message}", "WARN");
-                                    uploadQueue.Enqueue(e.FullPath);
+                                    EnqueueUpload(e.FullPath);
                                 }
                             }
                         }
@@ -1164,9 +2506,23 @@ namespace PrintagoFolderWatch.Core
                         {
                             if (pendingDeletions.TryRemove(e.FullPath, out var pendingInfo))
                             {
-                                Log($"Confirmed deletion: {e.Name}", "INFO");
-                                trackingDb?.Delete(e.FullPath);
-                                deleteQueue.Enqueue(pendingInfo.part);
+                                if (!Config.SyncDeletes)
+                                {
+                                    trackingDb?.Delete(e.FullPath);
+                                    Log($"Deleted locally: {e.Name} - Part kept in Printago (SyncDeletes is off)", "INFO");
+                                }
+                                else if (tracked?.PartId != pendingInfo.part.Id)
+                                {
+                                    // Only Parts this app uploaded for the file are deleted
+                                    trackingDb?.Delete(e.FullPath);
+                                    Log($"Deleted locally: {e.Name} - Part kept in Printago (not uploaded from this computer)", "INFO");
+                                }
+                                else
+                                {
+                                    // Still tracked until DeletePart succeeds, so a failed or dry-run delete is found again
+                                    Log($"Confirmed deletion: {e.Name}", "INFO");
+                                    deleteQueue.Enqueue(pendingInfo.part);
+                                }
                             }
                         }
                     });
@@ -1176,6 +2532,18 @@ namespace PrintagoFolderWatch.Core
                     trackingDb?.Delete(e.FullPath);
                 }
             }
+            else
+            {
+                // Deleting a folder only raises an event for the folder itself, not its contents.
+                // Parts are checked too - localFiles is empty when the initial scan was skipped.
+                var relativeFolder = GetRelativeUploadPath(e.FullPath);
+                if (localFiles.Keys.Any(k => k.StartsWith($"{relativeFolder}/")) ||
+                    remoteParts.Keys.Any(k => k.StartsWith($"{relativeFolder}/")))
+                {
+                    Log($"Detected folder deletion: {e.Name}", "INFO");
+                    ScheduleResync();
+                }
+            }
         }
 
         private void OnFileRenamed(object sender, RenamedEventArgs e)
@@ -1196,18 +2564,19 @@ namespace PrintagoFolderWatch.Core
                 }
 
                 Log($"Detected rename: {e.OldName} → {e.Name}", "INFO");
+                CancelPendingUpload(e.OldFullPath);
 
                 // Get old key info - use full filename WITH extension for cache key
-                var oldRelativePath = Path.GetRelativePath(Config.WatchPath, e.OldFullPath);
-                var oldFolderPath = Path.GetDirectoryName(oldRelativePath)?.Replace("\\", "/") ?? "";
+                var oldRelativePath = GetRelativeUploadPath(e.OldFullPath);
+                var oldFolderPath = Config.GetCloudFolder(oldRelativePath);
                 var oldFileName = e.OldName ?? Path.GetFileName(e.OldFullPath);
                 var oldKey = string.IsNullOrEmpty(oldFolderPath)
                     ? oldFileName
                     : $"{oldFolderPath}/{oldFileName}";
 
                 // Get new key info - use full filename WITH extension for cache key
-                var newRelativePath = Path.GetRelativePath(Config.WatchPath, e.FullPath);
-                var newFolderPath = Path.GetDirectoryName(newRelativePath)?.Replace("\\", "/") ?? "";
+                var newRelativePath = GetRelativeUploadPath(e.FullPath);
+                var newFolderPath = Config.GetCloudFolder(newRelativePath);
                 var newFileName = e.Name ?? Path.GetFileName(e.FullPath);
                 // Part name for Printago API should NOT have extension
                 var newPartName = Path.GetFileNameWithoutExtension(newFileName);
@@ -1249,9 +2618,8 @@ namespace PrintagoFolderWatch.Core
                     else
                     {
                         // File not tracked and not in remote - treat as new file
-                        if (filesInUploadQueue.TryAdd(e.FullPath, true))
+                        if (EnqueueUpload(e.FullPath))
                         {
-                            uploadQueue.Enqueue(e.FullPath);
                             Log($"Queueing renamed file as new: {e.Name}", "INFO");
                         }
                     }
@@ -1260,53 +2628,137 @@ namespace PrintagoFolderWatch.Core
             else if (Directory.Exists(e.FullPath))
             {
                 Log($"Detected folder rename: {e.OldName} → {e.Name}", "INFO");
-
-                _ = Task.Run(async () =>
-                {
-                    await Task.Delay(1000);
-                    await TriggerSyncNow();
-                });
+                ScheduleResync();
+            }
+            else if (IsSupportedFile(e.OldFullPath))
+            {
+                // Renamed to something we don't sync (e.g. model.3mf → model.3mf.bak) - same as removing it
+                OnFileDeleted(sender, new FileSystemEventArgs(WatcherChangeTypes.Deleted, Path.GetDirectoryName(e.OldFullPath) ?? "", Path.GetFileName(e.OldFullPath)));
             }
         }
 
         /// <summary>
-        /// Re-upload a renamed file to Printago, keeping the same Part ID.
-        /// This updates the Part name, folder, AND replaces the file in cloud storage.
+        /// A path was removed or renamed away: stop its debounce timer so it never reaches the queue.
+        /// Entries already queued are dropped by ProcessUploadQueue once it sees the file is gone.
         /// </summary>
-        private async Task ReuploadRenamedFile(string filePath, string partId, string newPartName, string newFolderPath)
+        private void CancelPendingUpload(string filePath)
         {
-            try
+            if (debounceTimers.TryRemove(filePath, out var timerCts))
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
+                timerCts.Cancel();
+            }
+        }
 
-                // Build the cloud path for the new file
-                var relativePath = Path.GetRelativePath(Config.WatchPath, filePath);
-                var cloudPath = relativePath.Replace("\\", "/");
+        private void OnWatcherError(object sender, ErrorEventArgs e)
+        {
+            var ex = e.GetException();
 
-                // Get signed upload URL
-                var signedUrlResponse = await GetSignedUploadUrl(apiUrl, cloudPath);
+            // The drive was disconnected - a rescan now would find nothing. MonitorWatchFolders takes it from here.
+            if (sender is FileSystemWatcher failed && !Directory.Exists(failed.Path))
+            {
+                Log($"File watcher error: {ex.Message} ({failed.Path} is no longer available)", "WARN");
+                return;
+            }
 
-                if (signedUrlResponse == null)
-                {
-                    Log($"Failed to get signed URL for renamed file: {cloudPath}", "ERROR");
-                    return;
-                }
+            if (ex is InternalBufferOverflowException)
+            {
+                Log("File watcher buffer overflowed - some changes were missed, rescanning", "WARN");
+                ScheduleResync();
+                return;
+            }
 
-                // Read and upload the file to cloud storage
-                var fileBytes = await File.ReadAllBytesAsync(filePath);
-                var uploadRequest = new HttpRequestMessage(HttpMethod.Put, signedUrlResponse.Value.uploadUrl)
-                {
-                    Content = new ByteArrayContent(fileBytes)
-                };
+            // Anything else usually leaves the watcher dead (e.g. a share that dropped and came straight back).
+            // Hand the folder to MonitorWatchFolders like a missing one: it's watched again on its next check,
+            // with a rescan then.
+            Log($"File watcher error: {ex.Message} - watching again shortly", "ERROR");
+            if (sender is not FileSystemWatcher dead)
+                return;
 
-                var uploadResponse = await httpClient.SendAsync(uploadRequest);
-                if (!uploadResponse.IsSuccessStatusCode)
-                {
-                    Log($"Failed to upload renamed file to storage: {cloudPath}", "ERROR");
+            lock (lifecycleLock)
+            {
+                var watch = Config.GetWatches().FirstOrDefault(w => w.Path == dead.Path);
+                if (!isRunning || !watchers.Remove(dead) || watch == null)
                     return;
-                }
+                unavailableWatches.Add(watch);
+            }
 
-                // Update the Part with new name, folder, and file reference
+            // Not from inside its own callback
+            _ = Task.Run(dead.Dispose);
+        }
+
+        /// <summary>
+        /// Run a full sync shortly after a folder-level change. Bursts of folder events
+        /// (e.g. deleting a tree with hundreds of subfolders) collapse into a single sync.
+        /// </summary>
+        private void ScheduleResync()
+        {
+            if (Interlocked.Exchange(ref resyncScheduled, 1) == 1)
+                return;
+
+            _ = Task.Run(async () =>
+            {
+                await Task.Delay(RESYNC_DELAY_MS);
+                Interlocked.Exchange(ref resyncScheduled, 0);
+
+                try
+                {
+                    await RunFullSync();
+                }
+                catch (OperationCanceledException)
+                {
+                    // Watching stopped - the next start scans anyway
+                }
+                catch (Exception ex)
+                {
+                    Log($"Resync error: {ex.Message}", "ERROR");
+                }
+            });
+        }
+
+        /// <summary>
+        /// Re-upload a renamed file to Printago, keeping the same Part ID.
+        /// This updates the Part name, folder, AND replaces the file in cloud storage.
+        /// </summary>
+        private async Task ReuploadRenamedFile(string filePath, string partId, string newPartName, string newFolderPath)
+        {
+            if (Config.IsDryRun())
+            {
+                Log($"DRY RUN: would re-upload Part {partId} as '{newPartName}' in '{newFolderPath}'", "INFO");
+                return;
+            }
+
+            var skipReason = GetUploadSkipReason(filePath);
+            if (skipReason != null)
+            {
+                Log($"Not re-uploading renamed {Path.GetFileName(filePath)} ({skipReason})", "INFO");
+                return;
+            }
+
+            try
+            {
+                // Build the cloud path for the new file
+                var cloudPath = GetCloudPath(filePath);
+
+                // Get signed upload URL
+                var signedUrlResponse = await partUploader.GetSignedUploadUrlAsync(cloudPath);
+
+                if (signedUrlResponse == null)
+                {
+                    Log($"Failed to get signed URL for renamed file: {cloudPath}", "ERROR");
+                    return;
+                }
+
+                // Upload the file to cloud storage
+                var upload = await partUploader.PutFileToSignedUrlAsync(cloudPath, signedUrlResponse.Value, filePath, null, cts?.Token ?? CancellationToken.None);
+                signedUrlResponse = upload.signedUrl;
+                using var uploadResponse = upload.response;
+                if (!uploadResponse.IsSuccessStatusCode)
+                {
+                    Log($"Failed to upload renamed file to storage: {cloudPath}", "ERROR");
+                    return;
+                }
+
+                // Update the Part with new name, folder, and file reference
                 var newFolderId = await GetOrCreateFolder(newFolderPath);
 
                 var updateBody = new
@@ -1316,14 +2768,13 @@ namespace PrintagoFolderWatch.Core
                     fileUris = new[] { signedUrlResponse.Value.storagePath }
                 };
 
-                var request = new HttpRequestMessage(HttpMethod.Patch, $"{apiUrl}/v1/parts/{partId}")
+                var request = new HttpRequestMessage(HttpMethod.Patch, apiClient.GetUrl($"parts/{partId}"))
                 {
                     Content = new StringContent(JsonConvert.SerializeObject(updateBody), Encoding.UTF8, "application/json")
                 };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
+                apiClient.AddApiHeaders(request);
 
-                var response = await SendApiRequestAsync(request);
+                var response = await apiClient.SendAsync(request);
 
                 if (response.IsSuccessStatusCode)
                 {
@@ -1352,8 +2803,7 @@ namespace PrintagoFolderWatch.Core
                 }
                 else
                 {
-                    var errorBody = await response.Content.ReadAsStringAsync();
-                    Log($"Failed to update renamed Part {partId}: HTTP {response.StatusCode}", "ERROR");
+                    Log($"Failed to update renamed Part {partId}: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                 }
             }
             catch (Exception ex)
@@ -1366,13 +2816,51 @@ namespace PrintagoFolderWatch.Core
 
         #region Delete Processing
 
+        /// <summary>
+        /// Re-queue a failed delete after the same backoff as uploads, without holding up the rest of the delete queue
+        /// </summary>
+        private void RetryDelete(PartCache part, CancellationToken ct)
+        {
+            var attempt = deleteAttempts.AddOrUpdate(part.Id, 1, (_, n) => n + 1);
+            if (attempt > Config.MaxRetries)
+            {
+                deleteAttempts.TryRemove(part.Id, out _);
+                Log($"Giving up on remote delete of {part.Name} after {attempt} attempts", "ERROR");
+                Notify("Delete failed", $"{part.Name} could not be deleted from Printago", Config.NOTIFY_ERRORS);
+                return;
+            }
+
+            var delay = PartUploader.GetRetryDelay(attempt - 1);
+            Log($"Retrying remote delete of {part.Name} in {delay.TotalSeconds:0.#}s (attempt {attempt}/{Config.MaxRetries})", "WARN");
+            _ = Task.Run(async () =>
+            {
+                await Task.Delay(delay, ct);
+                deleteQueue.Enqueue(part);
+            });
+        }
+
         private async Task ProcessDeleteQueue(CancellationToken ct)
         {
             while (!ct.IsCancellationRequested)
             {
-                if (deleteQueue.TryDequeue(out var part))
+                // Everything confirmed since the last pass is handled together (deleting a folder of files
+                // queues them all within one grace period); the API rate limiter still spaces the requests
+                if (deleteQueue.Count > 1)
+                {
+                    Log($"Deleting {deleteQueue.Count} Part(s)", "INFO");
+                }
+
+                while (!ct.IsCancellationRequested && deleteQueue.TryDequeue(out var part))
                 {
-                    await DeletePart(part);
+                    var result = await DeletePart(part);
+                    if (result == UploadResult.TransientFailure)
+                    {
+                        RetryDelete(part, ct);
+                    }
+                    else
+                    {
+                        deleteAttempts.TryRemove(part.Id, out _);
+                    }
                 }
 
                 await Task.Delay(2000, ct);
@@ -1383,52 +2871,636 @@ namespace PrintagoFolderWatch.Core
 
         #region Upload Processing
 
+        /// <summary>
+        /// Queue a file unless it's missing or already queued or uploading. Returns false if not queued. The entry is cleared
+        /// when its upload finishes, whether it succeeded or failed, so a later edit queues the file again.
+        /// fromScan puts it in the lane behind live changes.
+        /// </summary>
+        internal bool EnqueueUpload(string filePath, bool fromScan = false)
+        {
+            // Only files - folders and paths that have already gone again are never queued
+            if (!File.Exists(filePath))
+                return false;
+
+            if (!filesInUploadQueue.TryAdd(filePath, fromScan))
+            {
+                // Waiting in the scan lane and just changed: move it up. Its old entry is skipped when reached.
+                if (!fromScan && filesInUploadQueue.TryUpdate(filePath, false, true))
+                {
+                    uploadQueue.Enqueue(filePath);
+                    return true;
+                }
+
+                // Already queued: nothing to do. Already uploading: the upload may have read the old bytes.
+                if (uploadingPaths.ContainsKey(filePath) && changedWhileUploading.TryAdd(filePath, true))
+                {
+                    Log($"Changed while uploading: {Path.GetFileName(filePath)} - will upload again when done", "DEBUG");
+                }
+                return false;
+            }
+
+            try
+            {
+                queuedUploadBytes[filePath] = new FileInfo(filePath).Length;
+            }
+            catch (IOException)
+            {
+                // Gone again - dropped when it's taken off the queue
+            }
+
+            (fromScan ? scanUploadQueue : uploadQueue).Enqueue(filePath);
+            return true;
+        }
+
+        /// <summary>
+        /// Next file to upload: live changes first, then the scan backlog
+        /// </summary>
+        internal bool TryDequeueUpload(out string filePath)
+        {
+            if (uploadQueue.TryDequeue(out filePath!))
+                return true;
+
+            while (scanUploadQueue.TryDequeue(out filePath!))
+            {
+                // Claimed here so a change from now on is handled as changed-while-uploading. Entries that were
+                // moved up to the live lane (or already uploaded from there) since they were queued are skipped.
+                if (filesInUploadQueue.TryUpdate(filePath, false, true))
+                    return true;
+            }
+
+            return false;
+        }
+
         private async Task ProcessUploadQueue(CancellationToken ct)
         {
             while (!ct.IsCancellationRequested)
             {
-                if (uploadQueue.TryDequeue(out var filePath))
+                // Fill every free worker slot. Files waiting on a retry backoff still hold their slot.
+                // Nothing new starts while the API key is being rejected - every upload would fail the same way -
+                // or while uploads are paused from the tray.
+                while (uploadsPausedReason == null && !uploadsPausedByUser && !IsOffline && !IsRateLimited && Volatile.Read(ref inFlightUploads) < maxParallelUploads && TryDequeueUpload(out var filePath))
+                {
+                    // Deleted or renamed away while waiting in the queue
+                    if (!File.Exists(filePath))
+                    {
+                        filesInUploadQueue.TryRemove(filePath, out _);
+                        queuedUploadBytes.TryRemove(filePath, out _);
+                        partUploader.ForgetPrefetchedSignedUrl(filePath);
+                        Log($"Dropped from queue: {Path.GetFileName(filePath)} (no longer exists)", "DEBUG");
+                        continue;
+                    }
+
+                    if (!uploadingPaths.TryAdd(filePath, true))
+                    {
+                        // Queued again just as the previous upload was finishing - run it after that one
+                        filesInUploadQueue.TryRemove(filePath, out _);
+                        queuedUploadBytes.TryRemove(filePath, out _);
+                        changedWhileUploading[filePath] = true;
+                        Log($"Already uploading: {Path.GetFileName(filePath)} - will upload again when done", "DEBUG");
+                        continue;
+                    }
+
+                    Interlocked.Increment(ref inFlightUploads);
+                    // No token on Task.Run - the body must always run so the counters are released
+                    _ = Task.Run(async () =>
+                    {
+                        try
+                        {
+                            await ProcessSingleUpload(filePath, ct);
+                        }
+                        finally
+                        {
+                            uploadingPaths.TryRemove(filePath, out _);
+                            // Before the slot is released, so ShutdownAsync doesn't see an empty queue in between.
+                            // An unchanged file is skipped by the hash check, so this costs at most one hash.
+                            if (changedWhileUploading.TryRemove(filePath, out _) && !ct.IsCancellationRequested)
+                            {
+                                EnqueueUpload(filePath);
+                            }
+                            Interlocked.Decrement(ref inFlightUploads);
+                        }
+                    });
+                }
+
+                // One notification per burst rather than one per file
+                if (UploadQueueCount == 0 && Volatile.Read(ref inFlightUploads) == 0)
                 {
-                    _ = Task.Run(() => ProcessSingleUpload(filePath, ct), ct);
+                    var placeholders = Interlocked.Exchange(ref placeholdersSkipped, 0);
+                    if (placeholders > 0 && Config.GetPlaceholderAction() == "notify")
+                    {
+                        Notify("Cloud-only files skipped", $"{placeholders} file(s) aren't downloaded to this computer - choose \"Always keep on this device\" to upload them", Config.NOTIFY_ERRORS);
+                    }
+
+                    var uploaded = Interlocked.Exchange(ref uploadsSinceNotification, 0);
+                    if (uploaded > 0)
+                    {
+                        Notify("Upload complete", uploaded == 1 ? $"Uploaded {lastUploadedName}" : $"Uploaded {uploaded} files", Config.NOTIFY_UPLOADS);
+                    }
                 }
 
-                await Task.Delay(2000, ct);
+                await Task.Delay(500, ct);
             }
         }
 
-        private async Task ProcessSingleUpload(string filePath, CancellationToken ct)
+        private async Task<UploadResult> ProcessSingleUpload(string filePath, CancellationToken ct)
         {
-            await uploadSemaphore.WaitAsync(ct);
+            bool interrupted = false;
             try
             {
-                await Task.Delay(2000, ct);
+                // Slicers write incrementally - don't upload until the file has stopped changing
+                if (!await WaitForFileStable(filePath, ct))
+                {
+                    if (File.Exists(filePath))
+                    {
+                        Log($"Skipped: {Path.GetFileName(filePath)} still changing after {Config.MaxStableWaitSeconds}s - will retry on its next change", "WARN");
+                    }
+                    return UploadResult.TransientFailure;
+                }
 
-                if (File.Exists(filePath))
+                // Re-check the filter - the path may have been queued before IncludeExtensions changed
+                if (!IsSupportedFile(filePath))
                 {
-                    await UploadFile(filePath);
+                    Log($"Skipped: {Path.GetFileName(filePath)} (extension not included)", "DEBUG");
+                    return UploadResult.Skipped;
                 }
+
+                string? failureReason = null;
+                int? failureStatus = null;
+                var (result, attempts) = await partUploader.RunWithRetriesAsync(Path.GetFileName(filePath), async attemptCt =>
+                {
+                    var attemptResult = File.Exists(filePath) ? await UploadFile(filePath, attemptCt) : UploadResult.Skipped;
+                    uploadFailureReasons.TryRemove(filePath, out failureReason);
+                    failureStatus = uploadFailureStatuses.TryRemove(filePath, out var status) ? status : null;
+                    return attemptResult;
+                }, WaitOutUploadFailure, ct);
+
+                if (result == UploadResult.PermanentFailure)
+                {
+                    AddFailedUpload(filePath, $"Rejected: {failureReason ?? "not retryable"}", failureStatus, attempts);
+                    AddRecentUpload(filePath, false, failureReason ?? "rejected");
+                    Notify("Upload failed", $"{Path.GetFileName(filePath)}: {failureReason ?? "rejected"} - retry it from Failed Uploads", Config.NOTIFY_ERRORS);
+                    return result;
+                }
+
+                if (result == UploadResult.TransientFailure)
+                {
+                    Log($"Giving up on {Path.GetFileName(filePath)} after {attempts} attempts", "ERROR");
+                    AddFailedUpload(filePath, $"Gave up after {attempts} attempts: {failureReason ?? "see the logs"}", failureStatus, attempts);
+                    AddRecentUpload(filePath, false, failureReason ?? $"gave up after {attempts} attempts");
+                    Notify("Upload failed", $"{Path.GetFileName(filePath)} failed after {attempts} attempts ({failureReason ?? "see the logs"}) - retry it from Failed Uploads", Config.NOTIFY_ERRORS);
+                    return result;
+                }
+
+                // A one-shot upload with a rejected key fails without waiting - there's nothing that could fix it
+                if (result == UploadResult.AuthFailure)
+                    return result;
+
+                if (trackingDb?.RemoveFailedUpload(filePath) == true)
+                    WriteFailedUploadsFile();
+                if (result == UploadResult.Success)
+                {
+                    lastUploadedName = Path.GetFileName(filePath);
+                    Interlocked.Increment(ref uploadsSinceNotification);
+                    Notify("Uploaded", lastUploadedName, Config.NOTIFY_FILES);
+                    AddRecentUpload(filePath, true, null);
+                    ApplyPostUploadAction(filePath);
+                }
+                return result;
+            }
+            catch (OperationCanceledException) when (ct.IsCancellationRequested)
+            {
+                // Stopped or exiting mid-upload (or mid-backoff): pending, not failed - queued again on the next start
+                interrupted = true;
+                trackingDb?.AddPendingUpload(filePath);
+                throw;
             }
             finally
             {
-                uploadSemaphore.Release();
+                if (!interrupted)
+                    trackingDb?.RemovePendingUpload(filePath);
+
+                forceUploadPaths.TryRemove(filePath, out _);
                 filesInUploadQueue.TryRemove(filePath, out _);
+                queuedUploadBytes.TryRemove(filePath, out _);
+                partUploader.ForgetPrefetchedSignedUrl(filePath);
+            }
+        }
+
+        /// <summary>
+        /// Wait while an upload can't succeed for reasons that aren't the file's: offline (until the API host answers)
+        /// or a rejected key (until Test Connection or a config reload succeeds). False when there's nothing to wait
+        /// for - the watcher isn't running (one-shot upload), or the API host answers so the failure was the file's.
+        /// </summary>
+        private async Task<bool> WaitOutUploadFailure(UploadResult result, CancellationToken ct)
+        {
+            if (!isRunning)
+                return false;
+
+            if (result == UploadResult.NetworkFailure)
+            {
+                if (await CanReachApi(ct))
+                    return false;
+
+                GoOffline(ct);
+                while (IsOffline)
+                {
+                    await Task.Delay(1000, ct);
+                }
+                return true;
+            }
+
+            while (uploadsPausedReason != null)
+            {
+                await Task.Delay(1000, ct);
+            }
+            return true;
+        }
+
+        /// <summary>
+        /// Put a file on the failed-uploads list (tracking database and failed.json). It comes off again
+        /// when a later upload of it succeeds.
+        /// </summary>
+        private void AddFailedUpload(string filePath, string reason, int? httpStatus, int attempts)
+        {
+            trackingDb?.AddFailedUpload(filePath, GetCloudPath(filePath), reason, httpStatus, attempts);
+            WriteFailedUploadsFile();
+        }
+
+        /// <summary>
+        /// Rewrite failed.json next to the config file, so the list can be checked without the tray.
+        /// Deleted when the list is empty.
+        /// </summary>
+        private void WriteFailedUploadsFile()
+        {
+            try
+            {
+                var failed = GetFailedUploads();
+                lock (failedUploadsFileLock)
+                {
+                    if (failed.Count == 0)
+                    {
+                        File.Delete(Config.FailedUploadsFile);
+                        return;
+                    }
+
+                    var tempFile = Config.FailedUploadsFile + ".tmp";
+                    File.WriteAllText(tempFile, JsonConvert.SerializeObject(failed, Formatting.Indented));
+                    File.Move(tempFile, Config.FailedUploadsFile, overwrite: true);
+                }
+            }
+            catch (Exception ex)
+            {
+                Log($"Could not write {Path.GetFileName(Config.FailedUploadsFile)}: {ex.Message}", "WARN");
+            }
+        }
+
+        private void AddRecentUpload(string filePath, bool success, string? error)
+        {
+            var entry = new RecentUpload
+            {
+                FilePath = filePath,
+                FileName = Path.GetFileName(filePath),
+                CloudPath = GetCloudPath(filePath),
+                Success = success,
+                Error = error
+            };
+
+            lock (recentUploadsLock)
+            {
+                recentUploads.Enqueue(entry);
+                while (recentUploads.Count > RECENT_UPLOADS_MAX)
+                    recentUploads.Dequeue();
+            }
+        }
+
+        // Windows attributes of cloud files (OneDrive Files On-Demand and similar) whose content isn't downloaded
+        private const FileAttributes FILE_ATTRIBUTE_RECALL_ON_OPEN = (FileAttributes)0x00040000;
+        private const FileAttributes FILE_ATTRIBUTE_RECALL_ON_DATA_ACCESS = (FileAttributes)0x00400000;
+
+        /// <summary>
+        /// Why a file shouldn't be uploaded as it is - empty, or a cloud-only placeholder - or null if it's fine.
+        /// Placeholders aren't read at all (that would download them) unless Config.PlaceholderAction is "hydrate";
+        /// a skipped one is counted for the "notify" summary.
+        /// </summary>
+        private string? GetUploadSkipReason(string filePath)
+        {
+            try
+            {
+                if (SkipsPlaceholder(filePath))
+                {
+                    Interlocked.Increment(ref placeholdersSkipped);
+                    return "cloud-only placeholder - make it available offline to upload it, or set PlaceholderAction to hydrate";
+                }
+
+                if (new FileInfo(filePath).Length == 0)
+                    return "empty file";
+            }
+            catch (Exception)
+            {
+                // Gone or unreadable - the upload itself reports that
+            }
+
+            return null;
+        }
+
+        private bool SkipsPlaceholder(string filePath)
+        {
+            return Config.GetPlaceholderAction() != "hydrate" && IsCloudPlaceholder(filePath);
+        }
+
+        private static bool IsCloudPlaceholder(string filePath)
+        {
+            if (!OperatingSystem.IsWindows())
+                return false;
+
+            try
+            {
+                var attributes = File.GetAttributes(filePath);
+                return (attributes & (FileAttributes.Offline | FILE_ATTRIBUTE_RECALL_ON_OPEN | FILE_ATTRIBUTE_RECALL_ON_DATA_ACCESS)) != 0;
+            }
+            catch (Exception)
+            {
+                return false;
+            }
+        }
+
+        /// <summary>
+        /// Poll size and modified time until they haven't changed for Config.DebounceMs.
+        /// Returns false if the file disappears or is still changing after Config.MaxStableWaitSeconds.
+        /// </summary>
+        private async Task<bool> WaitForFileStable(string filePath, CancellationToken ct)
+        {
+            if (Config.DebounceMs <= 0)
+                return true;
+
+            var deadline = DateTime.UtcNow.AddSeconds(Config.MaxStableWaitSeconds);
+            var stableSince = DateTime.UtcNow;
+            (long length, DateTime lastWriteUtc)? last = null;
+
+            while (true)
+            {
+                var info = new FileInfo(filePath);
+                if (!info.Exists)
+                    return false;
+
+                var current = (info.Length, info.LastWriteTimeUtc);
+
+                // Not written to for a full window already (e.g. found by the initial scan)
+                if (last == null && (DateTime.UtcNow - current.LastWriteTimeUtc).TotalMilliseconds >= Config.DebounceMs)
+                    return true;
+
+                if (current != last)
+                {
+                    last = current;
+                    stableSince = DateTime.UtcNow;
+                }
+                else if ((DateTime.UtcNow - stableSince).TotalMilliseconds >= Config.DebounceMs)
+                {
+                    return true;
+                }
+
+                if (DateTime.UtcNow >= deadline)
+                    return false;
+
+                await Task.Delay(STABLE_POLL_MS, ct);
             }
         }
 
-        private async Task UploadFile(string filePath)
+        /// <summary>
+        /// The API rejected the key or store: stop starting new uploads (and keep the ones in progress waiting)
+        /// until the settings are fixed, rather than failing every queued file in turn
+        /// </summary>
+        private void PauseForRejectedKey(string reason)
+        {
+            if (uploadsPausedReason != null)
+                return;
+
+            uploadsPausedReason = reason;
+            Log($"Uploads paused - {reason}. Fix the API key or Store ID, then use Reload Config or Test Connection.", "ERROR");
+            Notify("Uploads paused", "Printago rejected the API key or Store ID - check your settings", Config.NOTIFY_ERRORS);
+        }
+
+        /// <summary>
+        /// No connection: stop starting new uploads until the API host answers again, instead of using up every
+        /// queued file's retries. A background check every OFFLINE_CHECK_SECONDS resumes them.
+        /// </summary>
+        private void GoOffline(CancellationToken ct)
+        {
+            if (Interlocked.Exchange(ref offline, 1) == 1)
+                return;
+
+            Log($"Can't reach {Config.ApiUrl} - uploads paused until the connection is back", "WARN");
+            Notify("Offline", "Uploads paused until Printago can be reached again", Config.NOTIFY_ERRORS);
+
+            _ = Task.Run(async () =>
+            {
+                try
+                {
+                    while (!await CanReachApi(ct))
+                    {
+                        await Task.Delay(TimeSpan.FromSeconds(OFFLINE_CHECK_SECONDS), ct);
+                    }
+
+                    Volatile.Write(ref offline, 0);
+                    Log($"Connection is back - uploads resumed ({UploadQueueCount} queued)", "SUCCESS");
+                    Notify("Back online", "Uploads resumed", Config.NOTIFY_START_STOP);
+                }
+                catch (OperationCanceledException)
+                {
+                    // Stopped - Start clears the offline state
+                }
+            });
+        }
+
+        private string DescribeRateLimitEnd()
+        {
+            return apiClient.RateLimitedUntilUtc.ToLocalTime().ToString("HH:mm:ss");
+        }
+
+        /// <summary>
+        /// True if the API host answers at all - any HTTP status counts, only no response means offline.
+        /// Goes through the same HttpClient (and proxy) as the uploads.
+        /// </summary>
+        private async Task<bool> CanReachApi(CancellationToken ct)
         {
-            var relativePath = Path.GetRelativePath(Config.WatchPath, filePath);
+            try
+            {
+                using var timeout = CancellationTokenSource.CreateLinkedTokenSource(ct);
+                timeout.CancelAfter(TimeSpan.FromSeconds(CONNECTION_TEST_TIMEOUT_SECONDS));
+                using var request = new HttpRequestMessage(HttpMethod.Head, Config.ApiUrl.TrimEnd('/'));
+                using var response = await httpClient.SendAsync(request, timeout.Token);
+                return true;
+            }
+            catch (OperationCanceledException) when (ct.IsCancellationRequested)
+            {
+                throw;
+            }
+            catch (Exception)
+            {
+                return false;
+            }
+        }
+
+        /// <summary>
+        /// Config.PostUploadAction for a file that was just uploaded. If it fails the file is left where it is;
+        /// its hash matches the Part now, so it isn't uploaded again.
+        /// </summary>
+        private void ApplyPostUploadAction(string filePath)
+        {
+            var watch = Config.FindWatch(filePath);
+            var action = Config.GetPostUploadAction(watch);
+            if (action == "none" || Config.IsDryRun())
+                return;
+
             var fileName = Path.GetFileName(filePath);
-            var folderPath = Path.GetDirectoryName(relativePath)?.Replace("\\", "/") ?? "";
+
+            // Written to again while uploading - keep it so the newer content gets uploaded too
+            var tracked = trackingDb?.GetByPath(filePath);
+            var current = new FileInfo(filePath);
+            if (tracked == null || !current.Exists || tracked.FileSize != current.Length || tracked.LastWriteUtc != current.LastWriteTimeUtc)
+            {
+                Log($"Not clearing {fileName} after upload - it changed while uploading", "DEBUG");
+                return;
+            }
+
+            postUploadRemovals[filePath] = true;
+            try
+            {
+                if (action == "delete")
+                {
+                    File.Delete(filePath);
+                    Log($"Deleted after upload: {fileName}", "INFO");
+                }
+                else
+                {
+                    // Same folder structure under MoveToDir, without overwriting an earlier file of the same name.
+                    // A folder's own MoveToDir mirrors that folder; the shared one keeps each folder's CloudPrefix apart.
+                    var relativePath = watch != null && !string.IsNullOrWhiteSpace(watch.MoveToDir)
+                        ? Path.GetRelativePath(watch.Path, filePath)
+                        : GetRelativeUploadPath(filePath);
+                    var destination = GetUnusedPath(Path.Combine(Config.GetMoveToDir(watch), relativePath));
+                    Directory.CreateDirectory(Path.GetDirectoryName(destination)!);
+                    File.Move(filePath, destination);
+                    Log($"Moved after upload: {fileName} → {destination}", "INFO");
+                }
+            }
+            catch (Exception ex)
+            {
+                postUploadRemovals.TryRemove(filePath, out _);
+                Log($"Could not {action} {fileName} after upload: {ex.Message}", "WARN");
+                Notify("Could not clear uploaded file", $"{fileName}: {ex.Message}", Config.NOTIFY_ERRORS);
+            }
+        }
+
+        private static string GetUnusedPath(string path)
+        {
+            var directory = Path.GetDirectoryName(path) ?? "";
+            var name = Path.GetFileNameWithoutExtension(path);
+            var extension = Path.GetExtension(path);
+
+            var candidate = path;
+            for (int i = 2; File.Exists(candidate); i++)
+            {
+                candidate = Path.Combine(directory, $"{name} ({i}){extension}");
+            }
+            return candidate;
+        }
+
+        /// <summary>
+        /// Path of a file (with forward slashes) relative to the watch folder it's in, under that folder's CloudPrefix -
+        /// this is its Printago folder and storage path. Just the file name if it's outside every watch folder
+        /// (one-shot uploads from a slicer's output folder).
+        /// </summary>
+        private string GetRelativeUploadPath(string filePath)
+        {
+            var watch = Config.FindWatch(filePath);
+            if (watch == null)
+                return Path.GetFileName(filePath);
+
+            var relativePath = Path.GetRelativePath(watch.Path, filePath);
+            var prefix = watch.GetCloudPrefix();
+            if (relativePath == ".")
+                return prefix;
+
+            relativePath = Config.ToCloudPath(relativePath);
+
+            return prefix.Length == 0 ? relativePath : $"{prefix}/{relativePath}";
+        }
+
+        /// <summary>
+        /// Storage path to request a signed URL for: the relative upload path run through Config.CloudPathTemplate
+        /// </summary>
+        private string GetCloudPath(string filePath)
+        {
+            return Config.FormatCloudPath(GetRelativeUploadPath(filePath));
+        }
+
+        /// <summary>
+        /// Upload specific files once, without starting the watcher (the "upload" command).
+        /// Uses the same cache, retry and Part create/update logic as the watcher. Returns success per file.
+        /// </summary>
+        public async Task<Dictionary<string, bool>> UploadFilesOnce(IEnumerable<string> filePaths)
+        {
+            var results = new Dictionary<string, bool>();
+
+            await BuildInitialCache();
+            await EnsureRootSyncFolder();
+
+            foreach (var filePath in filePaths.Select(Path.GetFullPath))
+            {
+                if (!File.Exists(filePath))
+                {
+                    Log($"Not found: {filePath}", "ERROR");
+                    results[filePath] = false;
+                    continue;
+                }
+
+                if (!IsSupportedFile(filePath))
+                {
+                    Log($"Not a supported file type (or excluded): {filePath}", "ERROR");
+                    results[filePath] = false;
+                    continue;
+                }
+
+                var result = await ProcessSingleUpload(filePath, CancellationToken.None);
+                results[filePath] = result == UploadResult.Success || result == UploadResult.Skipped;
+            }
+
+            return results;
+        }
+
+        /// <summary>
+        /// Upload one file and create or update its Part. ct (the watcher's run) aborts a PUT in progress on Stop;
+        /// once the file is in storage, the Part is still saved.
+        /// </summary>
+        private async Task<UploadResult> UploadFile(string filePath, CancellationToken ct)
+        {
+            // Temp files are often gone again by the time a worker gets to them
+            if (!File.Exists(filePath))
+            {
+                Log($"Dropped: {Path.GetFileName(filePath)} (no longer exists)", "DEBUG");
+                return UploadResult.Skipped;
+            }
+
+            // Uploading these would replace the Part's file with nothing
+            var skipReason = GetUploadSkipReason(filePath);
+            if (skipReason != null)
+            {
+                Log($"Skipped: {Path.GetFileName(filePath)} ({skipReason})", "INFO");
+                return UploadResult.Skipped;
+            }
+
+            var relativePath = GetRelativeUploadPath(filePath);
+            var fileName = Path.GetFileName(filePath);
+            var folderPath = Config.GetCloudFolder(relativePath);
             // Part name for Printago API should NOT have extension
             var partName = Path.GetFileNameWithoutExtension(fileName);
-            var fileExt = Path.GetExtension(filePath).ToLower();
 
             var progress = new UploadProgress
             {
                 FilePath = filePath,
                 FileName = fileName,
-                RelativePath = relativePath.Replace("\\", "/"),
+                RelativePath = relativePath,
                 ProgressPercent = 0,
                 Status = "Starting...",
                 StartTime = DateTime.Now,
@@ -1443,12 +3515,18 @@ namespace PrintagoFolderWatch.Core
                 : $"{folderPath}/{fileName}";
 
             var keyLock = uploadKeyLocks.GetOrAdd(key, _ => new SemaphoreSlim(1, 1));
-            await keyLock.WaitAsync();
-
             try
             {
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
+                await keyLock.WaitAsync(ct);
+            }
+            catch (OperationCanceledException)
+            {
+                activeUploads.TryRemove(filePath, out _);
+                throw;
+            }
 
+            try
+            {
                 PartCache? existingPart = null;
                 bool isUpdate = false;
 
@@ -1460,219 +3538,143 @@ namespace PrintagoFolderWatch.Core
 
                     var localHash = await ComputeFileHash(filePath);
 
-                    if (localHash == existingPart.FileHash)
+                    if (localHash == existingPart.FileHash && !forceUploadPaths.ContainsKey(filePath))
                     {
                         progress.Status = "Already up-to-date";
                         progress.ProgressPercent = 100;
                         Log($"Skipped: {key} (up-to-date)", "INFO");
                         await Task.Delay(1000);
                         activeUploads.TryRemove(filePath, out _);
-                        return;
+                        return UploadResult.Skipped;
                     }
 
                     isUpdate = true;
                     Log($"File changed: {key} - updating", "INFO");
                 }
 
-                progress.Status = "Creating folders...";
-                progress.ProgressPercent = 10;
-                string? folderId = await GetOrCreateFolder(folderPath);
-
-                progress.Status = "Reading file...";
-                progress.ProgressPercent = 15;
-                var fileBytes = await File.ReadAllBytesAsync(filePath);
-
-                progress.Status = "Getting signed URL...";
-                progress.ProgressPercent = 20;
-
-                var cloudPath = relativePath.Replace("\\", "/");
-                var signedUrlResponse = await GetSignedUploadUrl(apiUrl, cloudPath);
-
-                if (signedUrlResponse == null)
+                if (Config.IsDryRun())
                 {
-                    progress.Status = "Failed - No signed URL";
-                    Log($"Failed: {key} - No signed URL", "ERROR");
+                    Log($"DRY RUN: would {(isUpdate ? "update" : "upload")} {relativePath} ({progress.FileSizeBytes:N0} bytes, {Config.GetContentType(filePath)})", "INFO");
                     activeUploads.TryRemove(filePath, out _);
-                    return;
+                    return UploadResult.Skipped;
                 }
 
-                progress.Status = "Uploading...";
-                progress.ProgressPercent = 40;
+                progress.Status = "Creating folders...";
+                progress.ProgressPercent = 10;
+                string? folderId = await GetOrCreateFolder(folderPath, ct);
 
-                var uploadRequest = new HttpRequestMessage(HttpMethod.Put, signedUrlResponse.Value.uploadUrl)
+                var upload = new PartUpload
                 {
-                    Content = new ByteArrayContent(fileBytes)
+                    FilePath = filePath,
+                    CloudPath = GetCloudPath(filePath),
+                    Key = key,
+                    PartName = partName,
+                    FolderId = folderId,
+                    ExistingPartId = isUpdate ? existingPart?.Id : null
                 };
-
-                var uploadResponse = await httpClient.SendAsync(uploadRequest);
-                if (!uploadResponse.IsSuccessStatusCode)
+                var (result, partId, httpStatus) = await partUploader.UploadAsync(upload, progress, ct);
+                if (result != UploadResult.Success)
                 {
-                    progress.Status = $"Upload failed: {uploadResponse.StatusCode}";
-                    Log($"Upload failed: {key}", "ERROR");
-                    activeUploads.TryRemove(filePath, out _);
-                    return;
+                    if (httpStatus != null)
+                        uploadFailureStatuses[filePath] = httpStatus.Value;
+                    return result;
                 }
 
-                string? partId = null;
+                // Stat before hashing, so a write during hashing shows up as a changed file next time
+                var fileStat = new FileInfo(filePath);
+                var (fileSize, lastWriteUtc) = (fileStat.Length, fileStat.LastWriteTimeUtc);
+                var fileHash = await ComputeFileHash(filePath);
 
-                if (isUpdate && existingPart != null)
+                string? verifyError = null;
+                if (Config.VerifyUploads)
                 {
-                    progress.Status = "Updating part...";
-                    progress.ProgressPercent = 80;
-
-                    bool updateSuccess = await UpdatePartFile(existingPart.Id, filePath, signedUrlResponse.Value.storagePath);
-
-                    if (updateSuccess)
-                    {
-                        partId = existingPart.Id;
+                    progress.Status = "Verifying...";
+                    progress.ProgressPercent = 90;
+                    verifyError = await partUploader.VerifyPartAsync(partId, fileHash, ct);
+                }
 
-                        var fileHash = await ComputeFileHash(filePath);
-                        remoteParts[key] = new List<PartCache> { new PartCache
-                        {
-                            Id = partId,
-                            Name = partName,
-                            FolderId = folderId,
-                            FolderPath = folderPath,
-                            FileHash = fileHash,
-                            UpdatedAt = DateTime.UtcNow
-                        } };
+                // After a failed check the cached hash is left empty, so the retry uploads again instead of
+                // finding the Part up to date. The tracking entry keeps the Part ID, so the retry updates it.
+                remoteParts[key] = new List<PartCache> { new PartCache
+                {
+                    Id = partId,
+                    Name = partName,
+                    FolderId = folderId,
+                    FolderPath = folderPath,
+                    FileHash = verifyError == null ? fileHash : "",
+                    UpdatedAt = DateTime.UtcNow
+                } };
 
-                        trackingDb?.Upsert(new FileTrackingEntry
-                        {
-                            FilePath = filePath,
-                            FileHash = fileHash,
-                            PartId = partId,
-                            PartName = partName,
-                            FolderPath = folderPath,
-                            LastSeenAt = DateTime.UtcNow,
-                            CreatedAt = DateTime.UtcNow
-                        });
+                trackingDb?.Upsert(new FileTrackingEntry
+                {
+                    FilePath = filePath,
+                    FileHash = fileHash,
+                    PartId = partId,
+                    PartName = partName,
+                    FolderPath = folderPath,
+                    LastSeenAt = DateTime.UtcNow,
+                    CreatedAt = DateTime.UtcNow,
+                    FileSize = fileSize,
+                    LastWriteUtc = lastWriteUtc
+                });
 
-                        progress.Status = "Complete!";
-                        progress.ProgressPercent = 100;
-                        Log($"Updated: {key} (Part ID: {partId})", "SUCCESS");
-                        Interlocked.Increment(ref syncedFilesCount);
-                    }
-                    else
-                    {
-                        progress.Status = "Failed to update part";
-                        Log($"Failed to update part: {key}", "ERROR");
-                    }
+                if (verifyError != null)
+                {
+                    progress.Status = $"Verification failed: {verifyError}";
+                    Log($"VERIFICATION FAILED: {key} - {verifyError} (the upload itself went through; it will be retried)", "ERROR");
+                    result = UploadResult.TransientFailure;
                 }
                 else
                 {
-                    progress.Status = "Creating part...";
-                    progress.ProgressPercent = 80;
-
-                    var partType = fileExt == ".3mf" ? "3mf" : "stl";
-                    var partBody = new
-                    {
-                        name = partName,
-                        type = partType,
-                        description = "Auto-uploaded from folder watch",
-                        fileUris = new[] { signedUrlResponse.Value.storagePath },
-                        parameters = new object[0],
-                        printTags = new { },
-                        overriddenProcessProfileId = (string?)null,
-                        folderId = folderId
-                    };
-
-                    var partRequest = new HttpRequestMessage(HttpMethod.Post, $"{apiUrl}/v1/parts")
-                    {
-                        Content = new StringContent(JsonConvert.SerializeObject(partBody), Encoding.UTF8, "application/json")
-                    };
-                    partRequest.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                    partRequest.Headers.Add("x-printago-storeid", Config.StoreId);
-
-                    var partResponse = await SendApiRequestAsync(partRequest);
-                    if (partResponse.IsSuccessStatusCode)
-                    {
-                        var partResponseJson = await partResponse.Content.ReadAsStringAsync();
-                        var createdPart = JsonConvert.DeserializeAnonymousType(partResponseJson, new { id = "" });
-                        partId = createdPart?.id ?? "";
-
-                        var fileHash = await ComputeFileHash(filePath);
-                        remoteParts[key] = new List<PartCache> { new PartCache
-                        {
-                            Id = partId,
-                            Name = partName,
-                            FolderId = folderId,
-                            FolderPath = folderPath,
-                            FileHash = fileHash,
-                            UpdatedAt = DateTime.UtcNow
-                        } };
-
-                        trackingDb?.Upsert(new FileTrackingEntry
-                        {
-                            FilePath = filePath,
-                            FileHash = fileHash,
-                            PartId = partId,
-                            PartName = partName,
-                            FolderPath = folderPath,
-                            LastSeenAt = DateTime.UtcNow,
-                            CreatedAt = DateTime.UtcNow
-                        });
-
-                        progress.Status = "Complete!";
-                        progress.ProgressPercent = 100;
-                        Log($"Uploaded: {key} (Part ID: {partId})", "SUCCESS");
-                        Interlocked.Increment(ref syncedFilesCount);
-                    }
-                    else
-                    {
-                        progress.Status = $"Failed to create part: {partResponse.StatusCode}";
-                        Log($"Failed to create part: {key}", "ERROR");
-                    }
+                    progress.Status = "Complete!";
+                    progress.ProgressPercent = 100;
+                    Log($"{(isUpdate ? "Updated" : "Uploaded")}: {key} (Part ID: {partId}){(Config.VerifyUploads ? " - verified" : "")}", "SUCCESS");
+                    Interlocked.Increment(ref syncedFilesCount);
+                    Interlocked.Exchange(ref lastUploadSuccessTicks, DateTime.Now.Ticks);
                 }
 
                 await Task.Delay(2000);
+                return result;
+            }
+            catch (Exception ex) when ((ex is FileNotFoundException || ex is DirectoryNotFoundException) && !File.Exists(filePath))
+            {
+                // Deleted while uploading - the delete handler takes care of the Part
+                Log($"Dropped: {fileName} (deleted during upload)", "DEBUG");
+                return UploadResult.Skipped;
+            }
+            catch (OperationCanceledException) when (ct.IsCancellationRequested)
+            {
+                // Stopped or exiting - ProcessSingleUpload records it as pending for the next start
+                progress.Status = "Cancelled";
+                Log($"Cancelled: {fileName} (stopped while uploading)", "INFO");
+                throw;
+            }
+            catch (HttpRequestException ex) when (ex.StatusCode is System.Net.HttpStatusCode.Unauthorized or System.Net.HttpStatusCode.Forbidden)
+            {
+                progress.Status = "Paused - API key rejected";
+                PauseForRejectedKey(ex.Message);
+                return UploadResult.AuthFailure;
             }
             catch (Exception ex)
             {
                 progress.Status = $"Error: {ex.Message}";
+                if (ex is HttpRequestException { StatusCode: not null } httpEx)
+                    uploadFailureStatuses[filePath] = (int)httpEx.StatusCode.Value;
                 Log($"Upload error: {fileName} - {ex.Message}", "ERROR");
+                // No response at all (DNS, no route, connect timeout) - maybe offline rather than a problem with this file
+                if (ex is HttpRequestException { StatusCode: null } or TaskCanceledException)
+                    return UploadResult.NetworkFailure;
+                return PartUploader.ClassifyFailure(ex);
             }
             finally
             {
+                uploadFailureReasons[filePath] = progress.Status;
                 activeUploads.TryRemove(filePath, out _);
                 keyLock.Release();
             }
         }
 
-        private async Task<(string uploadUrl, string storagePath)?> GetSignedUploadUrl(string apiUrl, string cloudPath)
-        {
-            try
-            {
-                var requestBody = new { filenames = new[] { cloudPath } };
-                var request = new HttpRequestMessage(HttpMethod.Post, $"{apiUrl}/v1/storage/signed-upload-urls")
-                {
-                    Content = new StringContent(JsonConvert.SerializeObject(requestBody), Encoding.UTF8, "application/json")
-                };
-                request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                request.Headers.Add("x-printago-storeid", Config.StoreId);
-
-                var response = await SendApiRequestAsync(request);
-                var json = await response.Content.ReadAsStringAsync();
-
-                var result = JsonConvert.DeserializeAnonymousType(json, new
-                {
-                    signedUrls = new[] { new { uploadUrl = "", path = "" } }
-                });
-
-                if (result?.signedUrls != null && result.signedUrls.Length > 0)
-                {
-                    return (result.signedUrls[0].uploadUrl, result.signedUrls[0].path);
-                }
-
-                return null;
-            }
-            catch
-            {
-                return null;
-            }
-        }
-
-        private async Task<string?> GetOrCreateFolder(string folderPath)
+        private async Task<string?> GetOrCreateFolder(string folderPath, CancellationToken ct = default)
         {
             if (string.IsNullOrEmpty(folderPath))
             {
@@ -1691,14 +3693,12 @@ namespace PrintagoFolderWatch.Core
                 return legacyFolder.Id;
             }
 
-            await folderCreationLock.WaitAsync();
+            await folderCreationLock.WaitAsync(ct);
             try
             {
                 if (remoteFolders.TryGetValue(fullPath, out var recheck))
                     return recheck.Id;
 
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
-
                 await EnsureRootSyncFolder();
 
                 string? parentId = rootSyncFolderId;
@@ -1724,14 +3724,13 @@ namespace PrintagoFolderWatch.Core
                             parentId = parentId
                         };
 
-                        var request = new HttpRequestMessage(HttpMethod.Post, $"{apiUrl}/v1/folders")
+                        var request = new HttpRequestMessage(HttpMethod.Post, apiClient.GetUrl("folders"))
                         {
                             Content = new StringContent(JsonConvert.SerializeObject(createBody), Encoding.UTF8, "application/json")
                         };
-                        request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                        request.Headers.Add("x-printago-storeid", Config.StoreId);
+                        apiClient.AddApiHeaders(request);
 
-                        var response = await SendApiRequestAsync(request);
+                        var response = await apiClient.SendAsync(request, ct);
                         var json = await response.Content.ReadAsStringAsync();
                         var created = JsonConvert.DeserializeAnonymousType(json, new { id = "" });
 
@@ -1753,6 +3752,10 @@ namespace PrintagoFolderWatch.Core
                             return null;
                         }
                     }
+                    catch (OperationCanceledException) when (ct.IsCancellationRequested)
+                    {
+                        throw;
+                    }
                     catch (Exception ex)
                     {
                         Log($"Error creating folder {currentPath}: {ex.Message}", "ERROR");
@@ -1779,9 +3782,8 @@ namespace PrintagoFolderWatch.Core
             {
                 Log("Checking for empty folders...", "INFO");
 
-                var apiUrl = Config.ApiUrl.TrimEnd('/');
-                var folders = await FetchAllFolders(apiUrl);
-                var parts = await FetchAllParts(apiUrl);
+                var folders = await FetchAllFolders();
+                var parts = await FetchAllParts();
 
                 var foldersWithParts = new HashSet<string>();
                 foreach (var part in parts)
@@ -1844,6 +3846,15 @@ namespace PrintagoFolderWatch.Core
                     }
                 }
 
+                if (Config.IsDryRun())
+                {
+                    foreach (var folder in foldersToDelete)
+                    {
+                        Log($"DRY RUN: would delete folder {ReconstructFolderPath(folder.id, folders)}", "INFO");
+                    }
+                    return 0;
+                }
+
                 if (foldersToDelete.Count > 0)
                 {
                     Log($"Found {foldersToDelete.Count} empty folders to delete", "INFO");
@@ -1862,14 +3873,13 @@ namespace PrintagoFolderWatch.Core
                                 type = "part"
                             };
 
-                            var request = new HttpRequestMessage(HttpMethod.Delete, $"{apiUrl}/v1/folders/delete")
+                            var request = new HttpRequestMessage(HttpMethod.Delete, apiClient.GetUrl("folders/delete"))
                             {
                                 Content = new StringContent(JsonConvert.SerializeObject(deleteBody), Encoding.UTF8, "application/json")
                             };
-                            request.Headers.Add("authorization", $"ApiKey {Config.ApiKey}");
-                            request.Headers.Add("x-printago-storeid", Config.StoreId);
+                            apiClient.AddApiHeaders(request);
 
-                            var response = await SendApiRequestAsync(request);
+                            var response = await apiClient.SendAsync(request);
 
                             if (response.IsSuccessStatusCode)
                             {
@@ -1879,8 +3889,7 @@ namespace PrintagoFolderWatch.Core
                             }
                             else
                             {
-                                var errorBody = await response.Content.ReadAsStringAsync();
-                                Log($"Failed to delete folder '{folderPath}': HTTP {response.StatusCode}", "ERROR");
+                                Log($"Failed to delete folder '{folderPath}': {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
                             }
                         }
                         catch (Exception ex)
@@ -1902,18 +3911,310 @@ namespace PrintagoFolderWatch.Core
 
         #region Periodic Tasks
 
+        private FileSystemWatcher CreateWatcher(string path)
+        {
+            var watcher = new FileSystemWatcher(path)
+            {
+                NotifyFilter = NotifyFilters.FileName | NotifyFilters.DirectoryName | NotifyFilters.LastWrite | NotifyFilters.CreationTime,
+                // Max buffer size - deep OneDrive trees can produce bursts larger than the 8KB default
+                InternalBufferSize = 64 * 1024,
+                IncludeSubdirectories = true
+            };
+
+            watcher.Created += OnFileChanged;
+            watcher.Changed += OnFileChanged;
+            watcher.Deleted += OnFileDeleted;
+            watcher.Renamed += OnFileRenamed;
+            watcher.Error += OnWatcherError;
+            watcher.EnableRaisingEvents = true;
+            return watcher;
+        }
+
+        /// <summary>
+        /// True if filePath is under a configured watch folder that doesn't exist right now (e.g. a network drive
+        /// that isn't connected), so a missing file there doesn't mean it was deleted
+        /// </summary>
+        private bool IsInUnavailableWatch(string filePath)
+        {
+            var watch = Config.FindWatch(filePath);
+            return watch != null && !Directory.Exists(watch.Path);
+        }
+
+        /// <summary>
+        /// Watch folders that aren't available right now, for the tray
+        /// </summary>
+        public List<string> GetUnavailableWatchPaths()
+        {
+            lock (lifecycleLock)
+            {
+                return unavailableWatches.Select(w => w.Path).ToList();
+            }
+        }
+
+        /// <summary>
+        /// Every Config.WatchPathRetrySeconds: stop watching folders that have disappeared (drive disconnected,
+        /// share offline), and start watching missing ones as soon as they exist - then rescan to catch up
+        /// on whatever changed meanwhile. A folder that stays missing is checked less and less often (up to
+        /// WATCH_RETRY_MAX_SECONDS apart), since checking a dead network path can itself hang for a while.
+        /// Each disappearance is notified once.
+        /// </summary>
+        private async Task MonitorWatchFolders(CancellationToken ct)
+        {
+            // Missing folder -> (when to check it next, checks so far)
+            var retries = new Dictionary<string, (DateTime nextCheck, int misses)>();
+
+            while (!ct.IsCancellationRequested)
+            {
+                var interval = TimeSpan.FromSeconds(Math.Max(1, Config.WatchPathRetrySeconds));
+                await Task.Delay(interval, ct);
+
+                var lost = new List<FileSystemWatcher>();
+                var returned = new List<string>();
+                lock (lifecycleLock)
+                {
+                    if (ct.IsCancellationRequested)
+                        return;
+
+                    foreach (var watcher in watchers.Where(w => !Directory.Exists(w.Path)).ToList())
+                    {
+                        watchers.Remove(watcher);
+                        lost.Add(watcher);
+                        var watch = Config.GetWatches().FirstOrDefault(w => w.Path == watcher.Path);
+                        if (watch != null)
+                            unavailableWatches.Add(watch);
+                    }
+
+                    foreach (var watch in unavailableWatches.ToList())
+                    {
+                        if (retries.TryGetValue(watch.Path, out var retry) && DateTime.UtcNow < retry.nextCheck)
+                            continue;
+
+                        if (!Directory.Exists(watch.Path))
+                        {
+                            var misses = retry.misses + 1;
+                            var wait = Math.Min(WATCH_RETRY_MAX_SECONDS, interval.TotalSeconds * Math.Pow(2, Math.Min(misses, 10)));
+                            retries[watch.Path] = (DateTime.UtcNow.AddSeconds(wait), misses);
+                            continue;
+                        }
+
+                        try
+                        {
+                            watchers.Add(CreateWatcher(watch.Path));
+                            unavailableWatches.Remove(watch);
+                            retries.Remove(watch.Path);
+                            returned.Add(watch.Path);
+                        }
+                        catch (Exception ex)
+                        {
+                            // Mounted but not ready yet - try again next time
+                            Log($"Could not watch {watch.Path} yet: {ex.Message}", "DEBUG");
+                        }
+                    }
+                }
+
+                // Disposing waits for in-progress event callbacks, so do it outside the lock
+                foreach (var watcher in lost)
+                {
+                    Log($"Watch folder no longer available: {watcher.Path} - waiting for it to come back", "WARN");
+                    Notify("Watch folder unavailable", $"{watcher.Path} - waiting for it to come back", Config.NOTIFY_ERRORS);
+                    watcher.Dispose();
+                }
+
+                foreach (var path in returned)
+                {
+                    Log($"Watch folder available: {path} - watching, rescanning to catch up", "SUCCESS");
+                    Notify("Printago", $"Watching {path} again", Config.NOTIFY_START_STOP);
+                }
+                if (returned.Count > 0)
+                    ScheduleResync();
+            }
+        }
+
+        /// <summary>
+        /// Every Config.PollIntervalSeconds (all available watch folders), or FALLBACK_POLL_SECONDS for folders that
+        /// couldn't be watched at all: list the files and compare size and modified time with the previous listing.
+        /// New and changed files go through OnFileChanged like a watcher event, so the debounce and queue dedupe
+        /// apply and a file seen by both a watcher and the poll is uploaded once. The first listing of a folder
+        /// only records it (the initial sync has just covered it); deletes are left to the rescan. The snapshot
+        /// is about 100 bytes per file.
+        /// </summary>
+        private async Task PollWatchFolders(CancellationToken ct)
+        {
+            var snapshots = new Dictionary<string, Dictionary<string, (long size, long writeTicks)>>();
+            var options = new EnumerationOptions { RecurseSubdirectories = true, IgnoreInaccessible = true, AttributesToSkip = FileAttributes.System };
+
+            while (!ct.IsCancellationRequested)
+            {
+                var fallbackOnly = Config.PollIntervalSeconds <= 0;
+                var interval = fallbackOnly ? FALLBACK_POLL_SECONDS : Config.PollIntervalSeconds;
+                await Task.Delay(TimeSpan.FromSeconds(interval), ct);
+
+                List<string> roots;
+                lock (lifecycleLock)
+                {
+                    roots = (fallbackOnly ? pollOnlyWatches : watchers.Select(w => w.Path).Concat(pollOnlyWatches)).ToList();
+                }
+
+                foreach (var stale in snapshots.Keys.Except(roots).ToList())
+                    snapshots.Remove(stale);
+
+                foreach (var root in roots)
+                {
+                    // A disconnected share would list as empty - keep the old snapshot until it's back
+                    if (isScanning || !Directory.Exists(root))
+                        continue;
+
+                    var previous = snapshots.GetValueOrDefault(root);
+                    var current = new Dictionary<string, (long size, long writeTicks)>(previous?.Count ?? 0);
+                    var changed = new List<string>();
+                    try
+                    {
+                        foreach (var file in new DirectoryInfo(root).EnumerateFiles("*", options))
+                        {
+                            ct.ThrowIfCancellationRequested();
+                            if (!IsSupportedFile(file.FullName))
+                                continue;
+
+                            var entry = (file.Length, file.LastWriteTimeUtc.Ticks);
+                            current[file.FullName] = entry;
+                            if (previous != null && (!previous.TryGetValue(file.FullName, out var before) || before != entry))
+                                changed.Add(file.FullName);
+                        }
+                    }
+                    catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+                    {
+                        // Gone part-way through - try again next time with the old snapshot
+                        Log($"Could not list {root}: {ex.Message}", "DEBUG");
+                        continue;
+                    }
+
+                    snapshots[root] = current;
+                    if (changed.Count == 0)
+                        continue;
+
+                    Log($"Polling {root} found {changed.Count} new or changed file(s)", "DEBUG");
+                    foreach (var file in changed)
+                    {
+                        OnFileChanged(this, new FileSystemEventArgs(WatcherChangeTypes.Changed, Path.GetDirectoryName(file) ?? "", Path.GetFileName(file)));
+                    }
+                }
+            }
+        }
+
+        /// <summary>
+        /// Notice the computer waking up from sleep or hibernation - the clock has jumped ahead of the timer - and
+        /// catch up on files synced down while it was asleep (OneDrive), which no watcher saw. A clock change has the
+        /// same effect, which only costs a catch-up.
+        /// </summary>
+        private async Task WatchForSleep(CancellationToken ct)
+        {
+            var lastCheck = DateTime.UtcNow;
+            var lastCatchUp = DateTime.MinValue;
+
+            while (!ct.IsCancellationRequested)
+            {
+                await Task.Delay(TimeSpan.FromSeconds(CLOCK_CHECK_SECONDS), ct);
+
+                var now = DateTime.UtcNow;
+                var gap = now - lastCheck - TimeSpan.FromSeconds(CLOCK_CHECK_SECONDS);
+                lastCheck = now;
+                if (gap < TimeSpan.FromSeconds(SLEEP_GAP_SECONDS))
+                    continue;
+
+                if (isScanning || now - lastCatchUp < TimeSpan.FromMinutes(CATCH_UP_MIN_MINUTES))
+                {
+                    Log($"Resumed after about {gap.TotalMinutes:0} min - a scan ran recently, not catching up again", "DEBUG");
+                    continue;
+                }
+
+                lastCatchUp = now;
+                Log($"Resumed after about {gap.TotalMinutes:0} min asleep - checking for files changed meanwhile", "INFO");
+                try
+                {
+                    var found = CatchUpChangedFiles(ct);
+                    Log($"Catch-up after sleep found {found} new or changed file(s)", found > 0 ? "WARN" : "INFO");
+                }
+                catch (Exception ex) when (ex is not OperationCanceledException)
+                {
+                    Log($"Catch-up after sleep failed: {ex.Message}", "ERROR");
+                }
+            }
+        }
+
+        /// <summary>
+        /// Walk the available watch folders and queue (in the scan lane, behind live changes) every file whose size
+        /// or modified time isn't what the tracking database recorded. Local only - Printago isn't re-listed, and
+        /// deletes are left to the rescan. Modified times aren't compared with the last scan, since synced-down files
+        /// keep the time they were changed elsewhere. Returns how many were queued.
+        /// </summary>
+        private int CatchUpChangedFiles(CancellationToken ct)
+        {
+            var tracked = new Dictionary<string, FileTrackingEntry>();
+            foreach (var entry in trackingDb?.GetAll() ?? new List<FileTrackingEntry>())
+                tracked[entry.FilePath] = entry;
+
+            List<string> roots;
+            lock (lifecycleLock)
+            {
+                roots = watchers.Select(w => w.Path).Concat(pollOnlyWatches).ToList();
+            }
+
+            var options = new EnumerationOptions { RecurseSubdirectories = true, IgnoreInaccessible = true, AttributesToSkip = FileAttributes.System };
+            int queued = 0;
+            foreach (var root in roots.Where(Directory.Exists))
+            {
+                foreach (var file in new DirectoryInfo(root).EnumerateFiles("*", options))
+                {
+                    ct.ThrowIfCancellationRequested();
+                    if (!IsSupportedFile(file.FullName))
+                        continue;
+
+                    if (tracked.TryGetValue(file.FullName, out var entry) && entry.FileSize == file.Length && entry.LastWriteUtc == file.LastWriteTimeUtc)
+                        continue;
+
+                    if (!tracked.ContainsKey(file.FullName) && IsPreExisting(file.FullName))
+                        continue;
+
+                    if (EnqueueUpload(file.FullName, fromScan: true))
+                        queued++;
+                }
+            }
+
+            return queued;
+        }
+
+        /// <summary>
+        /// Safety net for events the watchers missed (network shares, OneDrive): re-list Printago and re-walk
+        /// the watch folders every Config.RescanMinutes. Unchanged files reuse their recorded hash, so this is
+        /// mostly directory listing. The interval is re-read each time, so a reload applies from the next wait.
+        /// </summary>
         private async Task PeriodicCacheRefresh(CancellationToken ct)
         {
+            var lastRescan = DateTime.UtcNow;
             while (!ct.IsCancellationRequested)
             {
-                await Task.Delay(TimeSpan.FromMinutes(30), ct);
+                await Task.Delay(TimeSpan.FromMinutes(1), ct);
+
+                // A scan already running (Sync Now, a folder delete) counts as this one
+                if (isScanning)
+                    lastRescan = DateTime.UtcNow;
+
+                if (Config.RescanMinutes <= 0 || DateTime.UtcNow - lastRescan < TimeSpan.FromMinutes(Config.RescanMinutes))
+                    continue;
 
+                lastRescan = DateTime.UtcNow;
                 try
                 {
-                    Log("Refreshing cache...", "INFO");
-                    await BuildInitialCache();
-                    await ScanLocalFileSystem();
-                    await PerformInitialSync();
+                    Log("Periodic rescan...", "INFO");
+                    var (queuedUploads, queuedDeletions) = await RunFullSync();
+                    if (queuedUploads > 0 || queuedDeletions > 0)
+                    {
+                        Log($"Periodic rescan found {queuedUploads} file(s) to upload and {queuedDeletions} Part(s) to delete that were missed", "WARN");
+                    }
+                }
+                catch (OperationCanceledException) when (ct.IsCancellationRequested)
+                {
+                    return;
                 }
                 catch (Exception ex)
                 {
@@ -1924,10 +4225,34 @@ namespace PrintagoFolderWatch.Core
 
         #endregion
 
+        private void Notify(string title, string message, string notifyEvent)
+        {
+            if (Config.ShouldNotify(notifyEvent))
+            {
+                OnNotification?.Invoke(title, Redact(message), notifyEvent == Config.NOTIFY_ERRORS);
+            }
+        }
+
+        /// <summary>
+        /// Never show the API key in the log window, log files or notifications (e.g. inside an echoed request,
+        /// error body or exception)
+        /// </summary>
+        private string Redact(string message)
+        {
+            return string.IsNullOrEmpty(Config?.ApiKey) ? message : message.Replace(Config.ApiKey, "****");
+        }
+
         private void Log(string message, string level)
         {
+            message = Redact(message);
+
             OnLog?.Invoke(message, level);
 
+            if (level == "ERROR")
+            {
+                Interlocked.Exchange(ref lastErrorTicks, DateTime.Now.Ticks);
+            }
+
             var timestamp = DateTime.Now.ToString("HH:mm:ss");
             var logEntry = $"[{timestamp}] [{level}] {message}";
             recentLogs.Enqueue(logEntry);
@@ -1937,30 +4262,22 @@ namespace PrintagoFolderWatch.Core
                 recentLogs.TryDequeue(out _);
             }
 
-            try
-            {
-                var appDataPath = Path.Combine(
-                    Environment.GetFolderPath(Environment.SpecialFolder.LocalApplicationData),
-                    "PrintagoFolderWatch"
-                );
-                var logDir = Path.Combine(appDataPath, "logs");
-                Directory.CreateDirectory(logDir);
-                var logFile = Path.Combine(logDir, $"printago-{DateTime.Now:yyyy-MM-dd}.log");
-                var fullTimestamp = DateTime.Now.ToString("HH:mm:ss.fff");
-                File.AppendAllText(logFile, $"[{fullTimestamp}] [{level}] {message}\n");
-            }
-            catch
+            if (Config?.ShouldLog(level) ?? true)
             {
-                // Ignore file logging errors
+                LogFile.Write(level, message);
             }
         }
 
         public void Dispose()
         {
             Stop();
+            configWatcher?.Dispose();
             httpClient?.Dispose();
+            storageHttpClient?.Dispose();
             cts?.Dispose();
             trackingDb?.Dispose();
+            uploadQueue.Dispose();
+            scanUploadQueue.Dispose();
         }
     }
 }
@@ -0,0 +1,36 @@
+using System.IO;
+using System.Net.Http;
+using System.Net.Http.Headers;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// IStorageUploader that PUTs the file with an HttpClient. The client is passed in (and not disposed here),
+    /// so it can be pointed at a local test server.
+    /// </summary>
+    public class HttpStorageUploader : IStorageUploader
+    {
+        private readonly HttpClient httpClient;
+
+        public HttpStorageUploader(HttpClient httpClient)
+        {
+            this.httpClient = httpClient;
+        }
+
+        public async Task<HttpResponseMessage> PutAsync(string uploadUrl, Stream content, long length, string contentType,
+            byte[]? contentMd5, CancellationToken ct)
+        {
+            var streamContent = new StreamContent(content);
+            streamContent.Headers.ContentLength = length;
+            streamContent.Headers.ContentMD5 = contentMd5;
+            streamContent.Headers.ContentType = MediaTypeHeaderValue.TryParse(contentType, out var mediaType)
+                ? mediaType
+                : new MediaTypeHeaderValue("application/octet-stream");
+
+            using var request = new HttpRequestMessage(HttpMethod.Put, uploadUrl) { Content = streamContent };
+            return await httpClient.SendAsync(request, ct);
+        }
+    }
+}
@@ -9,16 +9,41 @@ namespace PrintagoFolderWatch.Core
     {
         Config Config { get; }
         event Action<string, string>? OnLog;
+        event Action<bool, string>? OnConfigReloaded;
+        event Action<string, string, bool>? OnNotification;
 
         int UploadQueueCount { get; }
         int DeleteQueueCount { get; }
         int FoldersCreatedCount { get; }
         int SyncedFilesCount { get; }
+        int MaxParallelUploads { get; }
+        bool IsRunning { get; }
+        string? StartError { get; }
+        string? CredentialsRejectedReason { get; }
+        int RetryingCount { get; }
+        int ActiveUploadCount { get; }
+        int PendingUploadCount { get; }
+        DateTime? LastErrorTime { get; }
+        WatcherStatus Status { get; }
+        bool UploadsPaused { get; }
+        int RecentFailedCount { get; }
 
+        string GetStatusSummary();
+        string GetActivitySummary();
+        void SetUploadsPaused(bool paused);
         List<UploadProgress> GetActiveUploads();
         List<string> GetQueueItems();
         List<string> GetDeleteQueueItems();
         List<string> GetRecentLogs(int count);
+        List<FailedUploadEntry> GetFailedUploads();
+        List<RecentUpload> GetRecentUploads();
+        List<string> GetUnavailableWatchPaths();
+        bool RetryUpload(string filePath);
+        int RetryAllFailed();
+        Task<bool> RestartAsync();
         Task TriggerSyncNow();
+        Task ForceFullResync();
+        Task<(bool success, string message)> ReloadConfig();
+        Task<(bool success, string message)> TestConnection();
     }
 }
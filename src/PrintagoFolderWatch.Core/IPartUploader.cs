@@ -0,0 +1,71 @@
+using System;
+using System.Collections.Generic;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Models;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Gets a file into storage and onto its Part: signed URLs (batched, and prefetched for a scan), the storage
+    /// PUT, creating or updating the Part, and the retry backoff around an attempt. FileWatcherService decides what
+    /// to upload and what the outcome means (caches, failed list, notifications).
+    /// </summary>
+    internal interface IPartUploader
+    {
+        // A message and its level (INFO, WARN, ERROR...), like FileWatcherService.OnLog
+        event Action<string, string>? OnLog;
+
+        // Uploads waiting out a retry backoff
+        int RetryingCount { get; }
+
+        // Combined speed of the storage PUTs over the last few seconds
+        double BytesPerSecond { get; }
+
+        /// <summary>
+        /// Signed upload URL and storage path for one cloud path, or null if the response had none for it
+        /// </summary>
+        Task<(string uploadUrl, string storagePath)?> GetSignedUploadUrlAsync(string cloudPath, CancellationToken ct = default);
+
+        /// <summary>
+        /// Fetch signed URLs for a scan's files ahead of their uploads; UploadAsync uses them when it gets there
+        /// </summary>
+        Task PrefetchSignedUrlsAsync(IReadOnlyList<string> filePaths, Func<string, string> getCloudPath, CancellationToken ct);
+
+        void ForgetPrefetchedSignedUrl(string filePath);
+
+        /// <summary>
+        /// Watching stopped: drop the prefetched URLs and cancel the batch requests still in flight
+        /// </summary>
+        void CancelSignedUrlRequests();
+
+        /// <summary>
+        /// PUT a file to its signed URL (a fresh one if that's expired). Returns the response and the URL used.
+        /// </summary>
+        Task<(HttpResponseMessage response, (string uploadUrl, string storagePath) signedUrl)> PutFileToSignedUrlAsync(
+            string cloudPath, (string uploadUrl, string storagePath) signedUrl, string filePath, UploadProgress? progress, CancellationToken ct);
+
+        /// <summary>
+        /// One attempt: signed URL, PUT, then the Part. A failed response comes back as the result (with its status);
+        /// an exception is left for the caller to classify.
+        /// </summary>
+        Task<(UploadResult result, string partId, int? httpStatus)> UploadAsync(PartUpload upload, UploadProgress progress, CancellationToken ct);
+
+        /// <summary>
+        /// VerifyUploads: fetch the Part back and check Printago lists fileHash (the SHA-256 of the file just
+        /// uploaded). Returns what doesn't match, or null if it checks out. No response at all throws like any
+        /// other request, so it's retried as a network failure.
+        /// </summary>
+        Task<string?> VerifyPartAsync(string partId, string fileHash, CancellationToken ct);
+
+        /// <summary>
+        /// Run attempt in an upload slot until it doesn't fail transiently or Config.MaxRetries is used up, backing
+        /// off (without the slot) in between. A failure while the API is rate limiting waits for the pause to end and
+        /// is tried again in the same slot. A network or auth failure goes to waitOut first; when that returns true,
+        /// it's tried again. Neither counts as an attempt. Returns the last result and the attempts made.
+        /// </summary>
+        Task<(UploadResult result, int attempts)> RunWithRetriesAsync(string fileName, Func<CancellationToken, Task<UploadResult>> attempt,
+            Func<UploadResult, CancellationToken, Task<bool>> waitOut, CancellationToken ct);
+    }
+}
@@ -0,0 +1,48 @@
+using System;
+using System.Collections.Generic;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Requests to the Printago API. FileWatcherService and the part uploader build the folder and Part requests
+    /// and decide what to do with the answers; this is where they're addressed, authenticated, paced and rate
+    /// limited.
+    /// </summary>
+    public interface IPrintagoApiClient
+    {
+        // A message and its level (INFO, WARN, ERROR...), like FileWatcherService.OnLog
+        event Action<string, string>? OnLog;
+
+        // The API started rate limiting: every request waits until this time (UTC). Not raised again while
+        // a pause is only being extended.
+        event Action<DateTime>? OnRateLimited;
+
+        bool IsRateLimited { get; }
+        DateTime RateLimitedUntilUtc { get; }
+
+        /// <summary>
+        /// Absolute URL of an API endpoint under Config.ApiBasePath, e.g. GetUrl("parts")
+        /// </summary>
+        string GetUrl(string path);
+
+        void AddApiHeaders(HttpRequestMessage request);
+
+        Task<HttpResponseMessage> SendAsync(HttpRequestMessage request, CancellationToken ct = default);
+
+        /// <summary>
+        /// Signed upload URL and storage path for each cloud path the response could be matched to, or null if
+        /// it had none. A failed request throws HttpRequestException with its status.
+        /// </summary>
+        Task<Dictionary<string, (string uploadUrl, string storagePath)>?> GetSignedUploadUrlsAsync(
+            IReadOnlyList<string> cloudPaths, CancellationToken ct = default);
+
+        /// <summary>
+        /// "HTTP 401 - Invalid API key" for a failed response, and the same with the body (on one line, truncated)
+        /// for the log. The API key is taken out of both.
+        /// </summary>
+        Task<(string description, string logText)> ReadErrorResponse(HttpResponseMessage response);
+    }
+}
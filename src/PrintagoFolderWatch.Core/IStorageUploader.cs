@@ -0,0 +1,17 @@
+using System.IO;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Sends one file's bytes to a signed storage URL. FileWatcherService does the stability, checksum and
+    /// retry handling around it, so a fake can stand in for storage when testing that logic.
+    /// </summary>
+    public interface IStorageUploader
+    {
+        Task<HttpResponseMessage> PutAsync(string uploadUrl, Stream content, long length, string contentType,
+            byte[]? contentMd5, CancellationToken ct);
+    }
+}
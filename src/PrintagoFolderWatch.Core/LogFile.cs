@@ -0,0 +1,74 @@
+using System;
+using System.IO;
+using System.Text;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Append-only log file with size-based rotation: app.log is renamed to app.1.log (and so on, keeping
+    /// MAX_FILES in total) once it reaches MAX_FILE_BYTES. Safe to call from any thread; write errors are ignored.
+    /// </summary>
+    public static class LogFile
+    {
+        private const long MAX_FILE_BYTES = 5 * 1024 * 1024;
+        private const int MAX_FILES = 3;
+
+        private static readonly object writeLock = new();
+        private static string? openPath;
+        private static long currentSize;
+
+        public static string CurrentPath => Config.LogFile;
+
+        public static void Write(string level, string message)
+        {
+            var line = $"{DateTime.Now:yyyy-MM-dd HH:mm:ss.fff} [{level}] {message}{Environment.NewLine}";
+
+            lock (writeLock)
+            {
+                try
+                {
+                    var path = CurrentPath;
+                    if (openPath != path)
+                    {
+                        // First write, or --config pointed somewhere else
+                        Directory.CreateDirectory(Path.GetDirectoryName(path)!);
+                        currentSize = File.Exists(path) ? new FileInfo(path).Length : 0;
+                        openPath = path;
+                    }
+
+                    var lineBytes = Encoding.UTF8.GetByteCount(line);
+                    if (currentSize > 0 && currentSize + lineBytes > MAX_FILE_BYTES)
+                    {
+                        Rotate(path);
+                        currentSize = 0;
+                    }
+
+                    File.AppendAllText(path, line);
+                    currentSize += lineBytes;
+                }
+                catch
+                {
+                    // Ignore file logging errors
+                }
+            }
+        }
+
+        private static void Rotate(string path)
+        {
+            var directory = Path.GetDirectoryName(path)!;
+            var name = Path.GetFileNameWithoutExtension(path);
+            var extension = Path.GetExtension(path);
+            string Numbered(int index) => Path.Combine(directory, $"{name}.{index}{extension}");
+
+            File.Delete(Numbered(MAX_FILES - 1));
+            for (var i = MAX_FILES - 2; i >= 1; i--)
+            {
+                if (File.Exists(Numbered(i)))
+                {
+                    File.Move(Numbered(i), Numbered(i + 1));
+                }
+            }
+            File.Move(path, Numbered(1));
+        }
+    }
+}
@@ -0,0 +1,17 @@
+namespace PrintagoFolderWatch.Core.Models
+{
+    /// <summary>
+    /// A file to send to storage and the Part it goes on: ExistingPartId gets the new file, or without one a Part
+    /// named PartName is created in FolderId
+    /// </summary>
+    public class PartUpload
+    {
+        public string FilePath { get; set; } = "";
+        public string CloudPath { get; set; } = "";
+        // Cache key shown in the log, e.g. "parts/cube.stl"
+        public string Key { get; set; } = "";
+        public string PartName { get; set; } = "";
+        public string? FolderId { get; set; }
+        public string? ExistingPartId { get; set; }
+    }
+}
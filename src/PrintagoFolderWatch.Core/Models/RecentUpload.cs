@@ -0,0 +1,18 @@
+using System;
+
+namespace PrintagoFolderWatch.Core.Models
+{
+    /// <summary>
+    /// A finished upload (uploaded, or failed for good) for the tray's Recent Uploads menu
+    /// </summary>
+    public class RecentUpload
+    {
+        public string FilePath { get; set; } = "";
+        public string FileName { get; set; } = "";
+        // Where the file is in Printago, e.g. "Folder Watch/gcode/benchy.3mf"
+        public string CloudPath { get; set; } = "";
+        public DateTime FinishedAt { get; set; } = DateTime.Now;
+        public bool Success { get; set; }
+        public string? Error { get; set; }
+    }
+}
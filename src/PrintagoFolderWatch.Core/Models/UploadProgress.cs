@@ -11,5 +11,7 @@ namespace PrintagoFolderWatch.Core.Models
         public string Status { get; set; } = "Waiting...";
         public DateTime StartTime { get; set; } = DateTime.Now;
         public long FileSizeBytes { get; set; } = 0;
+        // Bytes of the current PUT sent so far
+        public long BytesSent { get; set; } = 0;
     }
 }
@@ -0,0 +1,40 @@
+using System.Collections.Generic;
+
+namespace PrintagoFolderWatch.Core.Models
+{
+    /// <summary>
+    /// One watched folder in Config.Watches
+    /// </summary>
+    public class WatchEntry
+    {
+        public string Path { get; set; } = "";
+
+        // Printago folder (under the sync root) this folder's files go in, e.g. "gcode". Empty = the sync root.
+        public string CloudPrefix { get; set; } = "";
+
+        // Replaces Config.IncludeExtensions for this folder when set
+        public List<string>? IncludeExtensions { get; set; }
+
+        // Replace Config.PostUploadAction / Config.MoveToDir for this folder when set
+        public string? PostUploadAction { get; set; }
+        public string? MoveToDir { get; set; }
+
+        /// <summary>
+        /// A copy of this entry for another folder
+        /// </summary>
+        public WatchEntry WithPath(string path)
+        {
+            var copy = (WatchEntry)MemberwiseClone();
+            copy.Path = path;
+            return copy;
+        }
+
+        /// <summary>
+        /// CloudPrefix with forward slashes and no leading/trailing slash ("" when not set)
+        /// </summary>
+        public string GetCloudPrefix()
+        {
+            return (CloudPrefix ?? "").Trim().Replace('\\', '/').Trim('/');
+        }
+    }
+}
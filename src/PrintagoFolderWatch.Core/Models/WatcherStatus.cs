@@ -0,0 +1,14 @@
+namespace PrintagoFolderWatch.Core.Models
+{
+    /// <summary>
+    /// Overall state shown by the tray icon
+    /// </summary>
+    public enum WatcherStatus
+    {
+        Stopped,
+        Watching,
+        Uploading,
+        Paused, // Watching, but uploads were paused from the tray
+        Error
+    }
+}
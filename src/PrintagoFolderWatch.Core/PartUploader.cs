@@ -0,0 +1,727 @@
+using System;
+using System.Collections.Concurrent;
+using System.Collections.Generic;
+using System.IO;
+using System.Linq;
+using System.Net.Http;
+using System.Security.Cryptography;
+using System.Text;
+using System.Threading;
+using System.Threading.Tasks;
+using Newtonsoft.Json;
+using Newtonsoft.Json.Linq;
+using PrintagoFolderWatch.Core.Models;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// IPartUploader over the API client and a storage uploader. The config is read on every upload, so a reload
+    /// (batch size, bandwidth cap, retries...) applies to the next one; the number of upload slots is fixed.
+    /// </summary>
+    internal class PartUploader : IPartUploader
+    {
+        // Opening a file that another program has locked: attempts, and the delay before the next (x attempt)
+        private const int FILE_OPEN_ATTEMPTS = 5;
+        private const int FILE_OPEN_RETRY_MS = 500;
+        private const int MAX_RETRY_DELAY_SECONDS = 60;
+        private const int MAX_RATE_LIMIT_RETRIES = 3;
+        private const int SIGNED_URL_BATCH_WINDOW_MS = 250;
+        // VerifyUploads: how often a Part with no file hash yet is fetched again, and how far apart
+        private const int VERIFY_ATTEMPTS = 3;
+        private const int VERIFY_RETRY_MS = 2000;
+
+        private readonly IPrintagoApiClient apiClient;
+        private readonly IStorageUploader storageUploader;
+        private readonly Func<Config> getConfig;
+
+        // Uploads in progress at once - a file waiting on a retry backoff gives its slot up
+        private readonly SemaphoreSlim uploadSlots;
+        private int retryingUploads = 0;
+
+        // Caps the combined speed of all storage PUTs (Config.MaxUploadBytesPerSec, read live so reloads apply)
+        private readonly BandwidthLimiter bandwidth;
+        // Bytes actually sent by all storage PUTs, for the speed shown in the tray
+        private readonly ThroughputMeter throughput = new();
+
+        // Signed URL batching: uploads that need a URL within the same window share one request
+        private readonly object signedUrlBatchLock = new();
+        private List<(string cloudPath, TaskCompletionSource<(string uploadUrl, string storagePath)?> tcs)> pendingSignedUrls = new();
+        // Cancels the batch requests in flight when watching stops (replaced, so the next run starts clean)
+        private CancellationTokenSource signedUrlCts = new();
+
+        // Initial sync: signed URLs fetched ahead of the workers in full batches, by local file path
+        private readonly ConcurrentDictionary<string, (string cloudPath, Task<Dictionary<string, (string uploadUrl, string storagePath)>?> batch)> prefetchedSignedUrls = new();
+
+        public event Action<string, string>? OnLog;
+
+        public PartUploader(IPrintagoApiClient apiClient, IStorageUploader storageUploader, int maxParallelUploads, Func<Config> getConfig)
+        {
+            this.apiClient = apiClient;
+            this.storageUploader = storageUploader;
+            this.getConfig = getConfig;
+            uploadSlots = new SemaphoreSlim(maxParallelUploads, maxParallelUploads);
+            bandwidth = new BandwidthLimiter(() => getConfig().MaxUploadBytesPerSec);
+        }
+
+        public int RetryingCount => Volatile.Read(ref retryingUploads);
+
+        public double BytesPerSecond => throughput.BytesPerSecond;
+
+        #region Retries
+
+        public async Task<(UploadResult result, int attempts)> RunWithRetriesAsync(string fileName, Func<CancellationToken, Task<UploadResult>> attempt,
+            Func<UploadResult, CancellationToken, Task<bool>> waitOut, CancellationToken ct)
+        {
+            bool retrying = false;
+            try
+            {
+                for (int attempts = 0; ; attempts++)
+                {
+                    UploadResult result;
+                    await uploadSlots.WaitAsync(ct);
+                    try
+                    {
+                        result = await attempt(ct);
+
+                        // Not counted as an attempt: every upload is waiting out the same API rate limit. Tried
+                        // again without giving up the slot, so this file goes first once the pause ends.
+                        while (result == UploadResult.TransientFailure && apiClient.IsRateLimited)
+                        {
+                            while (apiClient.IsRateLimited)
+                            {
+                                await Task.Delay(1000, ct);
+                            }
+                            result = await attempt(ct);
+                        }
+                    }
+                    finally
+                    {
+                        uploadSlots.Release();
+                    }
+
+                    if ((result is UploadResult.NetworkFailure or UploadResult.AuthFailure) && await waitOut(result, ct))
+                    {
+                        attempts--;
+                        continue;
+                    }
+
+                    // Not waited out: still offline for a one-shot upload, which fails like any network error
+                    if (result == UploadResult.NetworkFailure)
+                        result = UploadResult.TransientFailure;
+
+                    var maxRetries = getConfig().MaxRetries;
+                    if (result != UploadResult.TransientFailure || attempts >= maxRetries)
+                        return (result, attempts + 1);
+
+                    if (!retrying)
+                    {
+                        retrying = true;
+                        Interlocked.Increment(ref retryingUploads);
+                    }
+
+                    // Back off outside the semaphore so a failing file doesn't hold an upload slot
+                    var delay = GetRetryDelay(attempts);
+                    Log($"Retrying {fileName} in {delay.TotalSeconds:0.#}s (attempt {attempts + 1}/{maxRetries})", "WARN");
+                    await Task.Delay(delay, ct);
+                }
+            }
+            finally
+            {
+                if (retrying)
+                    Interlocked.Decrement(ref retryingUploads);
+            }
+        }
+
+        /// <summary>
+        /// Exponential backoff (1s, 2s, 4s...) capped at MAX_RETRY_DELAY_SECONDS, plus up to 1s of jitter
+        /// so files that failed together don't all retry at the same instant.
+        /// </summary>
+        internal static TimeSpan GetRetryDelay(int attempt)
+        {
+            var seconds = Math.Min(Math.Pow(2, attempt), MAX_RETRY_DELAY_SECONDS);
+            return TimeSpan.FromSeconds(seconds) + TimeSpan.FromMilliseconds(Random.Shared.Next(0, 1000));
+        }
+
+        /// <summary>
+        /// 4xx responses (bad key, bad request...) won't succeed on retry. Timeouts, rate limits
+        /// and server errors might.
+        /// </summary>
+        internal static UploadResult ClassifyFailure(System.Net.HttpStatusCode statusCode)
+        {
+            var code = (int)statusCode;
+            if (code >= 400 && code < 500 && code != 408 && code != 429)
+                return UploadResult.PermanentFailure;
+
+            return UploadResult.TransientFailure;
+        }
+
+        internal static UploadResult ClassifyFailure(Exception ex)
+        {
+            return ex switch
+            {
+                HttpRequestException httpEx when httpEx.StatusCode.HasValue => ClassifyFailure(httpEx.StatusCode.Value),
+                HttpRequestException => UploadResult.TransientFailure,
+                TaskCanceledException => UploadResult.TransientFailure, // HttpClient timeout
+                IOException => UploadResult.TransientFailure,
+                _ => UploadResult.PermanentFailure
+            };
+        }
+
+        #endregion
+
+        #region Upload
+
+        public async Task<(UploadResult result, string partId, int? httpStatus)> UploadAsync(PartUpload upload, UploadProgress progress, CancellationToken ct)
+        {
+            progress.Status = "Getting signed URL...";
+            progress.ProgressPercent = 20;
+
+            var signedUrl = await TakePrefetchedSignedUrl(upload.FilePath, upload.CloudPath)
+                ?? await GetSignedUploadUrlAsync(upload.CloudPath, ct);
+            if (signedUrl == null)
+            {
+                progress.Status = "Failed - No signed URL";
+                Log($"Failed: {upload.Key} - no signed URL in the API response", "ERROR");
+                return (UploadResult.TransientFailure, "", null);
+            }
+
+            progress.Status = "Uploading...";
+            progress.ProgressPercent = 40;
+
+            var put = await PutFileToSignedUrlAsync(upload.CloudPath, signedUrl.Value, upload.FilePath, progress, ct);
+            using (var uploadResponse = put.response)
+            {
+                if (!uploadResponse.IsSuccessStatusCode)
+                {
+                    var (description, error) = await apiClient.ReadErrorResponse(uploadResponse);
+                    progress.Status = $"Upload failed: {description}";
+                    Log($"Upload failed: {upload.Key} - {error}", "ERROR");
+                    return (ClassifyFailure(uploadResponse.StatusCode), "", (int)uploadResponse.StatusCode);
+                }
+            }
+
+            var storagePath = put.signedUrl.storagePath;
+            progress.ProgressPercent = 80;
+
+            if (upload.ExistingPartId != null)
+            {
+                progress.Status = "Updating part...";
+                if (await UpdatePartFile(upload.ExistingPartId, storagePath, ct))
+                    return (UploadResult.Success, upload.ExistingPartId, null);
+
+                progress.Status = "Failed to update part";
+                Log($"Failed to update part: {upload.Key}", "ERROR");
+                return (UploadResult.TransientFailure, "", null);
+            }
+
+            progress.Status = "Creating part...";
+
+            var partType = Path.GetExtension(upload.FilePath).ToLower() == ".3mf" ? "3mf" : "stl";
+            var partBody = new
+            {
+                name = upload.PartName,
+                type = partType,
+                description = "Auto-uploaded from folder watch",
+                fileUris = new[] { storagePath },
+                parameters = new object[0],
+                printTags = new { },
+                overriddenProcessProfileId = (string?)null,
+                folderId = upload.FolderId
+            };
+
+            var partRequest = new HttpRequestMessage(HttpMethod.Post, apiClient.GetUrl("parts"))
+            {
+                Content = new StringContent(JsonConvert.SerializeObject(partBody), Encoding.UTF8, "application/json")
+            };
+            apiClient.AddApiHeaders(partRequest);
+
+            using var partResponse = await apiClient.SendAsync(partRequest, ct);
+            if (!partResponse.IsSuccessStatusCode)
+            {
+                var (description, error) = await apiClient.ReadErrorResponse(partResponse);
+                progress.Status = $"Failed to create part: {description}";
+                Log($"Failed to create part: {upload.Key} - {error}", "ERROR");
+                return (ClassifyFailure(partResponse.StatusCode), "", (int)partResponse.StatusCode);
+            }
+
+            var partResponseJson = await partResponse.Content.ReadAsStringAsync();
+            var createdPart = JsonConvert.DeserializeAnonymousType(partResponseJson, new { id = "" });
+            return (UploadResult.Success, createdPart?.id ?? "", null);
+        }
+
+        private async Task<bool> UpdatePartFile(string partId, string storagePath, CancellationToken ct)
+        {
+            try
+            {
+                var updateBody = new { fileUris = new[] { storagePath } };
+
+                var request = new HttpRequestMessage(HttpMethod.Patch, apiClient.GetUrl($"parts/{partId}"))
+                {
+                    Content = new StringContent(JsonConvert.SerializeObject(updateBody), Encoding.UTF8, "application/json")
+                };
+                apiClient.AddApiHeaders(request);
+
+                var response = await apiClient.SendAsync(request, ct);
+
+                if (response.IsSuccessStatusCode)
+                {
+                    Log($"✓ Updated Part {partId} with new file", "SUCCESS");
+                    return true;
+                }
+                else
+                {
+                    Log($"Failed to update Part {partId} file: {(await apiClient.ReadErrorResponse(response)).logText}", "ERROR");
+                    return false;
+                }
+            }
+            catch (OperationCanceledException) when (ct.IsCancellationRequested)
+            {
+                throw;
+            }
+            catch (Exception ex)
+            {
+                Log($"Error updating Part {partId} file: {ex.Message}", "ERROR");
+                return false;
+            }
+        }
+
+        /// <summary>
+        /// Printago hashes a new file after it's attached, so a Part with no hash yet is asked again a few times;
+        /// if it never reports one that's only logged.
+        /// </summary>
+        public async Task<string?> VerifyPartAsync(string partId, string fileHash, CancellationToken ct)
+        {
+            if (string.IsNullOrEmpty(partId))
+                return "Printago didn't return a Part ID";
+
+            for (int attempt = 1; ; attempt++)
+            {
+                using var request = new HttpRequestMessage(HttpMethod.Get, apiClient.GetUrl($"parts/{partId}"));
+                apiClient.AddApiHeaders(request);
+
+                using var response = await apiClient.SendAsync(request, ct);
+                if (response.StatusCode == System.Net.HttpStatusCode.NotFound)
+                    return $"Part {partId} doesn't exist";
+                if (!response.IsSuccessStatusCode)
+                    return $"couldn't read Part {partId} back ({(await apiClient.ReadErrorResponse(response)).description})";
+
+                string[] hashes;
+                try
+                {
+                    var part = JObject.Parse(await response.Content.ReadAsStringAsync());
+                    hashes = (part["fileHashes"] as JArray)?.Select(h => h.ToString().ToLowerInvariant()).ToArray() ?? Array.Empty<string>();
+                }
+                catch (JsonException)
+                {
+                    return $"couldn't read Part {partId} back (not JSON)";
+                }
+
+                if (hashes.Contains(fileHash))
+                    return null;
+                if (hashes.Length > 0)
+                    return $"Printago has {hashes[0][..Math.Min(12, hashes[0].Length)]}…, the file is {fileHash[..12]}…";
+
+                if (attempt >= VERIFY_ATTEMPTS)
+                {
+                    Log($"Couldn't verify Part {partId} - Printago hasn't reported its file hash yet", "WARN");
+                    return null;
+                }
+                await Task.Delay(VERIFY_RETRY_MS, ct);
+            }
+        }
+
+        #endregion
+
+        #region Signed URLs
+
+        /// <summary>
+        /// Get a signed upload URL for one file. Requests made within SIGNED_URL_BATCH_WINDOW_MS of each other
+        /// (up to Config.SignedUrlBatchSize) are sent to the API as a single batch. Cancelling ct only stops this
+        /// caller waiting; the batch request itself is cancelled by CancelSignedUrlRequests.
+        /// </summary>
+        public async Task<(string uploadUrl, string storagePath)?> GetSignedUploadUrlAsync(string cloudPath, CancellationToken ct = default)
+        {
+            var tcs = new TaskCompletionSource<(string uploadUrl, string storagePath)?>(TaskCreationOptions.RunContinuationsAsynchronously);
+            List<(string cloudPath, TaskCompletionSource<(string uploadUrl, string storagePath)?> tcs)>? fullBatch = null;
+            bool startWindow = false;
+
+            lock (signedUrlBatchLock)
+            {
+                pendingSignedUrls.Add((cloudPath, tcs));
+                if (pendingSignedUrls.Count >= Math.Max(1, getConfig().SignedUrlBatchSize))
+                {
+                    fullBatch = pendingSignedUrls;
+                    pendingSignedUrls = new();
+                }
+                else if (pendingSignedUrls.Count == 1)
+                {
+                    startWindow = true;
+                }
+            }
+
+            if (fullBatch != null)
+            {
+                _ = FlushSignedUrlBatch(fullBatch);
+            }
+            else if (startWindow)
+            {
+                _ = Task.Run(async () =>
+                {
+                    await Task.Delay(SIGNED_URL_BATCH_WINDOW_MS);
+
+                    List<(string cloudPath, TaskCompletionSource<(string uploadUrl, string storagePath)?> tcs)> batch;
+                    lock (signedUrlBatchLock)
+                    {
+                        if (pendingSignedUrls.Count == 0)
+                            return; // Already flushed because it filled up
+                        batch = pendingSignedUrls;
+                        pendingSignedUrls = new();
+                    }
+
+                    await FlushSignedUrlBatch(batch);
+                });
+            }
+
+            return await tcs.Task.WaitAsync(ct);
+        }
+
+        /// <summary>
+        /// Request signed URLs for the initial sync's uploads in batches of Config.SignedUrlBatchSize, staying about
+        /// two batches ahead of the workers so the URLs are fresh when used. A failed batch is only logged - those
+        /// files fall back to GetSignedUploadUrlAsync when their upload starts.
+        /// </summary>
+        public async Task PrefetchSignedUrlsAsync(IReadOnlyList<string> filePaths, Func<string, string> getCloudPath, CancellationToken ct)
+        {
+            var batchSize = Math.Max(1, getConfig().SignedUrlBatchSize);
+
+            try
+            {
+                foreach (var chunk in filePaths.Chunk(batchSize))
+                {
+                    while (prefetchedSignedUrls.Count >= batchSize * 2)
+                    {
+                        await Task.Delay(500, ct);
+                    }
+
+                    var files = chunk
+                        .Where(File.Exists)
+                        .Select(filePath => (filePath, cloudPath: getCloudPath(filePath)))
+                        .ToList();
+                    if (files.Count == 0)
+                        continue;
+
+                    var batch = RequestPrefetchBatch(files.Select(f => f.cloudPath).Distinct().ToList());
+                    foreach (var (filePath, cloudPath) in files)
+                    {
+                        prefetchedSignedUrls[filePath] = (cloudPath, batch);
+                    }
+
+                    // One request at a time - they still go through the API rate limiter
+                    await batch;
+                }
+            }
+            catch (OperationCanceledException)
+            {
+                // Stopped
+            }
+        }
+
+        public void ForgetPrefetchedSignedUrl(string filePath)
+        {
+            prefetchedSignedUrls.TryRemove(filePath, out _);
+        }
+
+        public void CancelSignedUrlRequests()
+        {
+            prefetchedSignedUrls.Clear();
+            Interlocked.Exchange(ref signedUrlCts, new CancellationTokenSource()).Cancel();
+        }
+
+        private async Task<Dictionary<string, (string uploadUrl, string storagePath)>?> RequestPrefetchBatch(List<string> cloudPaths)
+        {
+            try
+            {
+                var urls = await apiClient.GetSignedUploadUrlsAsync(cloudPaths);
+                Log($"Prefetched {urls?.Count ?? 0}/{cloudPaths.Count} signed URLs", "DEBUG");
+                return urls;
+            }
+            catch (Exception ex)
+            {
+                Log($"Signed URL prefetch failed ({ex.Message}) - {cloudPaths.Count} file(s) will request their own", "WARN");
+                return null;
+            }
+        }
+
+        /// <summary>
+        /// Signed URL fetched for this file by PrefetchSignedUrlsAsync, or null if there isn't one (live events,
+        /// or the file was missing from its batch's response)
+        /// </summary>
+        private async Task<(string uploadUrl, string storagePath)?> TakePrefetchedSignedUrl(string filePath, string cloudPath)
+        {
+            if (!prefetchedSignedUrls.TryRemove(filePath, out var prefetched) || prefetched.cloudPath != cloudPath)
+                return null;
+
+            var urls = await prefetched.batch;
+            return urls != null && urls.TryGetValue(cloudPath, out var url) ? url : null;
+        }
+
+        private async Task FlushSignedUrlBatch(List<(string cloudPath, TaskCompletionSource<(string uploadUrl, string storagePath)?> tcs)> batch)
+        {
+            try
+            {
+                var cloudPaths = batch.Select(b => b.cloudPath).Distinct().ToList();
+                var urls = await apiClient.GetSignedUploadUrlsAsync(cloudPaths, signedUrlCts.Token);
+                if (batch.Count > 1)
+                {
+                    Log($"Fetched {urls?.Count ?? 0} signed URLs in one request", "DEBUG");
+                }
+
+                var unmatched = new List<(string cloudPath, TaskCompletionSource<(string uploadUrl, string storagePath)?> tcs)>();
+                foreach (var (cloudPath, tcs) in batch)
+                {
+                    if (urls != null && urls.TryGetValue(cloudPath, out var url))
+                        tcs.TrySetResult(url);
+                    else if (cloudPaths.Count > 1)
+                        unmatched.Add((cloudPath, tcs));
+                    else
+                        tcs.TrySetResult(null);
+                }
+
+                // Files the response couldn't be matched to ask again on their own, where there's no other URL to mix up
+                foreach (var single in unmatched.GroupBy(u => u.cloudPath))
+                {
+                    _ = FlushSignedUrlBatch(single.ToList());
+                }
+            }
+            catch (Exception ex)
+            {
+                // Every waiting upload sees the same failure (and classifies it for retry)
+                foreach (var (_, tcs) in batch)
+                {
+                    tcs.TrySetException(ex);
+                }
+            }
+        }
+
+        #endregion
+
+        #region Storage PUT
+
+        /// <summary>
+        /// PUT a file to its signed URL. Signed URLs expire, so a file that waited behind a long queue can get a 403 -
+        /// in that case a fresh URL is requested and the PUT is tried once more. Returns the URL actually used.
+        /// </summary>
+        public async Task<(HttpResponseMessage response, (string uploadUrl, string storagePath) signedUrl)> PutFileToSignedUrlAsync(
+            string cloudPath, (string uploadUrl, string storagePath) signedUrl, string filePath, UploadProgress? progress, CancellationToken ct)
+        {
+            var response = await PutFileToStorage(signedUrl.uploadUrl, filePath, progress, ct);
+            if (response.StatusCode != System.Net.HttpStatusCode.Forbidden)
+                return (response, signedUrl);
+
+            Log($"Signed URL rejected for {cloudPath} (HTTP 403, probably expired) - requesting a new one", "WARN");
+            var freshUrl = await GetSignedUploadUrlAsync(cloudPath, ct);
+            if (freshUrl == null)
+                return (response, signedUrl);
+
+            response.Dispose();
+            response = await PutFileToStorage(freshUrl.Value.uploadUrl, filePath, progress, ct);
+            if (response.IsSuccessStatusCode)
+            {
+                Log($"Uploaded {cloudPath} after refreshing its signed URL", "WARN");
+            }
+
+            return (response, freshUrl.Value);
+        }
+
+        /// <summary>
+        /// PUT a file to a signed storage URL, streamed from disk so large files aren't held in memory.
+        /// If the file changes size or timestamp while it's being sent, the PUT is repeated once.
+        /// With Config.SendContentMd5 the storage service checks the bytes itself and a rejected checksum is
+        /// sent once more. The MD5 of the bytes sent is also checked against the storage ETag; a mismatch throws
+        /// an IOException so the upload is retried like any other transient failure. A 429 is retried up to 3 times
+        /// after its Retry-After, like API requests. An upload that sends nothing for Config.UploadTimeoutSeconds
+        /// is aborted with an IOException, so it's retried too. Cancelling ct aborts the upload straight away.
+        /// </summary>
+        private async Task<HttpResponseMessage> PutFileToStorage(string uploadUrl, string filePath, UploadProgress? progress, CancellationToken ct)
+        {
+            var config = getConfig();
+            bool resentForChange = false;
+            bool resentForChecksum = false;
+            int rateLimitRetries = 0;
+
+            while (true)
+            {
+                var info = new FileInfo(filePath);
+                var before = (info.Length, info.LastWriteTimeUtc);
+                HttpResponseMessage response;
+                using var md5 = MD5.Create();
+
+                // A header has to be sent before the body, so this is one extra read of the file
+                byte[]? contentMd5 = config.SendContentMd5 ? await ComputeMd5(filePath, ct) : null;
+
+                // Restarted every time a chunk is read, so it only fires once the transfer stops moving
+                var stallTimeout = config.UploadTimeoutSeconds > 0 ? TimeSpan.FromSeconds(config.UploadTimeoutSeconds) : Timeout.InfiniteTimeSpan;
+                using var stall = CancellationTokenSource.CreateLinkedTokenSource(ct);
+                stall.CancelAfter(stallTimeout);
+
+                // Per-file progress for the status window - the PUT is the 40-80% part of an upload
+                if (progress != null)
+                    progress.BytesSent = 0;
+                void OnChunkSent(int bytes)
+                {
+                    stall.CancelAfter(stallTimeout);
+                    throughput.Add(bytes);
+                    if (progress == null || progress.FileSizeBytes <= 0)
+                        return;
+                    progress.BytesSent += bytes;
+                    var percent = (int)Math.Min(100, progress.BytesSent * 100 / progress.FileSizeBytes);
+                    progress.ProgressPercent = 40 + percent * 40 / 100;
+                    progress.Status = $"Uploading... {percent}%";
+                }
+
+                try
+                {
+                    using var stream = await OpenFileForReading(filePath, Log, ct);
+                    using var throttledStream = new ThrottledStream(stream, bandwidth, leaveOpen: true, onRead: OnChunkSent);
+                    // Hash while streaming rather than reading the file twice
+                    using var hashingStream = new CryptoStream(throttledStream, md5, CryptoStreamMode.Read, leaveOpen: true);
+                    response = await storageUploader.PutAsync(uploadUrl, hashingStream, stream.Length, config.GetContentType(filePath), contentMd5, stall.Token);
+                }
+                catch (OperationCanceledException) when (stall.IsCancellationRequested && !ct.IsCancellationRequested)
+                {
+                    throw new IOException($"Upload stalled - nothing sent for {config.UploadTimeoutSeconds}s");
+                }
+                catch (HttpRequestException) when (!resentForChange && HasFileChanged(filePath, before))
+                {
+                    // Sending more or fewer bytes than ContentLength aborts the request
+                    Log($"{Path.GetFileName(filePath)} changed during upload - sending again", "WARN");
+                    resentForChange = true;
+                    continue;
+                }
+
+                if (!resentForChange && HasFileChanged(filePath, before))
+                {
+                    Log($"{Path.GetFileName(filePath)} changed during upload - sending again", "WARN");
+                    resentForChange = true;
+                    response.Dispose();
+                    continue;
+                }
+
+                if (PrintagoApiClient.IsRateLimitResponse(response) && rateLimitRetries < MAX_RATE_LIMIT_RETRIES)
+                {
+                    // The signed URL stays valid for a while, so the same one is used again. Storage limits are per
+                    // object, so only this file waits.
+                    var retryDelay = PrintagoApiClient.GetRateLimitDelay(response, rateLimitRetries);
+                    Log($"Storage rate limited (HTTP {(int)response.StatusCode}) {Path.GetFileName(filePath)}, retrying in {retryDelay.TotalSeconds}s (attempt {rateLimitRetries + 1}/{MAX_RATE_LIMIT_RETRIES})", "WARN");
+                    rateLimitRetries++;
+                    response.Dispose();
+                    await Task.Delay(retryDelay, ct);
+                    continue;
+                }
+
+                if (contentMd5 != null && !resentForChecksum && await IsChecksumRejected(response))
+                {
+                    Log($"Storage rejected the checksum of {Path.GetFileName(filePath)} (HTTP {(int)response.StatusCode}) - sending again", "WARN");
+                    resentForChecksum = true;
+                    response.Dispose();
+                    continue;
+                }
+
+                var storedMd5 = GetMd5ETag(response);
+                if (response.IsSuccessStatusCode && storedMd5 != null && md5.Hash != null)
+                {
+                    var sentMd5 = Convert.ToHexString(md5.Hash).ToLowerInvariant();
+                    if (storedMd5 != sentMd5)
+                    {
+                        response.Dispose();
+                        throw new IOException($"Checksum mismatch - storage has MD5 {storedMd5}, sent {sentMd5}");
+                    }
+                    Log($"Verified {Path.GetFileName(filePath)} (MD5 {sentMd5})", "DEBUG");
+                }
+
+                return response;
+            }
+        }
+
+        private async Task<byte[]> ComputeMd5(string filePath, CancellationToken ct)
+        {
+            using var md5 = MD5.Create();
+            using var stream = await OpenFileForReading(filePath, Log, ct);
+            return await md5.ComputeHashAsync(stream, ct);
+        }
+
+        /// <summary>
+        /// Open a file to hash or upload it. Shares read/write so a slicer that is still saving isn't blocked by us;
+        /// if the slicer (or an antivirus scan) has it locked, tries again a few times before giving up with the
+        /// last error - an IOException, which the upload retry backoff treats as transient.
+        /// </summary>
+        internal static async Task<FileStream> OpenFileForReading(string filePath, Action<string, string> log, CancellationToken ct)
+        {
+            for (int attempt = 1; ; attempt++)
+            {
+                try
+                {
+                    return new FileStream(filePath, FileMode.Open, FileAccess.Read, FileShare.ReadWrite | FileShare.Delete, 81920, useAsync: true);
+                }
+                catch (Exception ex) when (attempt < FILE_OPEN_ATTEMPTS && IsFileInUse(ex))
+                {
+                    log($"{Path.GetFileName(filePath)} is in use - trying again ({attempt}/{FILE_OPEN_ATTEMPTS - 1})", "DEBUG");
+                    await Task.Delay(FILE_OPEN_RETRY_MS * attempt, ct);
+                }
+            }
+        }
+
+        // Windows ERROR_SHARING_VIOLATION / ERROR_LOCK_VIOLATION; EBUSY / EAGAIN elsewhere. Access denied
+        // (EACCES) is included - on Windows it's also what a file that is still being created can give.
+        private static bool IsFileInUse(Exception ex)
+        {
+            if (ex is UnauthorizedAccessException)
+                return true;
+            if (ex is not IOException || ex is FileNotFoundException || ex is DirectoryNotFoundException)
+                return false;
+
+            var code = OperatingSystem.IsWindows() ? ex.HResult & 0xFFFF : ex.HResult;
+            return OperatingSystem.IsWindows() ? code is 32 or 33 : code is 16 or 11;
+        }
+
+        /// <summary>
+        /// S3 answers a Content-MD5 that doesn't match the body with 400 BadDigest, GCS with a 400 mentioning the MD5
+        /// </summary>
+        private static async Task<bool> IsChecksumRejected(HttpResponseMessage response)
+        {
+            if (response.StatusCode != System.Net.HttpStatusCode.BadRequest)
+                return false;
+
+            var body = await response.Content.ReadAsStringAsync();
+            return body.Contains("BadDigest", StringComparison.OrdinalIgnoreCase) ||
+                   body.Contains("InvalidDigest", StringComparison.OrdinalIgnoreCase) ||
+                   body.Contains("MD5", StringComparison.OrdinalIgnoreCase);
+        }
+
+        /// <summary>
+        /// The ETag of a stored object when it's a plain MD5 (S3, GCS and R2 single-part uploads), otherwise null -
+        /// multipart ETags ("...-3") and opaque ones can't be checked
+        /// </summary>
+        private static string? GetMd5ETag(HttpResponseMessage response)
+        {
+            var etag = response.Headers.ETag?.Tag?.Trim('"');
+            if (etag == null || etag.Length != 32 || !etag.All(Uri.IsHexDigit))
+                return null;
+
+            return etag.ToLowerInvariant();
+        }
+
+        private static bool HasFileChanged(string filePath, (long length, DateTime lastWriteUtc) before)
+        {
+            var after = new FileInfo(filePath);
+            return !after.Exists || after.Length != before.length || after.LastWriteTimeUtc != before.lastWriteUtc;
+        }
+
+        #endregion
+
+        private void Log(string message, string level)
+        {
+            OnLog?.Invoke(message, level);
+        }
+    }
+}
@@ -0,0 +1,176 @@
+using System;
+using System.Collections.Generic;
+using System.IO;
+using System.Linq;
+using System.Text;
+using System.Text.RegularExpressions;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Glob-based exclusion matcher for paths relative to the watch folder.
+    /// - "*" and "?" match within a single folder level
+    /// - "**" matches any number of folders (e.g. "**/drafts/**")
+    /// - Patterns without a "/" match a file or folder name at any depth (e.g. "*.tmp", "Thumbs.db")
+    /// - Excluding a folder excludes everything under it
+    /// </summary>
+    public class PathFilter
+    {
+        public const string IGNORE_FILE_NAME = ".printagoignore";
+
+        // Never synced: Office lock files, temp and backup copies, unfinished downloads, OS folder metadata
+        public static readonly string[] BUILT_IN_PATTERNS =
+        {
+            "~$*", "*.tmp", "*.bak", "*.crdownload", "*.partial", "Thumbs.db", ".DS_Store", "desktop.ini"
+        };
+
+        // Dotfiles and dot-folders (e.g. .~lock files), unless Config.UploadHiddenFiles
+        public const string HIDDEN_PATTERN = ".*";
+
+        // "model (1).3mf" - OneDrive conflict copies and duplicate saves
+        private static readonly Regex NumberedCopyRegex = new(@"^.+ \(\d+\)\..+$", RegexOptions.CultureInvariant);
+
+        private readonly List<(Regex regex, bool matchName)> patterns = new();
+
+        public PathFilter(IEnumerable<string> globs)
+        {
+            foreach (var glob in globs)
+            {
+                var pattern = glob.Trim().Replace('\\', '/');
+                if (string.IsNullOrEmpty(pattern) || pattern.StartsWith("#"))
+                    continue;
+
+                bool matchName = !pattern.TrimEnd('/').Contains('/');
+                patterns.Add((GlobToRegex(pattern.Trim('/')), matchName));
+            }
+        }
+
+        public bool IsEmpty => patterns.Count == 0;
+
+        /// <summary>
+        /// Build a filter from the configured patterns plus the .printagoignore file in the watch root (if any).
+        /// </summary>
+        public static PathFilter Load(string watchPath, IEnumerable<string>? configPatterns)
+        {
+            var globs = new List<string>(configPatterns ?? Enumerable.Empty<string>());
+
+            try
+            {
+                if (!string.IsNullOrWhiteSpace(watchPath))
+                {
+                    var ignoreFile = Path.Combine(watchPath, IGNORE_FILE_NAME);
+                    if (File.Exists(ignoreFile))
+                    {
+                        globs.AddRange(File.ReadAllLines(ignoreFile));
+                    }
+                }
+            }
+            catch (Exception ex)
+            {
+                System.Diagnostics.Debug.WriteLine($"Error reading {IGNORE_FILE_NAME}: {ex.Message}");
+            }
+
+            return new PathFilter(globs);
+        }
+
+        /// <summary>
+        /// Check a relative path (either separator). A path is excluded if it, or any folder above it, matches.
+        /// </summary>
+        public bool IsExcluded(string relativePath)
+        {
+            if (IsEmpty || string.IsNullOrEmpty(relativePath))
+                return false;
+
+            var segments = relativePath.Replace('\\', '/').Trim('/').Split('/', StringSplitOptions.RemoveEmptyEntries);
+            if (segments.Length == 0 || segments[0] == "..")
+                return false;
+
+            var prefix = "";
+            foreach (var segment in segments)
+            {
+                prefix = string.IsNullOrEmpty(prefix) ? segment : $"{prefix}/{segment}";
+
+                foreach (var (regex, matchName) in patterns)
+                {
+                    if (regex.IsMatch(matchName ? segment : prefix))
+                        return true;
+                }
+            }
+
+            return false;
+        }
+
+        /// <summary>
+        /// A copy made next to the original by a sync client or slicer: "model (1).3mf", or "model-PC01.3mf"
+        /// with this computer's name (OneDrive conflicts, PrusaSlicer autosaves)
+        /// </summary>
+        public static bool IsCopyName(string fileName, string machineName)
+        {
+            if (NumberedCopyRegex.IsMatch(fileName))
+                return true;
+
+            return !string.IsNullOrEmpty(machineName) &&
+                   Regex.IsMatch(fileName, $@"^.+-{Regex.Escape(machineName)}\..+$", RegexOptions.IgnoreCase | RegexOptions.CultureInvariant);
+        }
+
+        private static Regex GlobToRegex(string glob)
+        {
+            var sb = new StringBuilder("^");
+            int i = 0;
+
+            while (i < glob.Length)
+            {
+                var c = glob[i];
+
+                if (c == '*')
+                {
+                    if (i + 1 < glob.Length && glob[i + 1] == '*')
+                    {
+                        if (i + 2 < glob.Length && glob[i + 2] == '/')
+                        {
+                            sb.Append("(?:.*/)?"); // "**/" - zero or more folders
+                            i += 3;
+                        }
+                        else
+                        {
+                            sb.Append(".*");
+                            i += 2;
+                        }
+                    }
+                    else
+                    {
+                        sb.Append("[^/]*");
+                        i++;
+                    }
+                }
+                else if (c == '?')
+                {
+                    sb.Append("[^/]");
+                    i++;
+                }
+                else if (c == '/' && glob.Substring(i) == "/**")
+                {
+                    sb.Append("(?:/.*)?"); // Trailing "/**" - the folder itself and everything under it
+                    i += 3;
+                }
+                else if (c == '[' && glob.IndexOf(']', i + 1) > i + 1)
+                {
+                    var end = glob.IndexOf(']', i + 1);
+                    var set = glob.Substring(i + 1, end - i - 1);
+                    if (set.StartsWith("!"))
+                        set = "^" + set.Substring(1);
+                    sb.Append('[').Append(set.Replace("\\", "\\\\")).Append(']');
+                    i = end + 1;
+                }
+                else
+                {
+                    sb.Append(Regex.Escape(c.ToString()));
+                    i++;
+                }
+            }
+
+            sb.Append('$');
+            return new Regex(sb.ToString(), RegexOptions.IgnoreCase | RegexOptions.CultureInvariant);
+        }
+    }
+}
@@ -0,0 +1,322 @@
+using System;
+using System.Collections.Generic;
+using System.Linq;
+using System.Net.Http;
+using System.Text;
+using System.Text.RegularExpressions;
+using System.Threading;
+using System.Threading.Tasks;
+using Newtonsoft.Json;
+using Newtonsoft.Json.Linq;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// IPrintagoApiClient over an HttpClient. The client is passed in (and not disposed here), so a test can answer
+    /// for Printago with its own handler. The config is read on every request, so a reload (new key, URL or
+    /// RequestsPerSecond) applies straight away.
+    /// </summary>
+    public class PrintagoApiClient : IPrintagoApiClient
+    {
+        private const int MAX_RATE_LIMIT_RETRIES = 3;
+        private const int MAX_RATE_LIMIT_DELAY_SECONDS = 60; // Same cap as upload retries
+        private const int MAX_LOGGED_ERROR_BODY = 500; // Characters of a failed response's body written to the log
+
+        private readonly HttpClient httpClient;
+        private readonly Func<Config> getConfig;
+
+        // One request at a time, at most Config.RequestsPerSecond
+        private readonly SemaphoreSlim rateLimiter = new(1, 1);
+        private DateTime lastCallTime = DateTime.MinValue;
+
+        // UTC ticks until which the API asked us to slow down (429, or 503 with Retry-After)
+        private long rateLimitedUntilTicks;
+
+        public event Action<string, string>? OnLog;
+        public event Action<DateTime>? OnRateLimited;
+
+        public PrintagoApiClient(HttpClient httpClient, Func<Config> getConfig)
+        {
+            this.httpClient = httpClient;
+            this.getConfig = getConfig;
+        }
+
+        public bool IsRateLimited => DateTime.UtcNow.Ticks < Interlocked.Read(ref rateLimitedUntilTicks);
+
+        public DateTime RateLimitedUntilUtc => new(Interlocked.Read(ref rateLimitedUntilTicks), DateTimeKind.Utc);
+
+        public string GetUrl(string path)
+        {
+            var config = getConfig();
+            return $"{config.ApiUrl.TrimEnd('/')}{config.GetApiPath(path)}";
+        }
+
+        /// <summary>
+        /// API key and store ID headers every Printago API request needs (names from Config.ApiKeyHeader / StoreIdHeader)
+        /// </summary>
+        public void AddApiHeaders(HttpRequestMessage request)
+        {
+            var config = getConfig();
+            request.Headers.Add(config.ApiKeyHeader, config.GetApiKeyHeaderValue());
+            request.Headers.Add(config.StoreIdHeader, config.StoreId);
+        }
+
+        /// <summary>
+        /// Send an API request at most Config.RequestsPerSecond, one at a time. A 429 (or 503 with Retry-After)
+        /// pauses every request for the server's delay and the request is tried again, up to 3 times, so the
+        /// files it belongs to keep their place at the front.
+        /// </summary>
+        public async Task<HttpResponseMessage> SendAsync(HttpRequestMessage request, CancellationToken ct = default)
+        {
+            // Retries stay inside this one acquisition, ahead of the requests that queued up behind this one
+            await rateLimiter.WaitAsync(ct);
+            try
+            {
+                for (int retryCount = 0; ; retryCount++)
+                {
+                    var pause = RateLimitedUntilUtc - DateTime.UtcNow;
+                    if (pause > TimeSpan.Zero)
+                    {
+                        await Task.Delay(pause, ct);
+                    }
+
+                    var timeSinceLastCall = DateTime.UtcNow - lastCallTime;
+                    var minimumDelay = getConfig().GetMinimumApiInterval();
+                    if (timeSinceLastCall < minimumDelay)
+                    {
+                        await Task.Delay(minimumDelay - timeSinceLastCall, ct);
+                    }
+
+                    var response = await httpClient.SendAsync(request, ct);
+                    lastCallTime = DateTime.UtcNow;
+
+                    if (!IsRateLimitResponse(response) || retryCount >= MAX_RATE_LIMIT_RETRIES)
+                    {
+                        return response;
+                    }
+
+                    var retryDelay = GetRateLimitDelay(response, retryCount);
+                    var statusCode = (int)response.StatusCode;
+                    response.Dispose();
+                    PauseForRateLimit(retryDelay);
+                    OnLog?.Invoke($"Rate limited (HTTP {statusCode}), retrying in {retryDelay.TotalSeconds}s (attempt {retryCount + 1}/{MAX_RATE_LIMIT_RETRIES})", "WARN");
+
+                    // A request message can only be sent once. The pause itself is waited out at the top of the loop.
+                    var retryRequest = new HttpRequestMessage(request.Method, request.RequestUri)
+                    {
+                        Content = request.Content
+                    };
+                    foreach (var header in request.Headers)
+                    {
+                        retryRequest.Headers.TryAddWithoutValidation(header.Key, header.Value);
+                    }
+                    request = retryRequest;
+                }
+            }
+            finally
+            {
+                rateLimiter.Release();
+            }
+        }
+
+        /// <summary>
+        /// One signed-upload-urls request for several files. The result is keyed by the requested filename;
+        /// entries are matched by name/path rather than by position, since the response order isn't guaranteed.
+        /// </summary>
+        public async Task<Dictionary<string, (string uploadUrl, string storagePath)>?> GetSignedUploadUrlsAsync(
+            IReadOnlyList<string> cloudPaths, CancellationToken ct = default)
+        {
+            try
+            {
+                var requestBody = new { filenames = cloudPaths };
+                using var request = new HttpRequestMessage(HttpMethod.Post, GetUrl(getConfig().SignedUploadUrlsPath))
+                {
+                    Content = new StringContent(JsonConvert.SerializeObject(requestBody), Encoding.UTF8, "application/json")
+                };
+                AddApiHeaders(request);
+
+                using var response = await SendAsync(request, ct);
+                if (!response.IsSuccessStatusCode)
+                {
+                    // Throw with the status so callers can tell a bad key (pause) from an outage (retry)
+                    var (description, error) = await ReadErrorResponse(response);
+                    OnLog?.Invoke($"Signed URL request failed: {error}", "ERROR");
+                    throw new HttpRequestException($"Signed URL request failed: {description}", null, response.StatusCode);
+                }
+
+                var json = await response.Content.ReadAsStringAsync();
+                var signedUrls = JObject.Parse(json)["signedUrls"] as JArray;
+                if (signedUrls == null || signedUrls.Count == 0)
+                    return null;
+
+                return MatchSignedUrls(cloudPaths, signedUrls);
+            }
+            catch (JsonException)
+            {
+                return null;
+            }
+        }
+
+        /// <summary>
+        /// Pair each requested cloud path with its entry in a signed-upload-urls response: by the filename echoed
+        /// back, else by the storage path ending with it. Paths that can't be identified are left out - only a
+        /// single leftover path and entry are paired up, since in a batch the response order says nothing.
+        /// </summary>
+        internal static Dictionary<string, (string uploadUrl, string storagePath)> MatchSignedUrls(
+            IReadOnlyList<string> cloudPaths, JArray signedUrls)
+        {
+            var entries = signedUrls
+                .Select(item => (
+                    filename: item.Value<string>("filename") ?? item.Value<string>("fileName"),
+                    uploadUrl: item.Value<string>("uploadUrl") ?? "",
+                    path: item.Value<string>("path") ?? ""))
+                .ToList();
+
+            var result = new Dictionary<string, (string uploadUrl, string storagePath)>();
+            var unmatched = new List<string>();
+
+            foreach (var cloudPath in cloudPaths)
+            {
+                var match = entries.FirstOrDefault(e => e.filename == cloudPath);
+                if (string.IsNullOrEmpty(match.uploadUrl))
+                {
+                    // No filename echoed back - the storage path ends with the name we asked for
+                    match = entries
+                        .Where(e => e.path.EndsWith("/" + cloudPath) || e.path == cloudPath)
+                        .OrderBy(e => e.path.Length)
+                        .FirstOrDefault();
+                }
+
+                if (!string.IsNullOrEmpty(match.uploadUrl))
+                {
+                    result[cloudPath] = (match.uploadUrl, match.path);
+                    entries.Remove(match);
+                }
+                else
+                {
+                    unmatched.Add(cloudPath);
+                }
+            }
+
+            // One path and one URL left over (e.g. a single-file request answered without a filename) can only belong together
+            if (unmatched.Count == 1 && entries.Count == 1)
+            {
+                result[unmatched[0]] = (entries[0].uploadUrl, entries[0].path);
+            }
+
+            return result;
+        }
+
+        public async Task<(string description, string logText)> ReadErrorResponse(HttpResponseMessage response)
+        {
+            var description = $"HTTP {(int)response.StatusCode}";
+            string body;
+            try
+            {
+                body = Redact(Regex.Replace(await response.Content.ReadAsStringAsync(), @"\s+", " ").Trim());
+            }
+            catch (Exception)
+            {
+                return (description, description);
+            }
+
+            if (body.Length == 0)
+                return (description, description);
+
+            var detail = ParseErrorMessage(body);
+            if (!string.IsNullOrWhiteSpace(detail))
+                description = $"{description} - {detail}";
+
+            // A plain-text body is already all in the description
+            if (detail == body)
+                return (description, description);
+
+            var loggedBody = body.Length > MAX_LOGGED_ERROR_BODY ? body.Substring(0, MAX_LOGGED_ERROR_BODY) + "…" : body;
+            return (description, $"{description} (response: {loggedBody})");
+        }
+
+        /// <summary>
+        /// The message in an error body: the message/error field of JSON, or the Message/Code of a storage XML error.
+        /// The start of a plain-text body; null for an HTML error page or nothing usable.
+        /// </summary>
+        internal static string? ParseErrorMessage(string body)
+        {
+            try
+            {
+                if (body.StartsWith("{"))
+                {
+                    var json = JObject.Parse(body);
+                    var error = json["message"] ?? json["error"];
+                    // e.g. { "error": { "message": "..." } }
+                    return error is JObject nested ? (string?)(nested["message"] ?? nested["code"]) : (string?)error;
+                }
+
+                if (body.StartsWith("<"))
+                {
+                    // e.g. <Error><Code>SignatureDoesNotMatch</Code><Message>...</Message></Error> from storage - the
+                    // Message wherever it is, the Code if there isn't one
+                    var match = Regex.Match(body, "<Message>(.*?)</Message>");
+                    if (!match.Success)
+                        match = Regex.Match(body, "<Code>(.*?)</Code>");
+                    return match.Success ? match.Groups[1].Value : null;
+                }
+
+                return body.Length > 200 ? body.Substring(0, 200) + "…" : body;
+            }
+            catch (Exception)
+            {
+                return null;
+            }
+        }
+
+        /// <summary>
+        /// 429, or a 503 that says when to come back - the server is shedding load rather than down
+        /// </summary>
+        internal static bool IsRateLimitResponse(HttpResponseMessage response)
+        {
+            return response.StatusCode == System.Net.HttpStatusCode.TooManyRequests ||
+                   (response.StatusCode == System.Net.HttpStatusCode.ServiceUnavailable && response.Headers.RetryAfter != null);
+        }
+
+        /// <summary>
+        /// How long to wait after a 429: the server's Retry-After (seconds or an HTTP date) capped like upload
+        /// retries, or 4s, 8s, 16s... when it doesn't send one
+        /// </summary>
+        internal static TimeSpan GetRateLimitDelay(HttpResponseMessage response, int retryCount)
+        {
+            var retryAfter = response.Headers.RetryAfter?.Delta
+                ?? (response.Headers.RetryAfter?.Date is DateTimeOffset retryAt ? retryAt - DateTimeOffset.UtcNow : null);
+            return retryAfter is TimeSpan serverDelay && serverDelay > TimeSpan.Zero
+                ? TimeSpan.FromSeconds(Math.Min(serverDelay.TotalSeconds, MAX_RATE_LIMIT_DELAY_SECONDS))
+                : TimeSpan.FromSeconds(Math.Min(Math.Pow(2, retryCount + 2), MAX_RATE_LIMIT_DELAY_SECONDS));
+        }
+
+        /// <summary>
+        /// Hold off every request for delay (extending a pause already running). OnRateLimited is only raised
+        /// for the start of a pause, however many requests were turned away.
+        /// </summary>
+        private void PauseForRateLimit(TimeSpan delay)
+        {
+            var until = DateTime.UtcNow.Add(delay).Ticks;
+            long previous;
+            do
+            {
+                previous = Interlocked.Read(ref rateLimitedUntilTicks);
+                if (previous >= until)
+                    return;
+            }
+            while (Interlocked.CompareExchange(ref rateLimitedUntilTicks, until, previous) != previous);
+
+            if (previous <= DateTime.UtcNow.Ticks)
+            {
+                OnRateLimited?.Invoke(new DateTime(until, DateTimeKind.Utc));
+            }
+        }
+
+        private string Redact(string message)
+        {
+            var apiKey = getConfig()?.ApiKey;
+            return string.IsNullOrEmpty(apiKey) ? message : message.Replace(apiKey, "****");
+        }
+    }
+}
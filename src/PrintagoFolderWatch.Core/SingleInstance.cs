@@ -0,0 +1,55 @@
+using System;
+using System.Security.Cryptography;
+using System.Text;
+using System.Threading;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// One running watcher per config file, so two instances don't upload every file twice. Held through a named
+    /// mutex, which the OS releases when the process exits - a crashed instance never leaves a stale lock behind.
+    /// </summary>
+    public sealed class SingleInstance : IDisposable
+    {
+        private const string MUTEX_NAME = "PrintagoFolderWatch_SingleInstance_8F4C3D2E";
+
+        private readonly Mutex mutex;
+
+        private SingleInstance(Mutex mutex)
+        {
+            this.mutex = mutex;
+        }
+
+        /// <summary>
+        /// Null if another instance is already running with this config file (null = the default one).
+        /// A different --config gets its own instance.
+        /// </summary>
+        public static SingleInstance? TryAcquire(string? configPath)
+        {
+            var name = configPath == null
+                ? MUTEX_NAME
+                : $"{MUTEX_NAME}_{Convert.ToHexString(SHA256.HashData(Encoding.UTF8.GetBytes(configPath.ToLowerInvariant())))[..16]}";
+            var mutex = new Mutex(false, name);
+
+            try
+            {
+                if (mutex.WaitOne(0))
+                    return new SingleInstance(mutex);
+            }
+            catch (AbandonedMutexException)
+            {
+                // The previous instance died without releasing it - ours now
+                return new SingleInstance(mutex);
+            }
+
+            mutex.Dispose();
+            return null;
+        }
+
+        public void Dispose()
+        {
+            mutex.ReleaseMutex();
+            mutex.Dispose();
+        }
+    }
+}
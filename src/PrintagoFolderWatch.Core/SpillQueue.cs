@@ -0,0 +1,213 @@
+using System;
+using System.Collections.Generic;
+using System.IO;
+using System.Text;
+using Newtonsoft.Json;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// FIFO queue of paths that never refuses or waits on an Enqueue: up to a limit are kept in memory and the rest
+    /// are appended to a journal file, read back in order as the memory part drains. The limit is read on every
+    /// call, so a reloaded config applies straight away. The journal only lives as long as the queue - a restart
+    /// rebuilds the queue with its initial sync - so one left by a crash is overwritten by the next spill.
+    /// </summary>
+    public class SpillQueue : IDisposable
+    {
+        private static readonly Encoding JournalEncoding = new UTF8Encoding(false);
+
+        private readonly string journalPath;
+        private readonly Func<int> getMemoryLimit;
+        private readonly Action<string>? onError;
+        private readonly object sync = new();
+
+        // Oldest first: memory, then the journal, then overflow (only used once the journal couldn't be written)
+        private readonly Queue<string> memory = new();
+        private readonly Queue<string> overflow = new();
+        private int spilledCount;
+        private StreamWriter? journalWriter;
+        private StreamReader? journalReader;
+        private bool journalFailed;
+
+        public SpillQueue(string journalPath, Func<int> getMemoryLimit, Action<string>? onError = null)
+        {
+            this.journalPath = journalPath;
+            this.getMemoryLimit = getMemoryLimit;
+            this.onError = onError;
+        }
+
+        public int Count
+        {
+            get
+            {
+                lock (sync)
+                {
+                    return memory.Count + spilledCount + overflow.Count;
+                }
+            }
+        }
+
+        /// <summary>
+        /// Entries queued behind the in-memory ones: in the journal (or, if it couldn't be written, set aside)
+        /// </summary>
+        public int SpilledCount
+        {
+            get
+            {
+                lock (sync)
+                {
+                    return spilledCount + overflow.Count;
+                }
+            }
+        }
+
+        public void Enqueue(string item)
+        {
+            lock (sync)
+            {
+                // Once anything has spilled, later entries queue behind it on disk to keep the order
+                if (spilledCount == 0 && overflow.Count == 0 && memory.Count < Math.Max(getMemoryLimit(), 1))
+                {
+                    memory.Enqueue(item);
+                }
+                else if (overflow.Count == 0 && !journalFailed && TryAppendToJournal(item))
+                {
+                    spilledCount++;
+                }
+                else
+                {
+                    overflow.Enqueue(item);
+                }
+            }
+        }
+
+        public bool TryDequeue(out string item)
+        {
+            lock (sync)
+            {
+                if (memory.Count == 0)
+                {
+                    Refill();
+                }
+
+                return memory.TryDequeue(out item!);
+            }
+        }
+
+        /// <summary>
+        /// The entries in memory, in order - the next ones out. SpilledCount more follow them.
+        /// </summary>
+        public List<string> PeekInMemory()
+        {
+            lock (sync)
+            {
+                return new List<string>(memory);
+            }
+        }
+
+        private bool TryAppendToJournal(string item)
+        {
+            try
+            {
+                if (journalWriter == null)
+                {
+                    Directory.CreateDirectory(Path.GetDirectoryName(journalPath)!);
+                    var stream = new FileStream(journalPath, FileMode.Create, FileAccess.Write, FileShare.ReadWrite | FileShare.Delete);
+                    journalWriter = new StreamWriter(stream, JournalEncoding);
+                }
+
+                // JSON-quoted, so a line break in a path can't split an entry
+                journalWriter.Write(JsonConvert.SerializeObject(item));
+                journalWriter.Write('\n');
+                return true;
+            }
+            catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+            {
+                // Kept in memory from now on - slower to fill up than to lose files
+                journalFailed = true;
+                onError?.Invoke($"Couldn't write the queue journal {journalPath}: {ex.Message} - keeping the rest of the queue in memory");
+                return false;
+            }
+        }
+
+        /// <summary>
+        /// Move the next batch (up to the memory limit) from the journal into memory - or, once the journal
+        /// is empty, whatever went to overflow
+        /// </summary>
+        private void Refill()
+        {
+            if (spilledCount > 0)
+            {
+                try
+                {
+                    // Every write is a whole line, so after a flush the reader never sees half an entry
+                    journalWriter?.Flush();
+                    journalReader ??= new StreamReader(
+                        new FileStream(journalPath, FileMode.Open, FileAccess.Read, FileShare.ReadWrite | FileShare.Delete),
+                        JournalEncoding);
+
+                    var limit = Math.Max(getMemoryLimit(), 1);
+                    while (spilledCount > 0 && memory.Count < limit)
+                    {
+                        var line = journalReader.ReadLine();
+                        if (line == null)
+                            throw new IOException("the journal ended early");
+
+                        memory.Enqueue(JsonConvert.DeserializeObject<string>(line) ?? "");
+                        spilledCount--;
+                    }
+                }
+                catch (Exception ex) when (ex is IOException or UnauthorizedAccessException or JsonException)
+                {
+                    onError?.Invoke($"Couldn't read the queue journal {journalPath}: {ex.Message} - {spilledCount} queued file(s) dropped");
+                    spilledCount = 0;
+                }
+
+                if (spilledCount == 0)
+                {
+                    DeleteJournal();
+                }
+            }
+
+            if (spilledCount == 0 && memory.Count == 0)
+            {
+                while (overflow.TryDequeue(out var item))
+                {
+                    memory.Enqueue(item);
+                }
+            }
+        }
+
+        /// <summary>
+        /// Close and remove the journal once it's been read to the end. Nothing to do if this queue never spilled,
+        /// so an instance that's only uploading a few files never touches another one's journal.
+        /// </summary>
+        private void DeleteJournal()
+        {
+            if (journalWriter == null)
+                return;
+
+            journalReader?.Dispose();
+            journalReader = null;
+            journalWriter.Dispose();
+            journalWriter = null;
+
+            try
+            {
+                File.Delete(journalPath);
+            }
+            catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+            {
+                // Truncated when the next spill creates it again
+            }
+        }
+
+        public void Dispose()
+        {
+            lock (sync)
+            {
+                DeleteJournal();
+            }
+        }
+    }
+}
@@ -0,0 +1,56 @@
+using System;
+
+namespace PrintagoFolderWatch.Core
+{
+    /// <summary>
+    /// Rolling upload speed across every upload worker: bytes are counted in one-second buckets and the
+    /// speed is the average over the last WINDOW_SECONDS (or since sending started, if that's more recent).
+    /// Nothing sent for the whole window reads as 0.
+    /// </summary>
+    public class ThroughputMeter
+    {
+        private const int WINDOW_SECONDS = 20;
+
+        private readonly object sync = new();
+        private readonly long[] bucketBytes = new long[WINDOW_SECONDS];
+        private readonly long[] bucketSecond = new long[WINDOW_SECONDS];
+
+        public void Add(long bytes)
+        {
+            var second = Environment.TickCount64 / 1000;
+            var index = (int)(second % WINDOW_SECONDS);
+            lock (sync)
+            {
+                if (bucketSecond[index] != second)
+                {
+                    bucketSecond[index] = second;
+                    bucketBytes[index] = 0;
+                }
+                bucketBytes[index] += bytes;
+            }
+        }
+
+        public double BytesPerSecond
+        {
+            get
+            {
+                var now = Environment.TickCount64 / 1000;
+                long total = 0;
+                var oldest = now;
+                lock (sync)
+                {
+                    for (int i = 0; i < WINDOW_SECONDS; i++)
+                    {
+                        if (bucketBytes[i] == 0 || now - bucketSecond[i] >= WINDOW_SECONDS)
+                            continue;
+                        total += bucketBytes[i];
+                        oldest = Math.Min(oldest, bucketSecond[i]);
+                    }
+                }
+
+                // The current second is only partly over, so it counts as a whole one
+                return total / (double)(now - oldest + 1);
+            }
+        }
+    }
+}
@@ -0,0 +1,12 @@
+namespace PrintagoFolderWatch.Core
+{
+    internal enum UploadResult
+    {
+        Success,
+        Skipped,
+        TransientFailure,
+        PermanentFailure,
+        AuthFailure, // API key or store rejected - waits for the settings to be fixed instead of counting as an attempt
+        NetworkFailure // No connection at all - checked against the API host, and waits while offline
+    }
+}
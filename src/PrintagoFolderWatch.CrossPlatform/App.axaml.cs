@@ -1,12 +1,15 @@
 using System;
 using System.Diagnostics;
 using System.IO;
+using System.Linq;
 using System.Threading.Tasks;
 using Avalonia;
 using Avalonia.Controls;
 using Avalonia.Controls.ApplicationLifetimes;
 using Avalonia.Markup.Xaml;
+using Avalonia.Threading;
 using PrintagoFolderWatch.Core;
+using PrintagoFolderWatch.Core.Models;
 using PrintagoFolderWatch.CrossPlatform.Views;
 
 namespace PrintagoFolderWatch.CrossPlatform;
@@ -23,8 +26,27 @@ public partial class App : Application
     private TrayIcon? _trayIcon;
     private NativeMenuItem? _startMenuItem;
     private NativeMenuItem? _stopMenuItem;
+    private NativeMenuItem? _pauseMenuItem;
+    private NativeMenuItem? _activityMenuItem;
+    private NativeMenuItem? _recentUploadsMenuItem;
+    private string? _shownRecentUploads; // Recent Uploads entries the submenu was last built from
+    private NativeMenuItem? _failedUploadsMenuItem;
+    private string? _shownFailedUploads; // Same for Failed Uploads
+    private NativeMenuItem? _settingsMenuItem;
     private NativeMenuItem? _syncNowMenuItem;
+    private NativeMenuItem? _forceResyncMenuItem;
+    private NativeMenuItem? _exitMenuItem;
+    private bool _exiting; // A second Exit quits without waiting for uploads
     private CrossPlatformUpdateChecker? _updateChecker;
+    private DispatcherTimer? _trayUpdateTimer;
+    private string? _lastNotification; // Shown in the tooltip when there's no desktop notification service
+
+    // Tray icon per status (the badged ones fall back to the plain icon if their file is missing)
+    private WindowIcon? _idleIcon;
+    private WindowIcon? _uploadingIcon;
+    private WindowIcon? _errorIcon;
+    private WindowIcon? _pausedIcon;
+    private WatcherStatus? _shownStatus;
 
     public override void Initialize()
     {
@@ -47,18 +69,51 @@ public partial class App : Application
                     _logsWindow?.AddLog(message, level);
                 });
             };
+            _watcherService.OnConfigReloaded += (success, message) =>
+            {
+                Avalonia.Threading.Dispatcher.UIThread.Post(() => OnConfigReloaded(success, message));
+            };
+            _watcherService.OnNotification += (title, message, isError) =>
+            {
+                Avalonia.Threading.Dispatcher.UIThread.Post(() => ShowDesktopNotification(title, message, isError));
+            };
+
+            if (_watcherService.Config.PauseOnStart)
+                _watcherService.SetUploadsPaused(true);
 
             // Create tray icon programmatically
             CreateTrayIcon();
 
+            // Keep the icon and tooltip current (queue depth and retry/backoff state change without any menu action).
+            // Polling once a second also throttles updates during an initial sync that queues thousands of files.
+            _trayUpdateTimer = new DispatcherTimer { Interval = TimeSpan.FromSeconds(1) };
+            _trayUpdateTimer.Tick += (s, e) =>
+            {
+                UpdateTrayStatus();
+                UpdateMenuState(); // Start Watching is re-enabled as soon as the config is fixed
+            };
+            _trayUpdateTimer.Start();
+
             // Initialize update checker
             _updateChecker = new CrossPlatformUpdateChecker(VERSION);
 
             // Auto-start if configured
-            if (_watcherService.Config.IsValid())
+            var configProblems = _watcherService.Config.Validate();
+            if (configProblems.Count == 0)
             {
                 _ = StartWatchingAsync();
             }
+            else if (Config.IsFirstRun)
+            {
+                // Straight to the settings rather than a list of everything that's missing
+                UpdateMenuState();
+                ShowSettingsWindow();
+            }
+            else
+            {
+                UpdateMenuState();
+                ShowMessage("Check Your Settings", string.Join("\n", configProblems));
+            }
 
             // Check for updates on startup (delayed)
             _ = CheckForUpdatesOnStartupAsync();
@@ -90,7 +145,15 @@ public partial class App : Application
             Debug.WriteLine($"Failed to load icon: {ex.Message}");
         }
 
+        _idleIcon = icon;
+        _uploadingIcon = LoadTrayIcon("icon-uploading.ico") ?? icon;
+        _errorIcon = LoadTrayIcon("icon-error.ico") ?? icon;
+        _pausedIcon = LoadTrayIcon("icon-paused.ico") ?? icon;
+
         // Create menu items
+        // Live queue/upload counts - not clickable, refreshed with the tooltip
+        _activityMenuItem = new NativeMenuItem("Not watching") { IsEnabled = false };
+
         var showStatusItem = new NativeMenuItem("Show Status");
         showStatusItem.Click += (s, e) => ShowStatusWindow();
 
@@ -100,12 +163,43 @@ public partial class App : Application
         _stopMenuItem = new NativeMenuItem("Stop Watching") { IsEnabled = false };
         _stopMenuItem.Click += (s, e) => StopWatching();
 
-        var settingsItem = new NativeMenuItem("Settings...");
-        settingsItem.Click += (s, e) => ShowSettingsWindow();
+        // Keeps watching and queuing - only stops new uploads from starting
+        _pauseMenuItem = new NativeMenuItem("Pause Uploads");
+        _pauseMenuItem.Click += (s, e) =>
+        {
+            if (_watcherService == null)
+                return;
+
+            _watcherService.SetUploadsPaused(!_watcherService.UploadsPaused);
+            _pauseMenuItem.Header = _watcherService.UploadsPaused ? "Resume Uploads" : "Pause Uploads";
+            UpdateTrayStatus();
+        };
+
+        _recentUploadsMenuItem = new NativeMenuItem("Recent Uploads") { Menu = new NativeMenu() };
+        _recentUploadsMenuItem.Menu.Items.Add(new NativeMenuItem("No uploads yet") { IsEnabled = false });
+
+        _failedUploadsMenuItem = new NativeMenuItem("Failed Uploads (0)") { Menu = new NativeMenu() };
+        _failedUploadsMenuItem.Menu.Items.Add(new NativeMenuItem("No failed uploads") { IsEnabled = false });
+
+        // "Setup..." until the required settings are filled in
+        _settingsMenuItem = new NativeMenuItem("Settings...");
+        _settingsMenuItem.Click += (s, e) => ShowSettingsWindow();
+
+        var reloadConfigItem = new NativeMenuItem("Reload Config");
+        reloadConfigItem.Click += async (s, e) => await ReloadConfigAsync();
+
+        var testConnectionItem = new NativeMenuItem("Test Connection");
+        testConnectionItem.Click += async (s, e) => await TestConnectionAsync();
 
         var logsItem = new NativeMenuItem("View Logs...");
         logsItem.Click += (s, e) => ShowLogsWindow();
 
+        var openLogFileItem = new NativeMenuItem("Open Log File");
+        openLogFileItem.Click += (s, e) => OpenLogFile();
+
+        var openConfigFileItem = new NativeMenuItem("Open Config File");
+        openConfigFileItem.Click += (s, e) => OpenConfigFile();
+
         _syncNowMenuItem = new NativeMenuItem("Sync Now") { IsEnabled = false };
         _syncNowMenuItem.Click += async (s, e) =>
         {
@@ -113,30 +207,46 @@ public partial class App : Application
                 await _watcherService.TriggerSyncNow();
         };
 
+        _forceResyncMenuItem = new NativeMenuItem("Force Full Re-sync") { IsEnabled = false };
+        _forceResyncMenuItem.Click += async (s, e) =>
+        {
+            if (_watcherService != null && _isRunning)
+                await _watcherService.ForceFullResync();
+        };
+
         var checkUpdatesItem = new NativeMenuItem("Check for Updates...");
         checkUpdatesItem.Click += async (s, e) => await CheckForUpdatesAsync(showNotification: true);
 
         var aboutItem = new NativeMenuItem("About...");
         aboutItem.Click += (s, e) => ShowAboutWindow();
 
-        var exitItem = new NativeMenuItem("Exit");
-        exitItem.Click += (s, e) => ExitApp();
+        _exitMenuItem = new NativeMenuItem("Exit");
+        _exitMenuItem.Click += (s, e) => ExitApp();
 
         // Build menu
         var menu = new NativeMenu();
+        menu.Items.Add(_activityMenuItem);
         menu.Items.Add(showStatusItem);
         menu.Items.Add(new NativeMenuItemSeparator());
         menu.Items.Add(_startMenuItem);
         menu.Items.Add(_stopMenuItem);
+        menu.Items.Add(_pauseMenuItem);
+        menu.Items.Add(_recentUploadsMenuItem);
+        menu.Items.Add(_failedUploadsMenuItem);
         menu.Items.Add(new NativeMenuItemSeparator());
-        menu.Items.Add(settingsItem);
+        menu.Items.Add(_settingsMenuItem);
+        menu.Items.Add(reloadConfigItem);
+        menu.Items.Add(testConnectionItem);
         menu.Items.Add(logsItem);
+        menu.Items.Add(openLogFileItem);
+        menu.Items.Add(openConfigFileItem);
         menu.Items.Add(_syncNowMenuItem);
+        menu.Items.Add(_forceResyncMenuItem);
         menu.Items.Add(new NativeMenuItemSeparator());
         menu.Items.Add(checkUpdatesItem);
         menu.Items.Add(aboutItem);
         menu.Items.Add(new NativeMenuItemSeparator());
-        menu.Items.Add(exitItem);
+        menu.Items.Add(_exitMenuItem);
 
         // Create tray icon
         _trayIcon = new TrayIcon
@@ -160,49 +270,338 @@ public partial class App : Application
         Debug.WriteLine("Tray icon created and registered");
     }
 
-    private void UpdateTrayTooltip()
+    private void UpdateTrayStatus()
     {
         if (_trayIcon != null)
         {
-            var status = _isRunning ? "Running" : "Stopped";
-            _trayIcon.ToolTipText = $"Printago Folder Watch v{VERSION} - {status}";
+            var status = _watcherService?.Status ?? WatcherStatus.Stopped;
+            if (status != _shownStatus)
+            {
+                var icon = status switch
+                {
+                    WatcherStatus.Uploading => _uploadingIcon,
+                    WatcherStatus.Error => _errorIcon,
+                    WatcherStatus.Paused => _pausedIcon,
+                    _ => _idleIcon
+                };
+                if (icon != null)
+                    _trayIcon.Icon = icon;
+                _shownStatus = status;
+            }
+
+            var activity = _watcherService?.GetActivitySummary() ?? "Not watching";
+            if (_activityMenuItem != null && _activityMenuItem.Header != activity)
+                _activityMenuItem.Header = activity;
+
+            var pauseHeader = _watcherService?.UploadsPaused == true ? "Resume Uploads" : "Pause Uploads";
+            if (_pauseMenuItem != null && _pauseMenuItem.Header != pauseHeader)
+                _pauseMenuItem.Header = pauseHeader;
+
+            UpdateRecentUploadsMenu();
+            UpdateFailedUploadsMenu();
+
+            var summary = _watcherService?.GetStatusSummary() ?? "Stopped";
+            var text = _lastNotification != null
+                ? $"Printago Folder Watch v{VERSION}\n{summary}\n{_lastNotification}"
+                : $"Printago Folder Watch v{VERSION}\n{summary}";
+
+            if (_trayIcon.ToolTipText != text)
+                _trayIcon.ToolTipText = text;
+        }
+    }
+
+    /// <summary>
+    /// Rebuild the Recent Uploads submenu when an upload has finished since the last tick.
+    /// Uploaded: click shows and copies its Printago path. Failed: click queues it again.
+    /// </summary>
+    private void UpdateRecentUploadsMenu()
+    {
+        if (_recentUploadsMenuItem?.Menu == null || _watcherService == null)
+            return;
+
+        var recent = _watcherService.GetRecentUploads();
+        var key = string.Join("|", recent.Select(u => $"{u.FilePath}:{u.FinishedAt.Ticks}"));
+        if (key == _shownRecentUploads)
+            return;
+        _shownRecentUploads = key;
+
+        var items = _recentUploadsMenuItem.Menu.Items;
+        items.Clear();
+        if (recent.Count == 0)
+        {
+            items.Add(new NativeMenuItem("No uploads yet") { IsEnabled = false });
+            return;
+        }
+
+        foreach (var upload in recent)
+        {
+            var item = new NativeMenuItem($"{(upload.Success ? "✓" : "✗")} {upload.FileName}  {upload.FinishedAt:HH:mm}");
+            item.Click += async (s, e) =>
+            {
+                if (upload.Success)
+                {
+                    // A tray-only app has no window to reach the clipboard through - the message window is one
+                    var dialog = ShowMessage("Printago Path", $"{upload.CloudPath}\n(copied to the clipboard)");
+                    if (dialog.Clipboard != null)
+                        await dialog.Clipboard.SetTextAsync(upload.CloudPath);
+                }
+                else if (!_watcherService.RetryUpload(upload.FilePath))
+                {
+                    ShowMessage("Can't Retry", $"{upload.FileName}: start watching first, or the file is gone");
+                }
+            };
+            items.Add(item);
+        }
+    }
+
+    /// <summary>
+    /// Rebuild the Failed Uploads submenu when the list has changed.
+    /// Click a file to queue it again, or Retry All Failed for the whole list.
+    /// </summary>
+    private void UpdateFailedUploadsMenu()
+    {
+        if (_failedUploadsMenuItem?.Menu == null || _watcherService == null)
+            return;
+
+        var failed = _watcherService.GetFailedUploads();
+        var key = string.Join("|", failed.Select(f => $"{f.FilePath}:{f.FailedAt.Ticks}"));
+        if (key == _shownFailedUploads)
+            return;
+        _shownFailedUploads = key;
+
+        _failedUploadsMenuItem.Header = $"Failed Uploads ({failed.Count})";
+
+        var items = _failedUploadsMenuItem.Menu.Items;
+        items.Clear();
+        if (failed.Count == 0)
+        {
+            items.Add(new NativeMenuItem("No failed uploads") { IsEnabled = false });
+            return;
+        }
+
+        foreach (var upload in failed)
+        {
+            var status = upload.HttpStatus is int code ? $"HTTP {code}" : "no response";
+            var item = new NativeMenuItem($"{Path.GetFileName(upload.FilePath)} ({status})");
+            item.Click += (s, e) =>
+            {
+                if (!_watcherService.RetryUpload(upload.FilePath))
+                    ShowMessage("Can't Retry", $"{Path.GetFileName(upload.FilePath)}: start watching first, or the file is gone");
+            };
+            items.Add(item);
+        }
+
+        var retryAllItem = new NativeMenuItem("Retry All Failed");
+        retryAllItem.Click += (s, e) =>
+        {
+            if (!_watcherService.IsRunning)
+            {
+                ShowMessage("Can't Retry", "Start watching first");
+                return;
+            }
+            _watcherService.RetryAllFailed();
+        };
+        items.Add(new NativeMenuItemSeparator());
+        items.Add(retryAllItem);
+    }
+
+    private static WindowIcon? LoadTrayIcon(string fileName)
+    {
+        try
+        {
+            var iconPath = Path.Combine(AppDomain.CurrentDomain.BaseDirectory, fileName);
+            return File.Exists(iconPath) ? new WindowIcon(iconPath) : null;
+        }
+        catch (Exception ex)
+        {
+            Debug.WriteLine($"Failed to load {fileName}: {ex.Message}");
+            return null;
         }
     }
 
     private void UpdateMenuState()
     {
+        var configValid = _watcherService?.Config.IsValid() == true;
         if (_startMenuItem != null)
-            _startMenuItem.IsEnabled = !_isRunning;
+            // Also not while the API refuses the key or store (until Test Connection or a reload succeeds)
+            _startMenuItem.IsEnabled = !_isRunning && configValid && _watcherService?.CredentialsRejectedReason == null;
+        var settingsHeader = configValid ? "Settings..." : "Setup...";
+        if (_settingsMenuItem != null && _settingsMenuItem.Header != settingsHeader)
+            _settingsMenuItem.Header = settingsHeader;
         if (_stopMenuItem != null)
             _stopMenuItem.IsEnabled = _isRunning;
         if (_syncNowMenuItem != null)
             _syncNowMenuItem.IsEnabled = _isRunning;
+        if (_forceResyncMenuItem != null)
+            _forceResyncMenuItem.IsEnabled = _isRunning;
     }
 
     private async Task StartWatchingAsync()
     {
-        if (_watcherService == null) return;
+        if (_watcherService == null || _watcherService.IsRunning) return;
+
+        // Marked running straight away so Stop is available (and cancels) while the initial sync runs
+        _isRunning = true;
+        UpdateMenuState();
 
         if (await _watcherService.Start())
         {
-            _isRunning = true;
-            UpdateTrayTooltip();
-            UpdateMenuState();
+            UpdateTrayStatus();
             _statusWindow?.SetRunningState(true);
             _statusWindow?.UpdateStatus("Running - Watching for changes");
         }
+        else if (_isRunning) // Not cancelled by Stop
+        {
+            _isRunning = false;
+            UpdateMenuState();
+            ShowMessage("Could Not Start", _watcherService.StartError ?? "Failed to start - see the logs for details");
+            _statusWindow?.SetRunningState(false);
+            _statusWindow?.UpdateStatus("Stopped");
+        }
     }
 
     private void StopWatching()
     {
         _watcherService?.Stop();
         _isRunning = false;
-        UpdateTrayTooltip();
+        UpdateTrayStatus();
         UpdateMenuState();
         _statusWindow?.SetRunningState(false);
         _statusWindow?.UpdateStatus("Stopped");
     }
 
+    private async Task ReloadConfigAsync()
+    {
+        if (_watcherService != null)
+            await _watcherService.ReloadConfig();
+    }
+
+    // Always shows the result - the user asked for it
+    private async Task TestConnectionAsync()
+    {
+        if (_watcherService == null) return;
+
+        var (success, message) = await _watcherService.TestConnection();
+        UpdateMenuState();
+        ShowDesktopNotification(success ? "Connection OK" : "Connection failed", message, !success);
+    }
+
+    // From the menu item or from saving config.json
+    private void OnConfigReloaded(bool success, string message)
+    {
+        if (_watcherService == null) return;
+
+        _isRunning = _watcherService.IsRunning;
+        UpdateTrayStatus();
+        UpdateMenuState();
+        _statusWindow?.SetRunningState(_isRunning);
+        _statusWindow?.UpdateStatus(_isRunning ? "Running - Watching for changes" : "Stopped");
+
+        Notify(success ? "Config reloaded" : "Check your config", message, success ? Config.NOTIFY_START_STOP : Config.NOTIFY_ERRORS);
+    }
+
+    // For events raised here (Config.NOTIFY_*), subject to the Notifications setting
+    private void Notify(string title, string message, string notifyEvent)
+    {
+        if (_watcherService?.Config.ShouldNotify(notifyEvent) != false)
+            ShowDesktopNotification(title, message, notifyEvent == Config.NOTIFY_ERRORS);
+    }
+
+    private void ShowDesktopNotification(string title, string message, bool isError)
+    {
+        if (!DesktopNotifier.Show(title, message, isError))
+        {
+            _lastNotification = $"{title}: {message}";
+            UpdateTrayStatus();
+        }
+    }
+
+    private Window ShowMessage(string title, string message)
+    {
+        var dialog = new Window
+        {
+            Title = title,
+            Width = 400,
+            Height = 150,
+            WindowStartupLocation = WindowStartupLocation.CenterScreen,
+            CanResize = false
+        };
+
+        var panel = new StackPanel
+        {
+            Margin = new Avalonia.Thickness(20),
+            VerticalAlignment = Avalonia.Layout.VerticalAlignment.Center
+        };
+
+        panel.Children.Add(new TextBlock
+        {
+            Text = message,
+            HorizontalAlignment = Avalonia.Layout.HorizontalAlignment.Center,
+            TextWrapping = Avalonia.Media.TextWrapping.Wrap,
+            Margin = new Avalonia.Thickness(0, 0, 0, 20)
+        });
+
+        var okButton = new Button
+        {
+            Content = "OK",
+            HorizontalAlignment = Avalonia.Layout.HorizontalAlignment.Center,
+            Padding = new Avalonia.Thickness(30, 8)
+        };
+        okButton.Click += (s, e) => dialog.Close();
+        panel.Children.Add(okButton);
+
+        dialog.Content = panel;
+        dialog.Show();
+        return dialog;
+    }
+
+    private Task<bool> ShowConfirmAsync(string title, string message)
+    {
+        var answer = new TaskCompletionSource<bool>();
+        var dialog = new Window
+        {
+            Title = title,
+            Width = 400,
+            Height = 150,
+            WindowStartupLocation = WindowStartupLocation.CenterScreen,
+            CanResize = false
+        };
+
+        var panel = new StackPanel
+        {
+            Margin = new Avalonia.Thickness(20),
+            VerticalAlignment = Avalonia.Layout.VerticalAlignment.Center
+        };
+
+        panel.Children.Add(new TextBlock
+        {
+            Text = message,
+            HorizontalAlignment = Avalonia.Layout.HorizontalAlignment.Center,
+            TextWrapping = Avalonia.Media.TextWrapping.Wrap,
+            Margin = new Avalonia.Thickness(0, 0, 0, 20)
+        });
+
+        var buttons = new StackPanel
+        {
+            Orientation = Avalonia.Layout.Orientation.Horizontal,
+            HorizontalAlignment = Avalonia.Layout.HorizontalAlignment.Center,
+            Spacing = 10
+        };
+        var yesButton = new Button { Content = "Yes", Padding = new Avalonia.Thickness(30, 8) };
+        var noButton = new Button { Content = "No", Padding = new Avalonia.Thickness(30, 8) };
+        yesButton.Click += (s, e) => { answer.TrySetResult(true); dialog.Close(); };
+        noButton.Click += (s, e) => dialog.Close();
+        buttons.Children.Add(yesButton);
+        buttons.Children.Add(noButton);
+        panel.Children.Add(buttons);
+
+        // Closing the window any other way counts as No
+        dialog.Closed += (s, e) => answer.TrySetResult(false);
+        dialog.Content = panel;
+        dialog.Show();
+        return answer.Task;
+    }
+
     private void ShowStatusWindow()
     {
         if (_statusWindow == null || !_statusWindow.IsVisible)
@@ -223,9 +622,34 @@ public partial class App : Application
         if (_settingsWindow == null || !_settingsWindow.IsVisible)
         {
             _settingsWindow = new SettingsWindow(_watcherService!.Config);
-            _settingsWindow.OnSettingsSaved += () =>
+            _settingsWindow.OnSettingsSaved += async () =>
             {
-                _watcherService!.Config.Save();
+                if (!_watcherService!.Config.Save(out var error))
+                {
+                    ShowMessage("Could Not Save Settings", $"Could not save {Config.ConfigFile}: {error}");
+                    return;
+                }
+                UpdateMenuState();
+
+                var question = _watcherService.IsRunning
+                    ? "Settings saved. Restart watching now with the new settings?"
+                    : "Settings saved. Start watching now?";
+                if (!await ShowConfirmAsync("Printago Settings", question))
+                    return;
+
+                if (!_watcherService.IsRunning)
+                {
+                    await StartWatchingAsync();
+                    return;
+                }
+
+                if (!await _watcherService.RestartAsync())
+                    ShowMessage("Could Not Start", _watcherService.StartError ?? "Failed to start - see the logs for details");
+                _isRunning = _watcherService.IsRunning;
+                UpdateTrayStatus();
+                UpdateMenuState();
+                _statusWindow?.SetRunningState(_isRunning);
+                _statusWindow?.UpdateStatus(_isRunning ? "Running - Watching for changes" : "Stopped");
             };
         }
         _settingsWindow.Show();
@@ -242,6 +666,52 @@ public partial class App : Application
         _logsWindow.Activate();
     }
 
+    private void OpenLogFile()
+    {
+        try
+        {
+            var logFile = LogFile.CurrentPath;
+            if (!File.Exists(logFile))
+            {
+                // Nothing logged yet - open an empty file rather than failing
+                Directory.CreateDirectory(Path.GetDirectoryName(logFile)!);
+                File.WriteAllText(logFile, "");
+            }
+
+            OpenWithDefaultApp(logFile);
+        }
+        catch (Exception ex)
+        {
+            Debug.WriteLine($"Failed to open log file: {ex.Message}");
+        }
+    }
+
+    // For the advanced options that aren't in the settings window - edits are picked up by the config watcher
+    private void OpenConfigFile()
+    {
+        try
+        {
+            if (!File.Exists(Config.ConfigFile))
+                _watcherService?.Config.Save();
+            OpenWithDefaultApp(Config.ConfigFile);
+        }
+        catch (Exception ex)
+        {
+            ShowMessage("Open Config File", $"Could not open the config file: {ex.Message}");
+        }
+    }
+
+    private static void OpenWithDefaultApp(string path)
+    {
+        var opener = OperatingSystem.IsWindows() ? "explorer.exe" : OperatingSystem.IsMacOS() ? "open" : "xdg-open";
+        Process.Start(new ProcessStartInfo
+        {
+            FileName = opener,
+            Arguments = $"\"{path}\"",
+            UseShellExecute = true
+        });
+    }
+
     private void ShowAboutWindow()
     {
         var aboutWindow = new AboutWindow();
@@ -479,9 +949,33 @@ public partial class App : Application
         return Task.CompletedTask;
     }
 
-    private void ExitApp()
+    private async void ExitApp()
     {
-        _watcherService?.Stop();
+        if (_exiting)
+        {
+            // Second click - quit without waiting for the uploads
+            _trayIcon?.Dispose();
+            if (ApplicationLifetime is IClassicDesktopStyleApplicationLifetime lifetime)
+                lifetime.Shutdown();
+            return;
+        }
+
+        _exiting = true;
+        _trayUpdateTimer?.Stop();
+        if (_watcherService != null)
+        {
+            var pending = _watcherService.PendingUploadCount;
+            if (pending > 0)
+            {
+                if (_exitMenuItem != null)
+                    _exitMenuItem.Header = "Exit Now";
+                if (_trayIcon != null)
+                    _trayIcon.ToolTipText = $"Printago Folder Watch v{VERSION} - finishing {pending} upload(s)";
+                DesktopNotifier.Show("Printago Folder Watch", $"Finishing {pending} upload(s)... Choose Exit Now to quit immediately.", false);
+            }
+
+            await _watcherService.ShutdownAsync(TimeSpan.FromSeconds(_watcherService.Config.ShutdownTimeoutSeconds));
+        }
         _watcherService?.Dispose();
 
         _trayIcon?.Dispose();
@@ -0,0 +1,52 @@
+using System;
+using System.Diagnostics;
+
+namespace PrintagoFolderWatch.CrossPlatform;
+
+/// <summary>
+/// Desktop notifications through the OS: notify-send on Linux, Notification Center (osascript) on macOS.
+/// Returns false when neither is available so the caller can fall back to the tray tooltip.
+/// </summary>
+public static class DesktopNotifier
+{
+    public static bool Show(string title, string message, bool isError)
+    {
+        try
+        {
+            var startInfo = new ProcessStartInfo { UseShellExecute = false, CreateNoWindow = true };
+
+            if (OperatingSystem.IsLinux())
+            {
+                startInfo.FileName = "notify-send";
+                startInfo.ArgumentList.Add("--app-name=Printago Folder Watch");
+                startInfo.ArgumentList.Add($"--urgency={(isError ? "critical" : "normal")}");
+                startInfo.ArgumentList.Add(title);
+                startInfo.ArgumentList.Add(message);
+            }
+            else if (OperatingSystem.IsMacOS())
+            {
+                startInfo.FileName = "osascript";
+                startInfo.ArgumentList.Add("-e");
+                startInfo.ArgumentList.Add($"display notification {AppleScriptString(message)} with title {AppleScriptString(title)}");
+            }
+            else
+            {
+                return false;
+            }
+
+            using var process = Process.Start(startInfo);
+            return process != null;
+        }
+        catch (Exception ex)
+        {
+            // Tool not installed (e.g. no libnotify) - fall back to the tooltip
+            Debug.WriteLine($"Desktop notification failed: {ex.Message}");
+            return false;
+        }
+    }
+
+    private static string AppleScriptString(string value)
+    {
+        return "\"" + value.Replace("\\", "\\\\").Replace("\"", "\\\"") + "\"";
+    }
+}
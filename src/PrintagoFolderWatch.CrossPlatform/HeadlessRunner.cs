@@ -0,0 +1,104 @@
+using System;
+using System.Collections.Generic;
+using System.Linq;
+using System.Runtime.InteropServices;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core;
+
+namespace PrintagoFolderWatch.CrossPlatform;
+
+/// <summary>
+/// Runs the watcher without Avalonia or a tray icon (--headless), e.g. on a Raspberry Pi over SSH.
+/// Logs and notifications go to stdout; SIGINT/SIGTERM stop watching and let queued uploads finish before exiting (a second one quits at once).
+/// </summary>
+public static class HeadlessRunner
+{
+    public static async Task<int> RunAsync()
+    {
+        using var service = new FileWatcherService();
+        service.OnLog += (message, level) =>
+        {
+            Console.WriteLine($"[{DateTime.Now:HH:mm:ss}] [{level}] {message}");
+        };
+        // No tray to show them in - notifications (filtered by the Notifications setting) are log lines too
+        service.OnNotification += (title, message, isError) =>
+        {
+            Console.WriteLine($"[{DateTime.Now:HH:mm:ss}] [{(isError ? "ERROR" : "INFO")}] {title}: {message}");
+        };
+
+        if (!CheckConfig(service.Config))
+        {
+            return 1;
+        }
+
+        var shutdown = new TaskCompletionSource();
+        void OnSignal(PosixSignalContext context)
+        {
+            // Keep the process alive until uploads have finished - unless this is the second Ctrl-C, which quits now
+            context.Cancel = shutdown.TrySetResult();
+        }
+
+        using var sigInt = PosixSignalRegistration.Create(PosixSignal.SIGINT, OnSignal);
+        using var sigTerm = PosixSignalRegistration.Create(PosixSignal.SIGTERM, OnSignal);
+
+        if (!await service.Start())
+        {
+            Console.Error.WriteLine($"Failed to start watching: {service.StartError ?? "see the log output above"}");
+            return 1;
+        }
+
+        await shutdown.Task;
+
+        Console.WriteLine($"Shutting down... ({service.PendingUploadCount} upload(s) pending, Ctrl-C again to quit now)");
+        await service.ShutdownAsync(TimeSpan.FromSeconds(service.Config.ShutdownTimeoutSeconds));
+        return 0;
+    }
+
+    /// <summary>
+    /// "upload &lt;file&gt;...": upload the given files and exit. Log lines go to stderr, one result line per file
+    /// to stdout. Exit code is 1 if any file failed.
+    /// </summary>
+    public static async Task<int> RunUploadAsync(List<string> files)
+    {
+        if (files.Count == 0)
+        {
+            Console.Error.WriteLine("Usage: PrintagoFolderWatch upload <file> [<file>...]");
+            return 2;
+        }
+
+        using var service = new FileWatcherService();
+        service.OnLog += (message, level) =>
+        {
+            if (level != "DEBUG")
+                Console.Error.WriteLine($"[{DateTime.Now:HH:mm:ss}] [{level}] {message}");
+        };
+
+        if (!CheckConfig(service.Config))
+        {
+            return 2;
+        }
+
+        var results = await service.UploadFilesOnce(files);
+        foreach (var (file, success) in results)
+        {
+            Console.WriteLine($"{(success ? "OK    " : "FAILED")} {file}");
+        }
+
+        return results.Values.All(success => success) ? 0 : 1;
+    }
+
+    private static bool CheckConfig(Config config)
+    {
+        var problems = config.Validate();
+        if (problems.Count == 0)
+            return true;
+
+        Console.Error.WriteLine($"Configuration problems in {Config.ConfigFile}:");
+        foreach (var problem in problems)
+        {
+            Console.Error.WriteLine($"  - {problem}");
+        }
+        Console.Error.WriteLine("Fix the config file, or pass --watch, --api-url, --api-key and --store-id");
+        return false;
+    }
+}
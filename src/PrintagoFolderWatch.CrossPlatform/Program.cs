@@ -1,38 +1,74 @@
 using System;
-using System.Threading;
 using Avalonia;
 using Avalonia.ReactiveUI;
+using PrintagoFolderWatch.Core;
 
 namespace PrintagoFolderWatch.CrossPlatform;
 
 class Program
 {
-    private const string MutexName = "PrintagoFolderWatch_SingleInstance_8F4C3D2E";
-    private static Mutex? _mutex;
-
     [STAThread]
     public static void Main(string[] args)
     {
-        // Try to create a mutex to ensure single instance
-        _mutex = new Mutex(true, MutexName, out bool createdNew);
+        var options = CommandLineOptions.Parse(args);
+        if (options.ShowHelp)
+        {
+            Console.WriteLine(CommandLineOptions.HELP_TEXT);
+            return;
+        }
+        foreach (var error in options.Errors)
+        {
+            Console.Error.WriteLine(error);
+        }
+        Config.UseCommandLine(options);
 
-        if (!createdNew)
+        // One-shot upload doesn't watch anything, so it can run alongside the tray app
+        if (options.Command == "upload")
         {
-            // Another instance is already running
-            // On Windows, we could try to bring the existing window to front
-            // For now, just exit silently
+            Environment.ExitCode = HeadlessRunner.RunUploadAsync(options.Files).GetAwaiter().GetResult();
             return;
         }
 
-        try
+        // One instance per config file, so --config allows a second instance
+        using var instance = SingleInstance.TryAcquire(options.ConfigPath);
+        if (instance == null)
         {
-            BuildAvaloniaApp().StartWithClassicDesktopLifetime(args);
+            Console.Error.WriteLine("Printago Folder Watch is already running with this config");
+            if (options.Headless || !HasDisplay())
+            {
+                // A service manager should see this as a failed start
+                Environment.ExitCode = 1;
+                return;
+            }
+
+            // Launched again from a menu or the desktop - say where the running one is rather than vanishing
+            DesktopNotifier.Show("Printago Folder Watch is already running", "Look for its icon in the menu bar or system tray", isError: false);
+            return;
         }
-        finally
+
+        // --headless / --no-tray, or no display to put a tray icon on (SSH, systemd, containers)
+        if (options.Headless || !HasDisplay())
         {
-            _mutex?.ReleaseMutex();
-            _mutex?.Dispose();
+            if (!options.Headless)
+                Console.WriteLine("No display found - running headless");
+
+            Environment.ExitCode = HeadlessRunner.RunAsync().GetAwaiter().GetResult();
+            return;
         }
+
+        BuildAvaloniaApp().StartWithClassicDesktopLifetime(args);
+    }
+
+    /// <summary>
+    /// Linux needs an X11 or Wayland session for the tray; Windows and macOS always have a desktop.
+    /// </summary>
+    private static bool HasDisplay()
+    {
+        if (!OperatingSystem.IsLinux())
+            return true;
+
+        return !string.IsNullOrEmpty(Environment.GetEnvironmentVariable("DISPLAY")) ||
+               !string.IsNullOrEmpty(Environment.GetEnvironmentVariable("WAYLAND_DISPLAY"));
     }
 
     public static AppBuilder BuildAvaloniaApp()
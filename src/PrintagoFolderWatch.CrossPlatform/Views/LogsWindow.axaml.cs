@@ -5,6 +5,7 @@ using System.IO;
 using System.Runtime.InteropServices;
 using Avalonia.Controls;
 using Avalonia.Interactivity;
+using PrintagoFolderWatch.Core;
 
 namespace PrintagoFolderWatch.CrossPlatform.Views;
 
@@ -19,11 +20,7 @@ public partial class LogsWindow : Window
         LogsList.ItemsSource = _logs;
 
         // Set logs path
-        _logsPath = Path.Combine(
-            Environment.GetFolderPath(Environment.SpecialFolder.LocalApplicationData),
-            "PrintagoFolderWatch",
-            "logs"
-        );
+        _logsPath = Path.GetDirectoryName(LogFile.CurrentPath)!;
         LogsPathText.Text = _logsPath;
     }
 
@@ -41,13 +41,24 @@ public partial class SettingsWindow : Window
 
     private void Save_Click(object? sender, RoutedEventArgs e)
     {
-        _config.WatchPath = WatchPathText.Text ?? "";
-        _config.ApiUrl = ApiUrlText.Text ?? "";
-        _config.ApiKey = ApiKeyText.Text ?? "";
-        _config.StoreId = StoreIdText.Text ?? "";
+        var previous = (_config.WatchPath, _config.ApiUrl, _config.ApiKey, _config.StoreId);
+        _config.WatchPath = (WatchPathText.Text ?? "").Trim();
+        _config.ApiUrl = (ApiUrlText.Text ?? "").Trim();
+        _config.ApiKey = (ApiKeyText.Text ?? "").Trim();
+        _config.StoreId = (StoreIdText.Text ?? "").Trim();
+
+        // Nothing is saved (or changed in the running config) until everything checks out
+        var problems = _config.Validate(requireWatchFolders: true);
+        if (problems.Count > 0)
+        {
+            (_config.WatchPath, _config.ApiUrl, _config.ApiKey, _config.StoreId) = previous;
+            ErrorText.Text = string.Join("\n", problems);
+            ErrorText.IsVisible = true;
+            return;
+        }
 
-        OnSettingsSaved?.Invoke();
         Close();
+        OnSettingsSaved?.Invoke();
     }
 
     private void Cancel_Click(object? sender, RoutedEventArgs e)
@@ -89,7 +89,7 @@ public partial class StatusWindow : Window
     private void UpdateActiveUploads()
     {
         var activeUploads = _watcherService.GetActiveUploads();
-        UploadingTab.Header = $"Currently Uploading ({activeUploads.Count}/10)";
+        UploadingTab.Header = $"Currently Uploading ({activeUploads.Count}/{_watcherService.MaxParallelUploads})";
 
         var currentUploads = activeUploads.Select(u => u.FilePath).ToHashSet();
 
@@ -103,7 +103,7 @@ public partial class StatusWindow : Window
         }
 
         // Add or update panels for active uploads
-        foreach (var upload in activeUploads.Take(10))
+        foreach (var upload in activeUploads.Take(_watcherService.MaxParallelUploads))
         {
             if (_activeUploadPanels.TryGetValue(upload.FilePath, out var existingPanel))
             {
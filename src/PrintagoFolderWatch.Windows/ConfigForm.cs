@@ -13,6 +13,9 @@ namespace PrintagoFolderWatch.Windows
         private TextBox txtApiKey;
         private TextBox txtStoreId;
 
+        // After a successful save - the tray offers to (re)start watching with the new settings
+        public event Action? OnSettingsSaved;
+
         public ConfigForm(Config config)
         {
             this.config = config;
@@ -159,15 +162,29 @@ namespace PrintagoFolderWatch.Windows
 
         private void BtnSave_Click(object? sender, EventArgs e)
         {
+            var previous = (config.WatchPath, config.ApiUrl, config.ApiKey, config.StoreId);
             config.WatchPath = txtWatchPath.Text.Trim();
             config.ApiUrl = txtApiUrl.Text.Trim();
             config.ApiKey = txtApiKey.Text.Trim();
             config.StoreId = txtStoreId.Text.Trim();
 
-            config.Save();
+            // Nothing is saved (or changed in the running config) until everything checks out
+            var problems = config.Validate(requireWatchFolders: true);
+            if (problems.Count > 0)
+            {
+                (config.WatchPath, config.ApiUrl, config.ApiKey, config.StoreId) = previous;
+                MessageBox.Show(string.Join("\n", problems), "Check Your Settings", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                return;
+            }
+
+            if (!config.Save(out var error))
+            {
+                MessageBox.Show($"Could not save {Config.ConfigFile}: {error}", "Check Your Settings", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                return;
+            }
 
-            MessageBox.Show("Settings saved! Please restart watching for changes to take effect.", "Success", MessageBoxButtons.OK, MessageBoxIcon.Information);
             Close();
+            OnSettingsSaved?.Invoke();
         }
     }
 }
@@ -1,15 +1,37 @@
 using System;
 using System.Windows.Forms;
+using PrintagoFolderWatch.Core;
 
 namespace PrintagoFolderWatch.Windows
 {
     static class Program
     {
         [STAThread]
-        static void Main()
+        static void Main(string[] args)
         {
+            var options = CommandLineOptions.Parse(args);
+
             Application.EnableVisualStyles();
             Application.SetCompatibleTextRenderingDefault(false);
+
+            // No console for a tray app, so the help goes in a message box
+            if (options.ShowHelp)
+            {
+                MessageBox.Show(CommandLineOptions.HELP_TEXT, "Printago Folder Watch", MessageBoxButtons.OK, MessageBoxIcon.Information);
+                return;
+            }
+
+            Config.UseCommandLine(options);
+
+            // One instance per config file - a second one would upload every file again
+            using var instance = SingleInstance.TryAcquire(options.ConfigPath);
+            if (instance == null)
+            {
+                MessageBox.Show("Printago Folder Watch is already running - look for its icon in the system tray (it may be under the ^ arrow).",
+                    "Printago Folder Watch", MessageBoxButtons.OK, MessageBoxIcon.Information);
+                return;
+            }
+
             Application.Run(new TrayApplicationContext());
         }
     }
@@ -105,7 +105,7 @@ namespace PrintagoFolderWatch.Windows
             Controls.Add(tabControl);
 
             // Currently Uploading Tab
-            var uploadingTab = new TabPage("Currently Uploading (0/10)");
+            var uploadingTab = new TabPage($"Currently Uploading (0/{service.MaxParallelUploads})");
             uploadingTab.BackColor = Color.FromArgb(30, 30, 30);
             tabControl.TabPages.Add(uploadingTab);
 
@@ -369,7 +369,7 @@ namespace PrintagoFolderWatch.Windows
         {
             var activeUploads = service.GetActiveUploads();
 
-            tabControl.TabPages[0].Text = $"Currently Uploading ({activeUploads.Count}/10)";
+            tabControl.TabPages[0].Text = $"Currently Uploading ({activeUploads.Count}/{service.MaxParallelUploads})";
 
             var currentUploads = activeUploads.Select(u => u.FilePath).ToHashSet();
 
@@ -383,7 +383,7 @@ namespace PrintagoFolderWatch.Windows
             }
 
             int y = 10;
-            foreach (var upload in activeUploads.Take(10))
+            foreach (var upload in activeUploads.Take(service.MaxParallelUploads))
             {
                 if (activeUploadPanels.ContainsKey(upload.FilePath))
                 {
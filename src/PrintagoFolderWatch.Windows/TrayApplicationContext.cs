@@ -1,8 +1,12 @@
 using System;
+using System.Diagnostics;
 using System.Drawing;
+using System.IO;
+using System.Linq;
 using System.Threading.Tasks;
 using System.Windows.Forms;
 using PrintagoFolderWatch.Core;
+using PrintagoFolderWatch.Core.Models;
 
 namespace PrintagoFolderWatch.Windows
 {
@@ -14,6 +18,20 @@ namespace PrintagoFolderWatch.Windows
         private LogForm? logForm;
         private StatusForm? statusForm;
         private UpdateChecker updateChecker;
+        private System.Windows.Forms.Timer trayUpdateTimer;
+
+        // Tray icon per status (the badged ones fall back to the plain icon if their file is missing)
+        private Icon idleIcon;
+        private Icon uploadingIcon;
+        private Icon errorIcon;
+        private Icon pausedIcon;
+        private WatcherStatus? shownStatus;
+        // Recent Uploads / Failed Uploads entries the submenus were last built from
+        private string? shownRecentUploads;
+        private string? shownFailedUploads;
+
+        // NotifyIcon.Text throws above this length
+        private const int MAX_TOOLTIP_LENGTH = 127;
 
         public TrayApplicationContext()
         {
@@ -36,6 +54,11 @@ namespace PrintagoFolderWatch.Windows
                 printagoIcon = SystemIcons.Application;
             }
 
+            idleIcon = printagoIcon;
+            uploadingIcon = LoadTrayIcon("icon-uploading.ico") ?? idleIcon;
+            errorIcon = LoadTrayIcon("icon-error.ico") ?? idleIcon;
+            pausedIcon = LoadTrayIcon("icon-paused.ico") ?? idleIcon;
+
             // Create tray icon with version in tooltip
             trayIcon = new NotifyIcon()
             {
@@ -55,20 +78,41 @@ namespace PrintagoFolderWatch.Windows
             };
 
             // Create menu items
+            var activityItem = new ToolStripMenuItem("Not watching") { Enabled = false };
             var startItem = new ToolStripMenuItem("Start Watching");
             var stopItem = new ToolStripMenuItem("Stop Watching") { Enabled = false };
+            var pauseItem = new ToolStripMenuItem("Pause Uploads");
+            var recentUploadsItem = new ToolStripMenuItem("Recent Uploads");
+            recentUploadsItem.DropDownItems.Add(new ToolStripMenuItem("No uploads yet") { Enabled = false });
+            var failedUploadsItem = new ToolStripMenuItem("Failed Uploads (0)");
+            failedUploadsItem.DropDownItems.Add(new ToolStripMenuItem("No failed uploads") { Enabled = false });
             var configItem = new ToolStripMenuItem("Settings...");
+            var reloadConfigItem = new ToolStripMenuItem("Reload Config");
+            var testConnectionItem = new ToolStripMenuItem("Test Connection");
             var logsItem = new ToolStripMenuItem("View Logs...");
+            var openLogFileItem = new ToolStripMenuItem("Open Log File");
+            var openConfigFileItem = new ToolStripMenuItem("Open Config File");
+            var forceResyncItem = new ToolStripMenuItem("Force Full Re-sync...");
             var checkUpdateItem = new ToolStripMenuItem("Check for Updates...");
             var aboutItem = new ToolStripMenuItem("About...");
             var exitItem = new ToolStripMenuItem("Exit");
 
             trayIcon.ContextMenuStrip.Items.AddRange(new ToolStripItem[] {
+                activityItem,
+                new ToolStripSeparator(),
                 startItem,
                 stopItem,
+                pauseItem,
+                recentUploadsItem,
+                failedUploadsItem,
                 new ToolStripSeparator(),
                 configItem,
+                reloadConfigItem,
+                testConnectionItem,
                 logsItem,
+                openLogFileItem,
+                openConfigFileItem,
+                forceResyncItem,
                 new ToolStripSeparator(),
                 checkUpdateItem,
                 aboutItem,
@@ -83,39 +127,162 @@ namespace PrintagoFolderWatch.Windows
                 logForm?.AddLog(message, level);
             };
 
-            // Wire up events
-            startItem.Click += async (s, e) =>
+            if (watcherService.Config.PauseOnStart)
+                watcherService.SetUploadsPaused(true);
+
+            // Keep the icon and tooltip current (queue depth and retry/backoff state change without any menu action).
+            // Polling once a second also throttles updates during an initial sync that queues thousands of files.
+            trayUpdateTimer = new System.Windows.Forms.Timer { Interval = 1000 };
+            trayUpdateTimer.Tick += (s, e) =>
+            {
+                UpdateTrayStatus();
+                var activity = watcherService.GetActivitySummary();
+                if (activityItem.Text != activity)
+                    activityItem.Text = activity;
+                var pauseText = watcherService.UploadsPaused ? "Resume Uploads" : "Pause Uploads";
+                if (pauseItem.Text != pauseText)
+                    pauseItem.Text = pauseText;
+                UpdateRecentUploadsMenu(recentUploadsItem);
+                UpdateFailedUploadsMenu(failedUploadsItem);
+                // Re-enabled as soon as the config is fixed (Settings window or by hand)
+                var configValid = watcherService.Config.IsValid();
+                if (!watcherService.IsRunning)
+                    startItem.Enabled = configValid && watcherService.CredentialsRejectedReason == null;
+                var configText = configValid ? "Settings..." : "Setup...";
+                if (configItem.Text != configText)
+                    configItem.Text = configText;
+            };
+            trayUpdateTimer.Start();
+
+            // Not while the settings are incomplete, or the API refused the key or store at the last check
+            // (Test Connection or a config reload checks again)
+            bool CanStart() => watcherService.Config.IsValid() && watcherService.CredentialsRejectedReason == null;
+
+            // Menu state is only touched on the UI thread, so auto-start and a Start click can't interleave
+            async Task StartWatching(bool showErrors)
             {
+                if (watcherService.IsRunning)
+                    return; // Already started or still starting
+
+                // Stop stays available while the initial sync runs, and cancels it
+                startItem.Enabled = false;
+                stopItem.Enabled = true;
+
                 if (await watcherService.Start())
                 {
-                    startItem.Enabled = false;
-                    stopItem.Enabled = true;
-                    trayIcon.Text = $"Printago Folder Watch v{UpdateChecker.CurrentVersion} - Running";
-                    trayIcon.ShowBalloonTip(2000, "Printago", "Watching folder", ToolTipIcon.Info);
+                    UpdateTrayStatus();
                 }
-                else
+                else if (stopItem.Enabled) // Not cancelled by Stop
                 {
-                    MessageBox.Show("Please configure settings first", "Configuration Required", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                    startItem.Enabled = CanStart();
+                    stopItem.Enabled = false;
+                    if (showErrors)
+                    {
+                        MessageBox.Show(watcherService.StartError ?? "Failed to start - see the logs for details",
+                            "Configuration Required", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                    }
                 }
+            }
+
+            // Wire up events
+            startItem.Click += async (s, e) => await StartWatching(showErrors: true);
+
+            // Keeps watching and queuing - only stops new uploads from starting
+            pauseItem.Click += (s, e) =>
+            {
+                watcherService.SetUploadsPaused(!watcherService.UploadsPaused);
+                pauseItem.Text = watcherService.UploadsPaused ? "Resume Uploads" : "Pause Uploads";
+                UpdateTrayStatus();
             };
 
             stopItem.Click += (s, e) =>
             {
                 watcherService.Stop();
-                startItem.Enabled = true;
+                startItem.Enabled = CanStart();
                 stopItem.Enabled = false;
-                trayIcon.Text = $"Printago Folder Watch v{UpdateChecker.CurrentVersion} - Stopped";
-                trayIcon.ShowBalloonTip(2000, "Printago", "Stopped watching", ToolTipIcon.Info);
+                UpdateTrayStatus();
             };
 
-            configItem.Click += (s, e) =>
+            void ShowConfigForm()
             {
                 if (configForm == null || configForm.IsDisposed)
                 {
                     configForm = new ConfigForm(watcherService.Config);
+                    configForm.OnSettingsSaved += async () =>
+                    {
+                        var question = watcherService.IsRunning
+                            ? "Settings saved. Restart watching now with the new settings?"
+                            : "Settings saved. Start watching now?";
+                        if (MessageBox.Show(question, "Printago Settings", MessageBoxButtons.YesNo, MessageBoxIcon.Question) != DialogResult.Yes)
+                            return;
+
+                        if (!watcherService.IsRunning)
+                        {
+                            await StartWatching(showErrors: true);
+                            return;
+                        }
+
+                        stopItem.Enabled = true;
+                        startItem.Enabled = false;
+                        if (!await watcherService.RestartAsync())
+                        {
+                            MessageBox.Show(watcherService.StartError ?? "Failed to start - see the logs for details",
+                                "Configuration Required", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                        }
+                        startItem.Enabled = !watcherService.IsRunning && CanStart();
+                        stopItem.Enabled = watcherService.IsRunning;
+                        UpdateTrayStatus();
+                    };
                 }
                 configForm.Show();
                 configForm.BringToFront();
+            }
+
+            configItem.Click += (s, e) => ShowConfigForm();
+
+            reloadConfigItem.Click += async (s, e) =>
+            {
+                reloadConfigItem.Enabled = false;
+                await watcherService.ReloadConfig();
+                reloadConfigItem.Enabled = true;
+            };
+
+            // Always shows the result - the user asked for it
+            testConnectionItem.Click += async (s, e) =>
+            {
+                testConnectionItem.Enabled = false;
+                var (success, message) = await watcherService.TestConnection();
+                testConnectionItem.Enabled = true;
+                if (!watcherService.IsRunning)
+                    startItem.Enabled = CanStart();
+                ShowBalloon(success ? "Connection OK" : "Connection failed", message, !success);
+            };
+
+            // From the menu item or from saving config.json - the file watcher calls in on a background thread
+            var uiContext = System.Threading.SynchronizationContext.Current;
+            watcherService.OnConfigReloaded += (success, message) =>
+            {
+                void ShowResult()
+                {
+                    startItem.Enabled = !watcherService.IsRunning && CanStart();
+                    stopItem.Enabled = watcherService.IsRunning;
+                    UpdateTrayStatus();
+                    ShowNotification(success ? "Printago" : "Check your config", message, success ? Config.NOTIFY_START_STOP : Config.NOTIFY_ERRORS);
+                }
+
+                if (uiContext != null)
+                    uiContext.Post(_ => ShowResult(), null);
+                else
+                    ShowResult();
+            };
+
+            // Start/stop and upload results (already filtered by the Notifications setting)
+            watcherService.OnNotification += (title, message, isError) =>
+            {
+                if (uiContext != null)
+                    uiContext.Post(_ => ShowBalloon(title, message, isError), null);
+                else
+                    ShowBalloon(title, message, isError);
             };
 
             logsItem.Click += (s, e) =>
@@ -128,9 +295,79 @@ namespace PrintagoFolderWatch.Windows
                 logForm.BringToFront();
             };
 
-            exitItem.Click += (s, e) =>
+            openLogFileItem.Click += (s, e) =>
             {
-                watcherService.Stop();
+                try
+                {
+                    var logFile = LogFile.CurrentPath;
+                    if (!File.Exists(logFile))
+                    {
+                        Directory.CreateDirectory(Path.GetDirectoryName(logFile)!);
+                        File.WriteAllText(logFile, "");
+                    }
+                    Process.Start(new ProcessStartInfo(logFile) { UseShellExecute = true });
+                }
+                catch (Exception ex)
+                {
+                    MessageBox.Show($"Could not open the log file: {ex.Message}", "Open Log File", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                }
+            };
+
+            // For the advanced options that aren't in the settings dialog - edits are picked up by the config watcher
+            openConfigFileItem.Click += (s, e) =>
+            {
+                try
+                {
+                    if (!File.Exists(Config.ConfigFile))
+                        watcherService.Config.Save();
+                    Process.Start(new ProcessStartInfo(Config.ConfigFile) { UseShellExecute = true });
+                }
+                catch (Exception ex)
+                {
+                    MessageBox.Show($"Could not open the config file: {ex.Message}", "Open Config File", MessageBoxButtons.OK, MessageBoxIcon.Warning);
+                }
+            };
+
+            forceResyncItem.Click += async (s, e) =>
+            {
+                if (!watcherService.IsRunning)
+                {
+                    MessageBox.Show("Start watching first", "Force Full Re-sync", MessageBoxButtons.OK, MessageBoxIcon.Information);
+                    return;
+                }
+
+                var confirm = MessageBox.Show(
+                    "Re-upload every file in the watch folder, even ones that haven't changed?\n\nExisting Parts and their settings are kept.",
+                    "Force Full Re-sync", MessageBoxButtons.YesNo, MessageBoxIcon.Question);
+                if (confirm == DialogResult.Yes)
+                {
+                    await watcherService.ForceFullResync();
+                }
+            };
+
+            bool exiting = false;
+            exitItem.Click += async (s, e) =>
+            {
+                if (exiting)
+                {
+                    // Second click - quit without waiting for the uploads
+                    trayIcon.Visible = false;
+                    Application.Exit();
+                    return;
+                }
+
+                exiting = true;
+                trayUpdateTimer.Stop();
+
+                var pending = watcherService.PendingUploadCount;
+                if (pending > 0)
+                {
+                    exitItem.Text = "Exit Now";
+                    trayIcon.Text = $"Printago Folder Watch - finishing {pending} upload(s)";
+                    trayIcon.ShowBalloonTip(5000, "Printago", $"Finishing {pending} upload(s)... Choose Exit Now to quit immediately.", ToolTipIcon.Info);
+                }
+
+                await watcherService.ShutdownAsync(TimeSpan.FromSeconds(watcherService.Config.ShutdownTimeoutSeconds));
                 trayIcon.Visible = false;
                 Application.Exit();
             };
@@ -156,17 +393,19 @@ namespace PrintagoFolderWatch.Windows
             };
 
             // Auto-start if configured
-            if (watcherService.Config.IsValid())
+            var configProblems = watcherService.Config.Validate();
+            if (configProblems.Count > 0)
             {
-                _ = Task.Run(async () =>
-                {
-                    if (await watcherService.Start())
-                    {
-                        trayIcon.Text = $"Printago Folder Watch v{UpdateChecker.CurrentVersion} - Running";
-                        startItem.Enabled = false;
-                        stopItem.Enabled = true;
-                    }
-                });
+                startItem.Enabled = false;
+                configItem.Text = "Setup...";
+                ShowNotification(Config.IsFirstRun ? "Welcome to Printago Folder Watch" : "Printago - check your settings",
+                    Config.IsFirstRun ? "Enter your Printago details and pick a folder to get started" : configProblems[0], Config.NOTIFY_ERRORS);
+                if (Config.IsFirstRun)
+                    ShowConfigForm();
+            }
+            else
+            {
+                _ = StartWatching(showErrors: false);
             }
 
             // Check for updates on startup (after a short delay)
@@ -177,6 +416,152 @@ namespace PrintagoFolderWatch.Windows
             });
         }
 
+        /// <summary>
+        /// Notification for an event (Config.NOTIFY_*), subject to the Notifications setting
+        /// </summary>
+        private void ShowNotification(string title, string message, string notifyEvent)
+        {
+            if (watcherService.Config.ShouldNotify(notifyEvent))
+                ShowBalloon(title, message, notifyEvent == Config.NOTIFY_ERRORS);
+        }
+
+        /// <summary>
+        /// Balloon tip (a toast on Windows 10+)
+        /// </summary>
+        private void ShowBalloon(string title, string message, bool isError)
+        {
+            trayIcon.ShowBalloonTip(isError ? 5000 : 2000, title, message, isError ? ToolTipIcon.Error : ToolTipIcon.Info);
+        }
+
+        private void UpdateTrayStatus()
+        {
+            var status = watcherService.Status;
+            if (status != shownStatus)
+            {
+                trayIcon.Icon = status switch
+                {
+                    WatcherStatus.Uploading => uploadingIcon,
+                    WatcherStatus.Error => errorIcon,
+                    WatcherStatus.Paused => pausedIcon,
+                    _ => idleIcon
+                };
+                shownStatus = status;
+            }
+
+            var text = $"Printago Folder Watch v{UpdateChecker.CurrentVersion}\n{watcherService.GetStatusSummary()}";
+            if (text.Length > MAX_TOOLTIP_LENGTH)
+                text = text.Substring(0, MAX_TOOLTIP_LENGTH - 1) + "…";
+
+            if (trayIcon.Text != text)
+                trayIcon.Text = text;
+        }
+
+        /// <summary>
+        /// Rebuild the Recent Uploads submenu when an upload has finished since the last tick.
+        /// Uploaded: click copies its Printago path. Failed: click queues it again.
+        /// </summary>
+        private void UpdateRecentUploadsMenu(ToolStripMenuItem recentUploadsItem)
+        {
+            var recent = watcherService.GetRecentUploads();
+            var key = string.Join("|", recent.Select(u => $"{u.FilePath}:{u.FinishedAt.Ticks}"));
+            if (key == shownRecentUploads)
+                return;
+            shownRecentUploads = key;
+
+            recentUploadsItem.DropDownItems.Clear();
+            if (recent.Count == 0)
+            {
+                recentUploadsItem.DropDownItems.Add(new ToolStripMenuItem("No uploads yet") { Enabled = false });
+                return;
+            }
+
+            foreach (var upload in recent)
+            {
+                var item = new ToolStripMenuItem($"{(upload.Success ? "✓" : "✗")} {upload.FileName}  {upload.FinishedAt:HH:mm}")
+                {
+                    ToolTipText = upload.Success ? $"{upload.CloudPath}\nClick to copy the Printago path" : $"{upload.Error}\nClick to retry"
+                };
+                item.Click += (s, e) =>
+                {
+                    if (upload.Success)
+                    {
+                        Clipboard.SetText(upload.CloudPath);
+                        trayIcon.ShowBalloonTip(2000, "Copied", upload.CloudPath, ToolTipIcon.Info);
+                    }
+                    else if (!watcherService.RetryUpload(upload.FilePath))
+                    {
+                        trayIcon.ShowBalloonTip(3000, "Can't retry", $"{upload.FileName}: start watching first, or the file is gone", ToolTipIcon.Warning);
+                    }
+                };
+                recentUploadsItem.DropDownItems.Add(item);
+            }
+        }
+
+        /// <summary>
+        /// Rebuild the Failed Uploads submenu when the list has changed.
+        /// Click a file to queue it again, or Retry All Failed for the whole list.
+        /// </summary>
+        private void UpdateFailedUploadsMenu(ToolStripMenuItem failedUploadsItem)
+        {
+            var failed = watcherService.GetFailedUploads();
+            var key = string.Join("|", failed.Select(f => $"{f.FilePath}:{f.FailedAt.Ticks}"));
+            if (key == shownFailedUploads)
+                return;
+            shownFailedUploads = key;
+
+            failedUploadsItem.Text = $"Failed Uploads ({failed.Count})";
+            failedUploadsItem.DropDownItems.Clear();
+            if (failed.Count == 0)
+            {
+                failedUploadsItem.DropDownItems.Add(new ToolStripMenuItem("No failed uploads") { Enabled = false });
+                return;
+            }
+
+            foreach (var upload in failed)
+            {
+                var status = upload.HttpStatus is int code ? $"HTTP {code} - " : "";
+                var item = new ToolStripMenuItem(Path.GetFileName(upload.FilePath))
+                {
+                    ToolTipText = $"{upload.CloudPath}\n{status}{upload.Reason}\n{upload.FailedAt.ToLocalTime():g} - click to retry"
+                };
+                item.Click += (s, e) =>
+                {
+                    if (!watcherService.RetryUpload(upload.FilePath))
+                    {
+                        trayIcon.ShowBalloonTip(3000, "Can't retry", $"{Path.GetFileName(upload.FilePath)}: start watching first, or the file is gone", ToolTipIcon.Warning);
+                    }
+                };
+                failedUploadsItem.DropDownItems.Add(item);
+            }
+
+            var retryAllItem = new ToolStripMenuItem("Retry All Failed");
+            retryAllItem.Click += (s, e) =>
+            {
+                if (!watcherService.IsRunning)
+                {
+                    trayIcon.ShowBalloonTip(3000, "Can't retry", "Start watching first", ToolTipIcon.Warning);
+                    return;
+                }
+                var queued = watcherService.RetryAllFailed();
+                trayIcon.ShowBalloonTip(2000, "Retry All Failed", $"Queued {queued} file(s)", ToolTipIcon.Info);
+            };
+            failedUploadsItem.DropDownItems.Add(new ToolStripSeparator());
+            failedUploadsItem.DropDownItems.Add(retryAllItem);
+        }
+
+        private static Icon? LoadTrayIcon(string fileName)
+        {
+            try
+            {
+                var iconPath = System.IO.Path.Combine(AppDomain.CurrentDomain.BaseDirectory, fileName);
+                return System.IO.File.Exists(iconPath) ? new Icon(iconPath) : null;
+            }
+            catch
+            {
+                return null;
+            }
+        }
+
         private void ShowAboutDialog()
         {
             var aboutForm = new Form
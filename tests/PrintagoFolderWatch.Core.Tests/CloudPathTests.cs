@@ -0,0 +1,85 @@
+using System;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class CloudPathTests
+    {
+        [Theory]
+        [InlineData("a/b/c.stl", "a/b/c.stl")]
+        [InlineData(@"a\b\c.stl", "a/b/c.stl")]
+        [InlineData(@"a\\b//./c.stl", "a/b/c.stl")]
+        [InlineData("/a/b/", "a/b")]
+        [InlineData("./c.stl", "c.stl")]
+        [InlineData("", "")]
+        public void ToCloudPath_UsesForwardSlashesWithoutEmptySegments(string path, string expected)
+        {
+            Assert.Equal(expected, Config.ToCloudPath(path));
+        }
+
+        [Theory]
+        [InlineData("a/b/c.stl", "a/b")]
+        [InlineData(@"a\c.stl", "a")]
+        [InlineData("c.stl", "")]
+        [InlineData("", "")]
+        public void GetCloudFolder_IsEverythingBeforeTheName(string cloudPath, string expected)
+        {
+            Assert.Equal(expected, Config.GetCloudFolder(cloudPath));
+        }
+
+        [Theory]
+        [InlineData("", @"parts\cube.stl", "parts/cube.stl")]
+        [InlineData("incoming/{relpath}", "parts/cube.stl", "incoming/parts/cube.stl")]
+        [InlineData("{ext}/{filename}", "parts/cube.STL", "STL/cube.STL")]
+        [InlineData("/by-name//{filename}/", "parts/cube.stl", "by-name/cube.stl")]
+        [InlineData("no-placeholders", "parts/cube.stl", "parts/cube.stl")] // Invalid - the relative path is used
+        public void FormatCloudPath_FillsInTheTemplate(string template, string relativePath, string expected)
+        {
+            var config = new Config { CloudPathTemplate = template };
+
+            Assert.Equal(expected, config.FormatCloudPath(relativePath));
+        }
+
+        [Fact]
+        public void FormatCloudPath_FillsInDateAndHostname()
+        {
+            var config = new Config { CloudPathTemplate = "{hostname}/{date}/{filename}" };
+
+            Assert.Equal($"{Environment.MachineName}/{DateTime.Now:yyyy-MM-dd}/cube.stl", config.FormatCloudPath("a/cube.stl"));
+        }
+
+        [Theory]
+        [InlineData("", null)]
+        [InlineData("{relpath}", null)]
+        [InlineData("x/{date}/{filename}", null)]
+        [InlineData("x/{date}", "must contain {relpath} or {filename}")]
+        [InlineData("x/{relpath", "has a \"{\" without a matching \"}\"")]
+        [InlineData("x}/{relpath}", "has a \"}\" without a matching \"{\"")]
+        [InlineData("../{relpath}", "can't contain \"..\"")]
+        public void ValidateCloudPathTemplate(string template, string? expected)
+        {
+            var config = new Config { CloudPathTemplate = template };
+
+            Assert.Equal(expected, config.ValidateCloudPathTemplate());
+        }
+
+        [Fact]
+        public void ValidateCloudPathTemplate_NamesAnUnknownPlaceholder()
+        {
+            var config = new Config { CloudPathTemplate = "{folder}/{filename}" };
+
+            Assert.StartsWith("has an unknown placeholder {folder}", config.ValidateCloudPathTemplate());
+        }
+
+        [Theory]
+        [InlineData("/v1", "parts", "/v1/parts")]
+        [InlineData("v2/", "/parts/", "/v2/parts/")]
+        [InlineData("", "parts", "/parts")]
+        public void GetApiPath_JoinsTheBasePath(string basePath, string path, string expected)
+        {
+            var config = new Config { ApiBasePath = basePath };
+
+            Assert.Equal(expected, config.GetApiPath(path));
+        }
+    }
+}
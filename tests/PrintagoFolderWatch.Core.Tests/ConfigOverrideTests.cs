@@ -0,0 +1,102 @@
+using System;
+using System.IO;
+using Newtonsoft.Json.Linq;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class ConfigOverrideTests : IDisposable
+    {
+        private static readonly string[] Variables =
+            { "PRINTAGO_WATCH_PATH", "PRINTAGO_API_URL", "PRINTAGO_API_KEY", "PRINTAGO_STORE_ID", "PRINTAGO_CONFIG" };
+
+        private readonly string configFile;
+
+        public ConfigOverrideTests()
+        {
+            foreach (var variable in Variables)
+                Environment.SetEnvironmentVariable(variable, null);
+
+            configFile = Path.Combine(TestEnvironment.CreateTempDirectory("overrides"), "config.json");
+            File.WriteAllText(configFile, new JObject
+            {
+                ["WatchPath"] = "/file/prints",
+                ["ApiUrl"] = "https://file.test",
+                ["ApiKey"] = "file-key",
+                ["StoreId"] = "file-store"
+            }.ToString());
+        }
+
+        public void Dispose()
+        {
+            foreach (var variable in Variables)
+                Environment.SetEnvironmentVariable(variable, null);
+            TestEnvironment.UseTempConfig();
+        }
+
+        private Config Load(params string[] flags)
+        {
+            var options = CommandLineOptions.Parse(flags);
+            options.ConfigPath = configFile;
+            options.NoKeychain = true;
+            Config.UseCommandLine(options);
+            return Config.Load();
+        }
+
+        private JObject SavedFile() => JObject.Parse(File.ReadAllText(configFile));
+
+        [Fact]
+        public void Load_FlagsBeatEnvironmentBeatFile()
+        {
+            Environment.SetEnvironmentVariable("PRINTAGO_API_URL", "https://env.test");
+            Environment.SetEnvironmentVariable("PRINTAGO_API_KEY", "env-key");
+
+            var config = Load("--api-key", "flag-key");
+
+            Assert.Equal("flag-key", config.ApiKey);
+            Assert.Equal("https://env.test", config.ApiUrl);
+            Assert.Equal("file-store", config.StoreId);
+            Assert.Equal("/file/prints", config.WatchPath);
+        }
+
+        [Fact]
+        public void Save_KeepsOverridesOutOfTheFile()
+        {
+            Environment.SetEnvironmentVariable("PRINTAGO_API_URL", "https://env.test");
+            var config = Load("--store-id=flag-store");
+
+            config.WatchPath = "/settings/prints";
+            Assert.True(config.Save(out var error), error);
+
+            var saved = SavedFile();
+            Assert.Equal("https://file.test", (string?)saved["ApiUrl"]);
+            Assert.Equal("file-store", (string?)saved["StoreId"]);
+            // Changed since startup, so it's saved as normal
+            Assert.Equal("/settings/prints", (string?)saved["WatchPath"]);
+            Assert.Equal("https://env.test", config.ApiUrl);
+        }
+
+        [Fact]
+        public void Load_WithSaveWritesFlagsButNotEnvironment()
+        {
+            Environment.SetEnvironmentVariable("PRINTAGO_API_KEY", "env-key");
+
+            var config = Load("--save", "--api-url", "https://flag.test");
+
+            var saved = SavedFile();
+            Assert.Equal("https://flag.test", (string?)saved["ApiUrl"]);
+            Assert.Equal("file-key", (string?)saved["ApiKey"]);
+            Assert.Equal("env-key", config.ApiKey);
+        }
+
+        [Fact]
+        public void Parse_ConfigFlagBeatsEnvironment()
+        {
+            var fromEnvironment = Path.Combine(Path.GetTempPath(), "env-config.json");
+            Environment.SetEnvironmentVariable("PRINTAGO_CONFIG", fromEnvironment);
+
+            Assert.Equal(fromEnvironment, CommandLineOptions.Parse(Array.Empty<string>()).ConfigPath);
+            Assert.Equal(configFile, CommandLineOptions.Parse(new[] { "--config", configFile }).ConfigPath);
+        }
+    }
+}
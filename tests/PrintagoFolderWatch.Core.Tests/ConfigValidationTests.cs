@@ -0,0 +1,132 @@
+using System;
+using System.Collections.Generic;
+using System.IO;
+using PrintagoFolderWatch.Core.Models;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class ConfigValidationTests : IDisposable
+    {
+        private readonly string root = TestEnvironment.CreateTempDirectory("validate");
+
+        private Config CreateValidConfig()
+        {
+            var watchPath = Path.Combine(root, "prints");
+            Directory.CreateDirectory(watchPath);
+            return new Config
+            {
+                WatchPath = watchPath,
+                ApiUrl = "https://api.printago.io",
+                ApiKey = "key",
+                StoreId = "store"
+            };
+        }
+
+        public void Dispose()
+        {
+            Directory.Delete(root, recursive: true);
+        }
+
+        [Fact]
+        public void Validate_AcceptsTheDefaults()
+        {
+            Assert.Empty(CreateValidConfig().Validate());
+        }
+
+        public static IEnumerable<object[]> InvalidConfigs()
+        {
+            object[] Case(string expected, Action<Config> change) => new object[] { expected, change };
+
+            yield return Case("ApiKey is not set", c => c.ApiKey = " ");
+            yield return Case("StoreId is not set", c => c.StoreId = "");
+            yield return Case("ApiUrl is not an http(s) URL", c => c.ApiUrl = "ftp://api.printago.io");
+            yield return Case("ApiUrl is not an http(s) URL", c => c.ApiUrl = "api.printago.io");
+            yield return Case("ApiKeyHeader is not a valid HTTP header name", c => c.ApiKeyHeader = "x api key");
+            yield return Case("StoreIdHeader is not a valid HTTP header name", c => c.StoreIdHeader = "");
+            yield return Case("RequestsPerSecond can't be negative", c => c.RequestsPerSecond = -1);
+            yield return Case("RequestsPerSecond can't be negative", c => c.RequestsPerSecond = double.NaN);
+            yield return Case("RequestsPerSecond must be 0 (no limit) or at least 0.01", c => c.RequestsPerSecond = 1e-300);
+            yield return Case("SignedUploadUrlsPath is not set", c => c.SignedUploadUrlsPath = " ");
+            yield return Case("PlaceholderAction must be skip, hydrate or notify", c => c.PlaceholderAction = "download");
+            yield return Case("PostUploadAction must be none, delete or move", c => c.PostUploadAction = "archive");
+            yield return Case("PostUploadAction can't be used together with SyncDeletes", c =>
+            {
+                c.PostUploadAction = "delete";
+                c.SyncDeletes = true;
+            });
+            yield return Case("MoveToDir is not set", c => c.PostUploadAction = "move");
+            yield return Case("MoveToDir can't be a watch folder or contain one", c =>
+            {
+                c.PostUploadAction = "move";
+                c.MoveToDir = Path.GetDirectoryName(c.WatchPath)!;
+            });
+            yield return Case("Watch folders can't be inside each other", c => c.Watches = new List<WatchEntry>
+            {
+                new() { Path = c.WatchPath },
+                new() { Path = Directory.CreateDirectory(Path.Combine(c.WatchPath, "inner")).FullName, CloudPrefix = "inner" }
+            });
+            yield return Case("Watches[1].Path is not set", c => c.Watches = new List<WatchEntry>
+            {
+                new() { Path = c.WatchPath },
+                new() { Path = "", CloudPrefix = "gcode" }
+            });
+            yield return Case("Watches[0].CloudPrefix can't contain \".\" or \"..\"", c => c.Watches = new List<WatchEntry>
+            {
+                new() { Path = c.WatchPath, CloudPrefix = "../outside" }
+            });
+            yield return Case("Watches[0] and Watches[1] have the same CloudPrefix", c => c.Watches = new List<WatchEntry>
+            {
+                new() { Path = c.WatchPath },
+                new() { Path = CreateSibling(c, "gcode") }
+            });
+            yield return Case("Watches[0] and Watches[1] have the same CloudPrefix", c => c.Watches = new List<WatchEntry>
+            {
+                new() { Path = c.WatchPath, CloudPrefix = "Models" },
+                new() { Path = CreateSibling(c, "gcode"), CloudPrefix = "/models/" }
+            });
+        }
+
+        // A folder next to the watch folder, under the test's temporary root
+        private static string CreateSibling(Config config, string name)
+        {
+            var path = Path.Combine(Path.GetDirectoryName(config.WatchPath)!, name);
+            Directory.CreateDirectory(path);
+            return path;
+        }
+
+        [Theory]
+        [MemberData(nameof(InvalidConfigs))]
+        public void Validate_ReportsWhatIsWrong(string expected, Action<Config> change)
+        {
+            var config = CreateValidConfig();
+            change(config);
+
+            var error = Assert.Single(config.Validate());
+            Assert.StartsWith(expected, error);
+        }
+
+        [Fact]
+        public void Validate_WaitsForAMissingWatchFolderUnlessTurnedOff()
+        {
+            var config = CreateValidConfig();
+            config.WatchPath = Path.Combine(root, "not-mounted-yet");
+
+            Assert.Empty(config.Validate());
+            Assert.StartsWith("WatchPath does not exist", Assert.Single(config.Validate(requireWatchFolders: true)));
+
+            config.WatchPathRetrySeconds = 0;
+            Assert.StartsWith("WatchPath does not exist", Assert.Single(config.Validate()));
+        }
+
+        [Fact]
+        public void Validate_RejectsAFileAsTheWatchFolder()
+        {
+            var config = CreateValidConfig();
+            config.WatchPath = Path.Combine(root, "cube.stl");
+            File.WriteAllText(config.WatchPath, "");
+
+            Assert.StartsWith("WatchPath is a file, not a folder", Assert.Single(config.Validate()));
+        }
+    }
+}
@@ -0,0 +1,59 @@
+using System.Collections.Generic;
+using System.IO;
+using PrintagoFolderWatch.Core.Models;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class ConfigWatchesTests
+    {
+        private static readonly string Prints = Path.Combine(Path.GetTempPath(), "prints");
+        private static readonly string Gcode = Path.Combine(Path.GetTempPath(), "gcode");
+
+        [Fact]
+        public void GetWatches_IsJustWatchPathForAnOldConfig()
+        {
+            var config = new Config { WatchPath = Prints };
+
+            var watch = Assert.Single(config.GetWatches());
+            Assert.Equal(Prints, watch.Path);
+            Assert.Empty(new Config().GetWatches());
+        }
+
+        [Fact]
+        public void GetWatches_PutsWatchPathFirstWithoutChangingWatches()
+        {
+            var first = new WatchEntry { Path = "old", CloudPrefix = "models" };
+            var config = new Config
+            {
+                WatchPath = Prints,
+                Watches = new List<WatchEntry> { first, new() { Path = Gcode, CloudPrefix = "gcode" } }
+            };
+
+            var watches = config.GetWatches();
+            watches.Add(new WatchEntry { Path = "extra" });
+
+            Assert.Equal(Prints, watches[0].Path);
+            Assert.Equal("models", watches[0].CloudPrefix);
+            Assert.Equal(Gcode, watches[1].Path);
+            // Workers read Watches while Settings or a reload replace it - it must never change underneath them
+            Assert.Equal("old", first.Path);
+            Assert.Same(first, config.Watches[0]);
+            Assert.Equal(2, config.Watches.Count);
+        }
+
+        [Fact]
+        public void FindWatch_IsTheFolderAPathIsIn()
+        {
+            var config = new Config
+            {
+                WatchPath = Prints,
+                Watches = new List<WatchEntry> { new() { Path = Prints }, new() { Path = Gcode } }
+            };
+
+            Assert.Equal(Gcode, config.FindWatch(Path.Combine(Gcode, "a", "cube.gcode"))?.Path);
+            Assert.Equal(Prints, config.FindWatch(Path.Combine(Prints, "cube.stl"))?.Path);
+            Assert.Null(config.FindWatch(Path.Combine(Path.GetTempPath(), "printsx", "cube.stl")));
+        }
+    }
+}
@@ -0,0 +1,45 @@
+using System.Linq;
+using System.Net.Http;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class DryRunTests
+    {
+        // The sync root with one empty folder in it that has no local counterpart
+        private const string FoldersWithAnEmptyOne = @"[
+            { ""id"": ""f-root"", ""name"": ""Local Folder Sync"", ""parentId"": null },
+            { ""id"": ""f-old"", ""name"": ""old"", ""parentId"": ""f-root"" } ]";
+
+        private static ServiceHarness CreateHarness(bool dryRun)
+        {
+            var harness = new ServiceHarness(c => c.DryRun = dryRun);
+            harness.Api.Override = request => request.Method == HttpMethod.Get && request.Path.EndsWith("/folders")
+                ? FakeHttpHandler.Json(FoldersWithAnEmptyOne)
+                : null;
+            return harness;
+        }
+
+        [Fact]
+        public async Task InitialSync_DeletesEmptyRemoteFolders()
+        {
+            using var harness = CreateHarness(dryRun: false);
+            await harness.StartAsync();
+
+            var delete = Assert.Single(harness.Api.RequestsTo(HttpMethod.Delete, "/folders/delete"));
+            Assert.Contains("f-old", delete.Body);
+        }
+
+        [Fact]
+        public async Task DryRun_LogsEmptyFoldersInsteadOfDeletingThem()
+        {
+            using var harness = CreateHarness(dryRun: true);
+            await harness.StartAsync();
+
+            Assert.Empty(harness.Api.Requests.Where(r => r.Method == HttpMethod.Delete));
+            Assert.Contains(harness.Logs, l => l.message.StartsWith("DRY RUN: would delete folder") && l.message.EndsWith("old"));
+        }
+    }
+}
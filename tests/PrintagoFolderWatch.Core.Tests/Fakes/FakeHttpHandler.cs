@@ -0,0 +1,72 @@
+using System;
+using System.Collections.Concurrent;
+using System.Collections.Generic;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Net.Http.Headers;
+using System.Text;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core.Tests.Fakes
+{
+    /// <summary>
+    /// A request as the fake server saw it. The body is read when it arrives, since the sender may dispose it.
+    /// </summary>
+    internal record RecordedRequest(HttpMethod Method, Uri Uri, byte[] BodyBytes, Dictionary<string, string> Headers,
+        HttpContentHeaders? ContentHeaders, DateTime ReceivedUtc)
+    {
+        public string Path => Uri.AbsolutePath;
+        public string Body => Encoding.UTF8.GetString(BodyBytes);
+    }
+
+    /// <summary>
+    /// HttpMessageHandler that answers every request with a function (or a subclass's Respond) instead of the
+    /// network, and records them
+    /// </summary>
+    internal class FakeHttpHandler : HttpMessageHandler
+    {
+        private readonly Func<RecordedRequest, CancellationToken, Task<HttpResponseMessage>>? respond;
+
+        public ConcurrentQueue<RecordedRequest> Requests { get; } = new();
+
+        public FakeHttpHandler(Func<RecordedRequest, CancellationToken, Task<HttpResponseMessage>> respond)
+        {
+            this.respond = respond;
+        }
+
+        public FakeHttpHandler(Func<RecordedRequest, HttpResponseMessage> respond)
+            : this((request, _) => Task.FromResult(respond(request)))
+        {
+        }
+
+        protected FakeHttpHandler()
+        {
+        }
+
+        protected virtual Task<HttpResponseMessage> Respond(RecordedRequest request, CancellationToken ct) => respond!(request, ct);
+
+        public List<RecordedRequest> RequestsTo(HttpMethod method, string pathSuffix) =>
+            Requests.Where(r => r.Method == method && r.Path.EndsWith(pathSuffix)).ToList();
+
+        protected override async Task<HttpResponseMessage> SendAsync(HttpRequestMessage request, CancellationToken cancellationToken)
+        {
+            var headers = request.Headers.ToDictionary(h => h.Key, h => string.Join(",", h.Value), StringComparer.OrdinalIgnoreCase);
+            var body = request.Content != null ? await request.Content.ReadAsByteArrayAsync(cancellationToken) : Array.Empty<byte>();
+            var recorded = new RecordedRequest(request.Method, request.RequestUri!, body, headers, request.Content?.Headers, DateTime.UtcNow);
+            Requests.Enqueue(recorded);
+
+            var response = await Respond(recorded, cancellationToken);
+            response.RequestMessage ??= request;
+            return response;
+        }
+
+        public static HttpResponseMessage Json(HttpStatusCode status, string json) => new(status)
+        {
+            Content = new StringContent(json, Encoding.UTF8, "application/json")
+        };
+
+        public static HttpResponseMessage Json(string json) => Json(HttpStatusCode.OK, json);
+    }
+}
@@ -0,0 +1,56 @@
+using System;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+using Newtonsoft.Json;
+using Newtonsoft.Json.Linq;
+
+namespace PrintagoFolderWatch.Core.Tests.Fakes
+{
+    /// <summary>
+    /// Just enough of the Printago API for the service: no folders or Parts to start with, and every folder,
+    /// signed URL and Part it's asked for is created. Override answers any request first (null = the default).
+    /// </summary>
+    internal class FakePrintago : FakeHttpHandler
+    {
+        public const string ApiUrl = "https://api.test";
+        public const string StorageUrl = "https://storage.test/";
+
+        private static int nextId;
+
+        public Func<RecordedRequest, HttpResponseMessage?>? Override { get; set; }
+
+        public int SignedUrlRequestCount => RequestsTo(HttpMethod.Post, "/storage/signed-upload-urls").Count;
+
+        protected override Task<HttpResponseMessage> Respond(RecordedRequest request, CancellationToken ct)
+        {
+            return Task.FromResult(Override?.Invoke(request) ?? RespondByDefault(request));
+        }
+
+        private static HttpResponseMessage RespondByDefault(RecordedRequest request)
+        {
+            var path = request.Path;
+            if (request.Method == HttpMethod.Get && (path.EndsWith("/folders") || path.EndsWith("/parts")))
+                return Json("[]");
+            if (request.Method == HttpMethod.Post && (path.EndsWith("/folders") || path.EndsWith("/parts")))
+                return Json(JsonConvert.SerializeObject(new { id = $"id-{Interlocked.Increment(ref nextId)}" }));
+            if (request.Method == HttpMethod.Post && path.EndsWith("/storage/signed-upload-urls"))
+            {
+                var filenames = JObject.Parse(request.Body)["filenames"]!.Values<string>();
+                var signedUrls = filenames.Select(name => new
+                {
+                    filename = name,
+                    uploadUrl = StorageUrl + name,
+                    path = $"stores/store-1/{name}"
+                });
+                return Json(JsonConvert.SerializeObject(new { signedUrls }));
+            }
+            if (request.Method == HttpMethod.Patch)
+                return Json("{}");
+
+            return Json(HttpStatusCode.NotFound, @"{ ""message"": ""Not in FakePrintago"" }");
+        }
+    }
+}
@@ -0,0 +1,44 @@
+using System;
+using System.Collections.Concurrent;
+using System.IO;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core.Tests.Fakes
+{
+    internal record StoragePut(string UploadUrl, byte[] Content, string ContentType, DateTime ReceivedUtc);
+
+    /// <summary>
+    /// IStorageUploader that keeps what it's sent. OnPut can delay or fail a PUT; by default every PUT succeeds.
+    /// </summary>
+    internal class FakeStorage : IStorageUploader
+    {
+        public ConcurrentQueue<StoragePut> Puts { get; } = new();
+
+        // Called once the body has been read; the returned response is what the upload gets
+        public Func<StoragePut, CancellationToken, Task<HttpResponseMessage>>? OnPut { get; set; }
+
+        // PUTs that have started, including ones still waiting in OnPut
+        public int StartedCount => Volatile.Read(ref startedCount);
+        private int startedCount;
+
+        public string[] UploadedNames => Puts.Select(p => p.UploadUrl.Substring(p.UploadUrl.LastIndexOf('/') + 1)).ToArray();
+
+        public async Task<HttpResponseMessage> PutAsync(string uploadUrl, Stream content, long length, string contentType,
+            byte[]? contentMd5, CancellationToken ct)
+        {
+            Interlocked.Increment(ref startedCount);
+            using var buffer = new MemoryStream();
+            await content.CopyToAsync(buffer, ct);
+            var put = new StoragePut(uploadUrl, buffer.ToArray(), contentType, DateTime.UtcNow);
+
+            var response = OnPut != null ? await OnPut(put, ct) : new HttpResponseMessage(HttpStatusCode.OK);
+            if (response.IsSuccessStatusCode)
+                Puts.Enqueue(put);
+            return response;
+        }
+    }
+}
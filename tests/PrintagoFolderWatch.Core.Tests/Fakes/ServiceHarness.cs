@@ -0,0 +1,83 @@
+using System;
+using System.Collections.Concurrent;
+using System.IO;
+using System.Linq;
+using System.Threading.Tasks;
+
+namespace PrintagoFolderWatch.Core.Tests.Fakes
+{
+    /// <summary>
+    /// A FileWatcherService on a temporary watch folder, talking to FakePrintago and FakeStorage. Settings are
+    /// the defaults with short waits; configure changes them before the service is created.
+    /// </summary>
+    internal sealed class ServiceHarness : IDisposable
+    {
+        private readonly string root;
+
+        public string WatchDir { get; }
+        public Config Config { get; }
+        public FakePrintago Api { get; } = new();
+        public FakeStorage Storage { get; } = new();
+        public FileWatcherService Service { get; }
+        public ConcurrentQueue<(string message, string level)> Logs { get; } = new();
+        public ConcurrentQueue<(string title, string message, bool isError)> Notifications { get; } = new();
+
+        public ServiceHarness(Action<Config>? configure = null)
+        {
+            TestEnvironment.UseTempConfig();
+            root = TestEnvironment.CreateTempDirectory("service");
+            WatchDir = Path.Combine(root, "prints");
+            Directory.CreateDirectory(WatchDir);
+
+            Config = new Config
+            {
+                WatchPath = WatchDir,
+                ApiUrl = FakePrintago.ApiUrl,
+                ApiKey = "test-key-123",
+                StoreId = "store-1",
+                RequestsPerSecond = 0,
+                DebounceMs = 300,
+                MaxRetries = 2
+            };
+            configure?.Invoke(Config);
+
+            Service = new FileWatcherService(Config, Path.Combine(root, "file-tracking.db"), Api, Storage);
+            Service.OnLog += (message, level) => Logs.Enqueue((message, level));
+            Service.OnNotification += (title, message, isError) => Notifications.Enqueue((title, message, isError));
+        }
+
+        /// <summary>
+        /// Start watching, failing the test with the log if it doesn't
+        /// </summary>
+        public async Task StartAsync()
+        {
+            if (!await Service.Start())
+                throw new InvalidOperationException($"Start failed\n{LogText}");
+        }
+
+        public string PathOf(string relativePath) => Path.Combine(WatchDir, relativePath);
+
+        public string WriteFile(string relativePath, string content = "solid cube\nendsolid cube\n")
+        {
+            var path = PathOf(relativePath);
+            Directory.CreateDirectory(Path.GetDirectoryName(path)!);
+            File.WriteAllText(path, content);
+            return path;
+        }
+
+        public string LogText => string.Join("\n", Logs.Select(l => $"[{l.level}] {l.message}"));
+
+        public void Dispose()
+        {
+            Service.Dispose();
+            try
+            {
+                Directory.Delete(root, recursive: true);
+            }
+            catch (IOException)
+            {
+                // Left for the OS to clean up
+            }
+        }
+    }
+}
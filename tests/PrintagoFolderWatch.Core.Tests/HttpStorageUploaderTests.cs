@@ -0,0 +1,54 @@
+using System.IO;
+using System.Net;
+using System.Net.Http;
+using System.Security.Cryptography;
+using System.Text;
+using System.Threading;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class HttpStorageUploaderTests
+    {
+        [Fact]
+        public async Task PutAsync_SendsTheFileWithItsHeaders()
+        {
+            var handler = new FakeHttpHandler(_ => new HttpResponseMessage(HttpStatusCode.OK));
+            var uploader = new HttpStorageUploader(new HttpClient(handler));
+            var bytes = Encoding.ASCII.GetBytes("solid cube\nendsolid cube\n");
+            var md5 = MD5.HashData(bytes);
+
+            using var response = await uploader.PutAsync("https://storage.test/upload?sig=1", new MemoryStream(bytes), bytes.Length,
+                "model/stl", md5, CancellationToken.None);
+
+            Assert.Equal(HttpStatusCode.OK, response.StatusCode);
+            var sent = Assert.Single(handler.Requests);
+            Assert.Equal(HttpMethod.Put, sent.Method);
+            Assert.Equal("https://storage.test/upload?sig=1", sent.Uri.ToString());
+            Assert.Equal(bytes, sent.BodyBytes);
+            Assert.Equal("model/stl", sent.ContentHeaders!.ContentType!.MediaType);
+            Assert.Equal(bytes.Length, sent.ContentHeaders.ContentLength);
+            Assert.Equal(md5, sent.ContentHeaders.ContentMD5);
+            // Signed URLs carry their own authorization - the API key must never go to storage
+            Assert.False(sent.Headers.ContainsKey("authorization"));
+        }
+
+        [Theory]
+        [InlineData("model/3mf", "model/3mf")]
+        [InlineData("not a content type", "application/octet-stream")]
+        public async Task PutAsync_FallsBackToOctetStream(string contentType, string expected)
+        {
+            var handler = new FakeHttpHandler(_ => new HttpResponseMessage(HttpStatusCode.OK));
+            var uploader = new HttpStorageUploader(new HttpClient(handler));
+
+            using var response = await uploader.PutAsync("https://storage.test/upload", new MemoryStream(new byte[3]), 3,
+                contentType, null, CancellationToken.None);
+
+            var sent = Assert.Single(handler.Requests);
+            Assert.Equal(expected, sent.ContentHeaders!.ContentType!.MediaType);
+            Assert.Null(sent.ContentHeaders.ContentMD5);
+        }
+    }
+}
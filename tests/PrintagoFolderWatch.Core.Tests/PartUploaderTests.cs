@@ -0,0 +1,181 @@
+using System;
+using System.IO;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Threading;
+using System.Threading.Tasks;
+using Newtonsoft.Json.Linq;
+using PrintagoFolderWatch.Core.Models;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class PartUploaderTests
+    {
+        private readonly FakePrintago api = new();
+        private readonly FakeStorage storage = new();
+        private readonly Config config = new()
+        {
+            ApiUrl = FakePrintago.ApiUrl,
+            ApiKey = "test-key-123",
+            StoreId = "store-1",
+            RequestsPerSecond = 0,
+            MaxRetries = 1
+        };
+
+        private PartUploader CreateUploader()
+        {
+            var apiClient = new PrintagoApiClient(new HttpClient(api), () => config);
+            return new PartUploader(apiClient, storage, 2, () => config);
+        }
+
+        private static PartUpload CreateUpload(string? existingPartId = null)
+        {
+            var filePath = Path.Combine(TestEnvironment.CreateTempDirectory("part-uploader"), "cube.stl");
+            File.WriteAllText(filePath, "solid cube\nendsolid cube\n");
+            return new PartUpload
+            {
+                FilePath = filePath,
+                CloudPath = "parts/cube.stl",
+                Key = "parts/cube.stl",
+                PartName = "cube",
+                FolderId = "folder-1",
+                ExistingPartId = existingPartId
+            };
+        }
+
+        [Fact]
+        public async Task UploadAsync_CreatesAPartForTheStoredFile()
+        {
+            var upload = CreateUpload();
+            var progress = new UploadProgress { FileSizeBytes = new FileInfo(upload.FilePath).Length };
+
+            var (result, partId, httpStatus) = await CreateUploader().UploadAsync(upload, progress, CancellationToken.None);
+
+            Assert.Equal(UploadResult.Success, result);
+            Assert.StartsWith("id-", partId);
+            Assert.Null(httpStatus);
+            Assert.Equal(FakePrintago.StorageUrl + "parts/cube.stl", Assert.Single(storage.Puts).UploadUrl);
+            Assert.Equal(progress.FileSizeBytes, progress.BytesSent);
+
+            var part = JObject.Parse(Assert.Single(api.RequestsTo(HttpMethod.Post, "/parts")).Body);
+            Assert.Equal("cube", (string?)part["name"]);
+            Assert.Equal("stl", (string?)part["type"]);
+            Assert.Equal("folder-1", (string?)part["folderId"]);
+            Assert.Equal(new[] { "stores/store-1/parts/cube.stl" }, part["fileUris"]!.Values<string>());
+        }
+
+        [Fact]
+        public async Task UploadAsync_PutsTheNewFileOnTheExistingPart()
+        {
+            var upload = CreateUpload(existingPartId: "part-7");
+
+            var (result, partId, _) = await CreateUploader().UploadAsync(upload, new UploadProgress(), CancellationToken.None);
+
+            Assert.Equal(UploadResult.Success, result);
+            Assert.Equal("part-7", partId);
+            Assert.Empty(api.RequestsTo(HttpMethod.Post, "/parts"));
+            var patch = JObject.Parse(Assert.Single(api.RequestsTo(HttpMethod.Patch, "/parts/part-7")).Body);
+            Assert.Equal(new[] { "stores/store-1/parts/cube.stl" }, patch["fileUris"]!.Values<string>());
+        }
+
+        [Fact]
+        public async Task UploadAsync_StorageErrorIsTransientWithItsStatus()
+        {
+            storage.OnPut = (_, _) => Task.FromResult(new HttpResponseMessage(HttpStatusCode.ServiceUnavailable));
+            var progress = new UploadProgress();
+
+            var (result, _, httpStatus) = await CreateUploader().UploadAsync(CreateUpload(), progress, CancellationToken.None);
+
+            Assert.Equal(UploadResult.TransientFailure, result);
+            Assert.Equal(503, httpStatus);
+            Assert.StartsWith("Upload failed: HTTP 503", progress.Status);
+            Assert.Empty(api.RequestsTo(HttpMethod.Post, "/parts"));
+        }
+
+        [Theory]
+        [InlineData(HttpStatusCode.OK, @"{ ""fileHashes"": [""ABCDEF0123456789""] }", null)]
+        [InlineData(HttpStatusCode.OK, @"{ ""fileHashes"": [""9876543210fedcba""] }", "Printago has 9876543210fe…, the file is abcdef012345…")]
+        [InlineData(HttpStatusCode.NotFound, "{}", "Part part-7 doesn't exist")]
+        public async Task VerifyPartAsync_ComparesTheFileHash(HttpStatusCode status, string part, string? expected)
+        {
+            api.Override = request => request.Method == HttpMethod.Get && request.Path.EndsWith("/parts/part-7")
+                ? FakeHttpHandler.Json(status, part)
+                : null;
+
+            var error = await CreateUploader().VerifyPartAsync("part-7", "abcdef0123456789", CancellationToken.None);
+
+            Assert.Equal(expected, error);
+        }
+
+        [Fact]
+        public async Task RunWithRetriesAsync_GivesUpAfterMaxRetries()
+        {
+            var uploader = CreateUploader();
+            var attempts = 0;
+            var retrying = 0;
+
+            var (result, attemptsMade) = await uploader.RunWithRetriesAsync("cube.stl", _ =>
+            {
+                attempts++;
+                retrying = Math.Max(retrying, uploader.RetryingCount);
+                return Task.FromResult(UploadResult.TransientFailure);
+            }, (_, _) => Task.FromResult(false), CancellationToken.None);
+
+            Assert.Equal(UploadResult.TransientFailure, result);
+            Assert.Equal(2, attemptsMade);
+            Assert.Equal(2, attempts);
+            Assert.Equal(1, retrying);
+            Assert.Equal(0, uploader.RetryingCount);
+        }
+
+        [Fact]
+        public async Task RunWithRetriesAsync_WaitedOutFailuresAreNotAttempts()
+        {
+            var results = new[] { UploadResult.NetworkFailure, UploadResult.AuthFailure, UploadResult.Success };
+            var attempts = 0;
+
+            var (result, attemptsMade) = await CreateUploader().RunWithRetriesAsync("cube.stl",
+                _ => Task.FromResult(results[attempts++]), (_, _) => Task.FromResult(true), CancellationToken.None);
+
+            Assert.Equal(UploadResult.Success, result);
+            Assert.Equal(1, attemptsMade);
+            Assert.Equal(3, attempts);
+        }
+
+        [Fact]
+        public async Task RunWithRetriesAsync_NetworkFailureNotWaitedOutIsTransient()
+        {
+            config.MaxRetries = 0;
+
+            var (result, attemptsMade) = await CreateUploader().RunWithRetriesAsync("cube.stl",
+                _ => Task.FromResult(UploadResult.NetworkFailure), (_, _) => Task.FromResult(false), CancellationToken.None);
+
+            Assert.Equal(UploadResult.TransientFailure, result);
+            Assert.Equal(1, attemptsMade);
+        }
+
+        [Fact]
+        public async Task RunWithRetriesAsync_HoldsOnlyMaxParallelSlots()
+        {
+            var uploader = CreateUploader();
+            var sync = new object();
+            var running = 0;
+            var maxRunning = 0;
+
+            await Task.WhenAll(Enumerable.Range(0, 5).Select(_ => uploader.RunWithRetriesAsync("cube.stl", async _ =>
+            {
+                lock (sync)
+                    maxRunning = Math.Max(maxRunning, ++running);
+                await Task.Delay(50);
+                lock (sync)
+                    running--;
+                return UploadResult.Success;
+            }, (_, _) => Task.FromResult(false), CancellationToken.None)));
+
+            Assert.Equal(2, maxRunning);
+        }
+    }
+}
@@ -0,0 +1,32 @@
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class PathFilterTests
+    {
+        [Theory]
+        [InlineData("cube (1).3mf", true)]
+        [InlineData("cube (12).gcode.3mf", true)]
+        [InlineData("cube-PC01.stl", true)]
+        [InlineData("cube-pc01.stl", true)]
+        [InlineData("cube.stl", false)]
+        [InlineData("cube (v2).3mf", false)]
+        [InlineData("cube(1).3mf", false)]
+        [InlineData("cube-v2.stl", false)]
+        [InlineData("PC01.stl", false)]
+        public void IsCopyName_MatchesNumberedAndComputerNamedCopies(string fileName, bool expected)
+        {
+            Assert.Equal(expected, PathFilter.IsCopyName(fileName, "PC01"));
+        }
+
+        [Theory]
+        [InlineData("~$cube.stl", true)]
+        [InlineData("cube.stl.tmp", true)]
+        [InlineData("drafts/Thumbs.db", true)]
+        [InlineData("cube.stl", false)]
+        public void BuiltInPatterns_SkipTempAndSystemFiles(string relativePath, bool expected)
+        {
+            Assert.Equal(expected, new PathFilter(PathFilter.BUILT_IN_PATTERNS).IsExcluded(relativePath));
+        }
+    }
+}
@@ -0,0 +1,250 @@
+using System;
+using System.Collections.Generic;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Net.Http.Headers;
+using System.Threading.Tasks;
+using Newtonsoft.Json.Linq;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class PrintagoApiClientTests
+    {
+        private const string ApiKey = "test-key-123";
+
+        private static Config CreateConfig(double requestsPerSecond = 0) => new()
+        {
+            ApiUrl = "https://api.test/",
+            ApiKey = ApiKey,
+            StoreId = "store-1",
+            RequestsPerSecond = requestsPerSecond
+        };
+
+        private static PrintagoApiClient CreateClient(FakeHttpHandler handler, Config? config = null)
+        {
+            config ??= CreateConfig();
+            return new PrintagoApiClient(new HttpClient(handler), () => config);
+        }
+
+        [Fact]
+        public async Task SendAsync_AddsKeyAndStoreHeaders()
+        {
+            var handler = new FakeHttpHandler(_ => FakeHttpHandler.Json("[]"));
+            var client = CreateClient(handler);
+
+            var request = new HttpRequestMessage(HttpMethod.Get, client.GetUrl("folders"));
+            client.AddApiHeaders(request);
+            using var response = await client.SendAsync(request);
+
+            var sent = Assert.Single(handler.Requests);
+            Assert.Equal("https://api.test/v1/folders", sent.Uri.ToString());
+            Assert.Equal($"ApiKey {ApiKey}", sent.Headers["authorization"]);
+            Assert.Equal("store-1", sent.Headers["x-printago-storeid"]);
+        }
+
+        [Fact]
+        public async Task SendAsync_UsesTheConfiguredPathsAndHeaders()
+        {
+            var handler = new FakeHttpHandler(_ => FakeHttpHandler.Json(@"{ ""signedUrls"": [] }"));
+            var config = CreateConfig();
+            config.ApiBasePath = "/v2";
+            config.SignedUploadUrlsPath = "uploads/signed";
+            config.ApiKeyHeader = "x-api-key";
+            config.ApiKeyScheme = "";
+            config.StoreIdHeader = "x-store";
+            var client = CreateClient(handler, config);
+
+            await client.GetSignedUploadUrlsAsync(new[] { "a/one.stl" });
+
+            var sent = Assert.Single(handler.Requests);
+            Assert.Equal("https://api.test/v2/uploads/signed", sent.Uri.ToString());
+            Assert.Equal(ApiKey, sent.Headers["x-api-key"]);
+            Assert.Equal("store-1", sent.Headers["x-store"]);
+            Assert.False(sent.Headers.ContainsKey("authorization"));
+        }
+
+        [Fact]
+        public async Task GetSignedUploadUrlsAsync_MatchesEntriesByFilenameNotPosition()
+        {
+            var handler = new FakeHttpHandler(_ => FakeHttpHandler.Json(@"{ ""signedUrls"": [
+                { ""filename"": ""b/two.stl"", ""uploadUrl"": ""https://storage.test/2"", ""path"": ""stores/1/b/two.stl"" },
+                { ""filename"": ""a/one.stl"", ""uploadUrl"": ""https://storage.test/1"", ""path"": ""stores/1/a/one.stl"" }
+            ] }"));
+            var client = CreateClient(handler);
+
+            var urls = await client.GetSignedUploadUrlsAsync(new[] { "a/one.stl", "b/two.stl" });
+
+            Assert.NotNull(urls);
+            Assert.Equal(("https://storage.test/1", "stores/1/a/one.stl"), urls!["a/one.stl"]);
+            Assert.Equal(("https://storage.test/2", "stores/1/b/two.stl"), urls["b/two.stl"]);
+
+            var body = JObject.Parse(Assert.Single(handler.Requests).Body);
+            Assert.Equal(new[] { "a/one.stl", "b/two.stl" }, body["filenames"]!.Values<string>());
+        }
+
+        [Fact]
+        public async Task GetSignedUploadUrlsAsync_NoUrlsIsNull()
+        {
+            var client = CreateClient(new FakeHttpHandler(_ => FakeHttpHandler.Json(@"{ ""signedUrls"": [] }")));
+
+            Assert.Null(await client.GetSignedUploadUrlsAsync(new[] { "one.stl" }));
+        }
+
+        [Theory]
+        [InlineData(HttpStatusCode.BadRequest)]
+        [InlineData(HttpStatusCode.Unauthorized)]
+        [InlineData(HttpStatusCode.Forbidden)]
+        [InlineData(HttpStatusCode.InternalServerError)]
+        public async Task GetSignedUploadUrlsAsync_FailureThrowsWithStatusAndMessage(HttpStatusCode status)
+        {
+            var client = CreateClient(new FakeHttpHandler(_ =>
+                FakeHttpHandler.Json(status, @"{ ""message"": ""Nope"" }")));
+
+            var ex = await Assert.ThrowsAsync<HttpRequestException>(() => client.GetSignedUploadUrlsAsync(new[] { "one.stl" }));
+
+            Assert.Equal(status, ex.StatusCode);
+            Assert.Contains($"HTTP {(int)status} - Nope", ex.Message);
+        }
+
+        [Fact]
+        public async Task SendAsync_PacesRequestsToRequestsPerSecond()
+        {
+            var handler = new FakeHttpHandler(_ => FakeHttpHandler.Json("[]"));
+            var client = CreateClient(handler, CreateConfig(requestsPerSecond: 5));
+
+            // Sent together, as parallel workers would
+            await Task.WhenAll(Enumerable.Range(0, 3).Select(async _ =>
+            {
+                using var response = await client.SendAsync(new HttpRequestMessage(HttpMethod.Get, client.GetUrl("parts")));
+            }));
+
+            var times = handler.Requests.Select(r => r.ReceivedUtc).OrderBy(t => t).ToList();
+            Assert.Equal(3, times.Count);
+            for (int i = 1; i < times.Count; i++)
+            {
+                Assert.True(times[i] - times[i - 1] >= TimeSpan.FromMilliseconds(190), $"{(times[i] - times[i - 1]).TotalMilliseconds}ms apart");
+            }
+        }
+
+        [Theory]
+        [InlineData(0, 0)]
+        [InlineData(-1, 0)]
+        [InlineData(double.NaN, 0)]
+        [InlineData(5, 0.2)]
+        [InlineData(0.5, 2)]
+        [InlineData(1e-300, 100)] // Clamped instead of overflowing TimeSpan
+        public void GetMinimumApiInterval_IsOneOverRequestsPerSecond(double requestsPerSecond, double expectedSeconds)
+        {
+            Assert.Equal(TimeSpan.FromSeconds(expectedSeconds), CreateConfig(requestsPerSecond).GetMinimumApiInterval());
+        }
+
+        [Fact]
+        public async Task SendAsync_RetriesAfterRateLimitWithRetryAfter()
+        {
+            var calls = 0;
+            var handler = new FakeHttpHandler(request =>
+            {
+                if (request.Body != "{}")
+                    return FakeHttpHandler.Json(HttpStatusCode.BadRequest, "{}"); // The retry must resend the body
+                if (++calls > 1)
+                    return FakeHttpHandler.Json(@"{ ""ok"": true }");
+
+                var response = FakeHttpHandler.Json(HttpStatusCode.TooManyRequests, "{}");
+                response.Headers.RetryAfter = new RetryConditionHeaderValue(TimeSpan.FromSeconds(1));
+                return response;
+            });
+            var client = CreateClient(handler);
+            var rateLimited = new List<DateTime>();
+            client.OnRateLimited += until => rateLimited.Add(until);
+
+            var request = new HttpRequestMessage(HttpMethod.Post, client.GetUrl("parts")) { Content = new StringContent("{}") };
+            client.AddApiHeaders(request);
+            using var response = await client.SendAsync(request);
+
+            Assert.Equal(HttpStatusCode.OK, response.StatusCode);
+            var requests = handler.Requests.ToList();
+            Assert.Equal(2, requests.Count);
+            Assert.True(requests[1].ReceivedUtc - requests[0].ReceivedUtc >= TimeSpan.FromMilliseconds(900));
+            Assert.Equal($"ApiKey {ApiKey}", requests[1].Headers["authorization"]);
+            Assert.Single(rateLimited);
+        }
+
+        [Fact]
+        public async Task SendAsync_GivesUpAfterThreeRateLimitRetries()
+        {
+            var handler = new FakeHttpHandler(_ =>
+            {
+                var response = FakeHttpHandler.Json(HttpStatusCode.TooManyRequests, "{}");
+                response.Headers.RetryAfter = new RetryConditionHeaderValue(TimeSpan.FromMilliseconds(50));
+                return response;
+            });
+            var client = CreateClient(handler);
+
+            using var response = await client.SendAsync(new HttpRequestMessage(HttpMethod.Get, client.GetUrl("parts")));
+
+            Assert.Equal(HttpStatusCode.TooManyRequests, response.StatusCode);
+            Assert.Equal(4, handler.Requests.Count);
+        }
+
+        [Theory]
+        [InlineData(@"{ ""message"": ""Invalid API key"" }", "Invalid API key")]
+        [InlineData(@"{ ""error"": ""Store not found"" }", "Store not found")]
+        [InlineData(@"{ ""error"": { ""message"": ""Bad file type"" } }", "Bad file type")]
+        [InlineData(@"{ ""error"": { ""code"": ""quota_exceeded"" } }", "quota_exceeded")]
+        [InlineData("<Error><Code>SignatureDoesNotMatch</Code><Message>The signature is wrong</Message></Error>", "The signature is wrong")]
+        [InlineData("<Error><Code>AccessDenied</Code></Error>", "AccessDenied")]
+        [InlineData("<html><body>Bad gateway</body></html>", null)]
+        [InlineData("Service unavailable", "Service unavailable")]
+        [InlineData("{ not json", null)]
+        public void ParseErrorMessage_FindsTheMessage(string body, string? expected)
+        {
+            Assert.Equal(expected, PrintagoApiClient.ParseErrorMessage(body));
+        }
+
+        [Fact]
+        public async Task ReadErrorResponse_RedactsTheKeyAndTruncatesTheBody()
+        {
+            var client = CreateClient(new FakeHttpHandler(_ => FakeHttpHandler.Json("{}")));
+            using var response = FakeHttpHandler.Json(HttpStatusCode.Unauthorized,
+                $@"{{ ""message"": ""Key {ApiKey} is invalid"", ""padding"": ""{new string('x', 1000)}"" }}");
+
+            var (description, logText) = await client.ReadErrorResponse(response);
+
+            Assert.Equal("HTTP 401 - Key **** is invalid", description);
+            Assert.DoesNotContain(ApiKey, logText);
+            Assert.True(logText.Length < 700);
+        }
+
+        [Theory]
+        [InlineData(HttpStatusCode.TooManyRequests, false, true)]
+        [InlineData(HttpStatusCode.ServiceUnavailable, true, true)]
+        [InlineData(HttpStatusCode.ServiceUnavailable, false, false)]
+        [InlineData(HttpStatusCode.InternalServerError, true, false)]
+        public void IsRateLimitResponse(HttpStatusCode status, bool retryAfter, bool expected)
+        {
+            using var response = new HttpResponseMessage(status);
+            if (retryAfter)
+                response.Headers.RetryAfter = new RetryConditionHeaderValue(TimeSpan.FromSeconds(5));
+
+            Assert.Equal(expected, PrintagoApiClient.IsRateLimitResponse(response));
+        }
+
+        [Theory]
+        [InlineData(null, 0, 4)]
+        [InlineData(null, 2, 16)]
+        [InlineData(null, 10, 60)]
+        [InlineData(7, 0, 7)]
+        [InlineData(3600, 0, 60)]
+        public void GetRateLimitDelay_UsesRetryAfterCappedOrBacksOff(int? retryAfterSeconds, int retryCount, int expectedSeconds)
+        {
+            using var response = new HttpResponseMessage(HttpStatusCode.TooManyRequests);
+            if (retryAfterSeconds != null)
+                response.Headers.RetryAfter = new RetryConditionHeaderValue(TimeSpan.FromSeconds(retryAfterSeconds.Value));
+
+            Assert.Equal(TimeSpan.FromSeconds(expectedSeconds), PrintagoApiClient.GetRateLimitDelay(response, retryCount));
+        }
+    }
+}
@@ -0,0 +1,69 @@
+using System.Collections.Generic;
+using System.Linq;
+using Newtonsoft.Json.Linq;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class SignedUrlMatchTests
+    {
+        public static IEnumerable<object[]> Responses => new List<object[]>
+        {
+            // Filenames echoed back, in a different order from the request
+            new object[]
+            {
+                new[] { "a/one.stl", "b/two.stl" },
+                @"[ { ""filename"": ""b/two.stl"", ""uploadUrl"": ""https://storage.test/2"", ""path"": ""stores/1/b/two.stl"" },
+                    { ""filename"": ""a/one.stl"", ""uploadUrl"": ""https://storage.test/1"", ""path"": ""stores/1/a/one.stl"" } ]",
+                new[] { "a/one.stl=https://storage.test/1", "b/two.stl=https://storage.test/2" }
+            },
+            // No filenames: "one.stl" is also the end of "a/one.stl" - the shortest matching path is the one asked for
+            new object[]
+            {
+                new[] { "one.stl", "a/one.stl" },
+                @"[ { ""uploadUrl"": ""https://storage.test/2"", ""path"": ""stores/1/a/one.stl"" },
+                    { ""uploadUrl"": ""https://storage.test/1"", ""path"": ""stores/1/one.stl"" } ]",
+                new[] { "a/one.stl=https://storage.test/2", "one.stl=https://storage.test/1" }
+            },
+            new object[]
+            {
+                new[] { "one.stl" },
+                @"[ { ""fileName"": ""one.stl"", ""uploadUrl"": ""https://storage.test/1"", ""path"": ""stores/1/one.stl"" } ]",
+                new[] { "one.stl=https://storage.test/1" }
+            },
+            // A single file's URL is its own, whatever the entry says
+            new object[]
+            {
+                new[] { "one.stl" },
+                @"[ { ""uploadUrl"": ""https://storage.test/1"", ""path"": ""uploads/8f3a"" } ]",
+                new[] { "one.stl=https://storage.test/1" }
+            },
+            // Two entries that can't be told apart (reversed, as it happens) - neither file gets one
+            new object[]
+            {
+                new[] { "a/one.stl", "b/two.stl" },
+                @"[ { ""uploadUrl"": ""https://storage.test/2"", ""path"": ""uploads/2"" },
+                    { ""uploadUrl"": ""https://storage.test/1"", ""path"": ""uploads/1"" } ]",
+                new string[0]
+            },
+            // The only entry left after matching the others by name belongs to the only file left
+            new object[]
+            {
+                new[] { "a/one.stl", "b/two.stl", "c/three.stl" },
+                @"[ { ""uploadUrl"": ""https://storage.test/3"", ""path"": ""uploads/3"" },
+                    { ""filename"": ""a/one.stl"", ""uploadUrl"": ""https://storage.test/1"", ""path"": ""uploads/1"" },
+                    { ""filename"": ""b/two.stl"", ""uploadUrl"": ""https://storage.test/2"", ""path"": ""uploads/2"" } ]",
+                new[] { "a/one.stl=https://storage.test/1", "b/two.stl=https://storage.test/2", "c/three.stl=https://storage.test/3" }
+            },
+        };
+
+        [Theory]
+        [MemberData(nameof(Responses))]
+        public void MatchSignedUrls_GivesEachFileItsOwnUrl(string[] cloudPaths, string signedUrls, string[] expected)
+        {
+            var urls = PrintagoApiClient.MatchSignedUrls(cloudPaths, JArray.Parse(signedUrls));
+
+            Assert.Equal(expected, urls.OrderBy(u => u.Key).Select(u => $"{u.Key}={u.Value.uploadUrl}"));
+        }
+    }
+}
@@ -0,0 +1,73 @@
+using System.Collections.Generic;
+using System.IO;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class SpillQueueTests
+    {
+        private static List<string> Drain(SpillQueue queue)
+        {
+            var items = new List<string>();
+            while (queue.TryDequeue(out var item))
+                items.Add(item);
+            return items;
+        }
+
+        [Fact]
+        public void SpillsPastTheLimitAndKeepsTheOrder()
+        {
+            var journal = Path.Combine(TestEnvironment.CreateTempDirectory("spill"), "live.queue");
+            using var queue = new SpillQueue(journal, () => 2);
+            var items = new[] { "a.stl", "b.stl", "line\nbreak.stl", "d.stl", "e.stl" };
+            foreach (var item in items)
+                queue.Enqueue(item);
+
+            Assert.Equal(5, queue.Count);
+            Assert.Equal(3, queue.SpilledCount);
+            Assert.Equal(new[] { "a.stl", "b.stl" }, queue.PeekInMemory());
+            Assert.True(File.Exists(journal));
+
+            Assert.True(queue.TryDequeue(out var first));
+            Assert.Equal("a.stl", first);
+            // Queued behind the journal, not in the memory slot that just freed up
+            queue.Enqueue("f.stl");
+
+            Assert.Equal(new[] { "b.stl", "line\nbreak.stl", "d.stl", "e.stl", "f.stl" }, Drain(queue));
+            Assert.Equal(0, queue.Count);
+            Assert.False(File.Exists(journal));
+        }
+
+        [Fact]
+        public void KeepsEverythingInMemoryWhenTheJournalCantBeWritten()
+        {
+            // A file where the journal's folder should be
+            var blocker = Path.Combine(TestEnvironment.CreateTempDirectory("spill"), "blocked");
+            File.WriteAllText(blocker, "");
+            var errors = new List<string>();
+            using var queue = new SpillQueue(Path.Combine(blocker, "live.queue"), () => 1, errors.Add);
+
+            foreach (var item in new[] { "a.stl", "b.stl", "c.stl" })
+                queue.Enqueue(item);
+
+            Assert.Equal(3, queue.Count);
+            Assert.Equal(2, queue.SpilledCount);
+            Assert.Single(errors);
+            Assert.Equal(new[] { "a.stl", "b.stl", "c.stl" }, Drain(queue));
+        }
+
+        [Fact]
+        public void NeverSpillsWithinTheLimit()
+        {
+            var journal = Path.Combine(TestEnvironment.CreateTempDirectory("spill"), "live.queue");
+            using var queue = new SpillQueue(journal, () => 10);
+
+            queue.Enqueue("a.stl");
+            queue.Enqueue("b.stl");
+
+            Assert.Equal(0, queue.SpilledCount);
+            Assert.False(File.Exists(journal));
+            Assert.Equal(new[] { "a.stl", "b.stl" }, Drain(queue));
+        }
+    }
+}
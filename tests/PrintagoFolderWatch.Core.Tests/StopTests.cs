@@ -0,0 +1,41 @@
+using System;
+using System.Diagnostics;
+using System.Threading;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class StopTests
+    {
+        [Fact]
+        public async Task Stop_CancelsASlowUploadAndKeepsItPending()
+        {
+            // Only interrupted uploads are queued on the next start, so the file coming back proves it was kept
+            using var harness = new ServiceHarness(config => config.SkipInitialScan = true);
+            harness.Storage.OnPut = async (put, ct) =>
+            {
+                await Task.Delay(Timeout.Infinite, ct);
+                throw new InvalidOperationException("not reached");
+            };
+            await harness.StartAsync();
+
+            harness.WriteFile("cube.stl");
+            await TestEnvironment.WaitUntil(() => harness.Storage.StartedCount > 0, TimeSpan.FromSeconds(15), "the PUT to start");
+
+            var stopwatch = Stopwatch.StartNew();
+            harness.Service.Stop();
+            Assert.True(stopwatch.Elapsed < TimeSpan.FromSeconds(1), $"Stop took {stopwatch.Elapsed}");
+            await TestEnvironment.WaitUntil(() => harness.Service.PendingUploadCount == 0, TimeSpan.FromSeconds(2), "the upload to be cancelled");
+            Assert.Empty(harness.Service.GetFailedUploads());
+
+            harness.Storage.OnPut = null;
+            await harness.StartAsync();
+
+            await TestEnvironment.WaitUntil(() => harness.Storage.Puts.Count > 0, TimeSpan.FromSeconds(15), "the interrupted upload");
+            Assert.Equal("cube.stl", Assert.Single(harness.Storage.UploadedNames));
+            Assert.Contains(harness.Logs, l => l.message.Contains("interrupted when watching last stopped"));
+        }
+    }
+}
@@ -0,0 +1,75 @@
+using System;
+using System.IO;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class TempFileSaveTests
+    {
+        [Theory]
+        [InlineData("cube.stl.tmp")]
+        [InlineData("cube.stl.partial")]
+        [InlineData("~$cube.stl")]
+        public async Task WriteToTempThenRename_UploadsOnlyTheFinalName(string tempName)
+        {
+            using var harness = new ServiceHarness();
+            await harness.StartAsync();
+
+            // How slicers save: write a temp file in pieces, then rename it over the real name
+            var tempPath = harness.PathOf(tempName);
+            using (var stream = File.Create(tempPath))
+            {
+                for (int i = 0; i < 5; i++)
+                {
+                    await stream.WriteAsync(new byte[4096]);
+                    await stream.FlushAsync();
+                    await Task.Delay(50);
+                }
+            }
+            // Longer than the quiet period, so the temp file would be queued if it weren't skipped
+            await Task.Delay(harness.Config.DebounceMs * 3);
+            File.Move(tempPath, harness.PathOf("cube.stl"));
+
+            await TestEnvironment.WaitUntil(() => harness.Storage.Puts.Count > 0, TimeSpan.FromSeconds(15), "the upload");
+            await Task.Delay(1500);
+
+            Assert.Equal(new[] { "cube.stl" }, harness.Storage.UploadedNames);
+            Assert.Equal(5 * 4096, Assert.Single(harness.Storage.Puts).Content.Length);
+        }
+
+        [Fact]
+        public async Task RenameToAnUnsyncedName_IsNotUploaded()
+        {
+            using var harness = new ServiceHarness();
+            await harness.StartAsync();
+
+            var path = harness.WriteFile("cube.stl");
+            await Task.Delay(100);
+            File.Move(path, harness.PathOf("cube.stl.bak"));
+
+            await Task.Delay(harness.Config.DebounceMs * 2 + 2500);
+
+            Assert.Equal(0, harness.Storage.StartedCount);
+            Assert.Equal(0, harness.Service.UploadQueueCount);
+        }
+
+        [Theory]
+        [InlineData("cube (1).stl")]
+        [InlineData("cube-{0}.stl")]
+        public async Task ConflictAndAutosaveCopies_AreNotUploaded(string copyName)
+        {
+            using var harness = new ServiceHarness();
+            await harness.StartAsync();
+
+            harness.WriteFile("cube.stl");
+            harness.WriteFile(string.Format(copyName, Environment.MachineName));
+
+            await TestEnvironment.WaitUntil(() => harness.Storage.Puts.Count > 0, TimeSpan.FromSeconds(15), "the upload");
+            await Task.Delay(harness.Config.DebounceMs * 2 + 2500);
+
+            Assert.Equal(new[] { "cube.stl" }, harness.Storage.UploadedNames);
+        }
+    }
+}
@@ -0,0 +1,55 @@
+using System;
+using System.IO;
+using System.Threading.Tasks;
+using Xunit;
+
+// Config.ConfigFile and the command line are process-wide, and the service tests time real file events
+[assembly: CollectionBehavior(DisableTestParallelization = true)]
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    internal static class TestEnvironment
+    {
+        private static readonly object sync = new();
+        private static string? configDir;
+
+        /// <summary>
+        /// Point Config at a temporary config file (so logs, failed.json and queue journals stay out of the
+        /// user's profile) with the OS credential store left alone. Returns the folder it's in.
+        /// </summary>
+        public static string UseTempConfig()
+        {
+            lock (sync)
+            {
+                configDir ??= CreateTempDirectory("config");
+                Config.UseCommandLine(new CommandLineOptions
+                {
+                    ConfigPath = Path.Combine(configDir, "config.json"),
+                    NoKeychain = true
+                });
+                return configDir;
+            }
+        }
+
+        public static string CreateTempDirectory(string name)
+        {
+            var path = Path.Combine(Path.GetTempPath(), "printago-tests", $"{name}-{Guid.NewGuid():N}");
+            Directory.CreateDirectory(path);
+            return path;
+        }
+
+        /// <summary>
+        /// Poll until condition is true, or fail after timeout
+        /// </summary>
+        public static async Task WaitUntil(Func<bool> condition, TimeSpan timeout, string description)
+        {
+            var deadline = DateTime.UtcNow + timeout;
+            while (!condition())
+            {
+                if (DateTime.UtcNow > deadline)
+                    throw new TimeoutException($"Timed out after {timeout.TotalSeconds}s waiting for {description}");
+                await Task.Delay(50);
+            }
+        }
+    }
+}
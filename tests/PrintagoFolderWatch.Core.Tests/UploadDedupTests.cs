@@ -0,0 +1,58 @@
+using System;
+using System.IO;
+using System.Text;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class UploadDedupTests
+    {
+        [Fact]
+        public async Task TenEventsForOneFile_UploadOnce()
+        {
+            using var harness = new ServiceHarness();
+            await harness.StartAsync();
+
+            var path = harness.PathOf("cube.stl");
+            var content = new StringBuilder();
+            for (int i = 0; i < 10; i++)
+            {
+                content.AppendLine($"facet {i}");
+                File.WriteAllText(path, content.ToString());
+                await Task.Delay(30);
+            }
+
+            await TestEnvironment.WaitUntil(() => harness.Storage.Puts.Count > 0, TimeSpan.FromSeconds(15), "the upload");
+            await Task.Delay(harness.Config.DebounceMs * 2 + 2500);
+
+            var put = Assert.Single(harness.Storage.Puts);
+            Assert.Equal(content.ToString(), Encoding.UTF8.GetString(put.Content));
+        }
+
+        [Fact]
+        public void EnqueueUpload_QueuesAPathOnce()
+        {
+            using var harness = new ServiceHarness();
+            var path = harness.WriteFile("cube.stl");
+
+            Assert.True(harness.Service.EnqueueUpload(path));
+            for (int i = 0; i < 9; i++)
+            {
+                Assert.False(harness.Service.EnqueueUpload(path));
+            }
+
+            Assert.Equal(1, harness.Service.UploadQueueCount);
+        }
+
+        [Fact]
+        public void EnqueueUpload_SkipsMissingFiles()
+        {
+            using var harness = new ServiceHarness();
+
+            Assert.False(harness.Service.EnqueueUpload(harness.PathOf("gone.stl")));
+            Assert.Equal(0, harness.Service.UploadQueueCount);
+        }
+    }
+}
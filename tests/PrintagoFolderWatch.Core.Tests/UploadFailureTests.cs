@@ -0,0 +1,128 @@
+using System;
+using System.IO;
+using System.Linq;
+using System.Net;
+using System.Net.Http;
+using System.Net.Http.Headers;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class UploadFailureTests
+    {
+        [Theory]
+        [InlineData(HttpStatusCode.BadRequest, false)]
+        [InlineData(HttpStatusCode.NotFound, false)]
+        [InlineData(HttpStatusCode.RequestEntityTooLarge, false)]
+        [InlineData(HttpStatusCode.RequestTimeout, true)]
+        [InlineData(HttpStatusCode.TooManyRequests, true)]
+        [InlineData(HttpStatusCode.InternalServerError, true)]
+        [InlineData(HttpStatusCode.BadGateway, true)]
+        [InlineData(HttpStatusCode.ServiceUnavailable, true)]
+        public void ClassifyFailure_RetriesOnlyWhatCanSucceedLater(HttpStatusCode status, bool retried)
+        {
+            var expected = retried ? UploadResult.TransientFailure : UploadResult.PermanentFailure;
+            Assert.Equal(expected, PartUploader.ClassifyFailure(status));
+            Assert.Equal(expected, PartUploader.ClassifyFailure(new HttpRequestException("failed", null, status)));
+        }
+
+        [Fact]
+        public void ClassifyFailure_RetriesNetworkAndFileErrors()
+        {
+            Assert.Equal(UploadResult.TransientFailure, PartUploader.ClassifyFailure(new HttpRequestException("no route")));
+            Assert.Equal(UploadResult.TransientFailure, PartUploader.ClassifyFailure(new TaskCanceledException()));
+            Assert.Equal(UploadResult.TransientFailure, PartUploader.ClassifyFailure(new IOException("locked")));
+            Assert.Equal(UploadResult.PermanentFailure, PartUploader.ClassifyFailure(new InvalidOperationException()));
+        }
+
+        private static Func<RecordedRequest, HttpResponseMessage?> FailSignedUrls(HttpStatusCode status, int times = int.MaxValue)
+        {
+            var failures = 0;
+            return request =>
+            {
+                if (!request.Path.EndsWith("/storage/signed-upload-urls") || failures++ >= times)
+                    return null;
+                return FakeHttpHandler.Json(status, @"{ ""message"": ""Signed URL refused"" }");
+            };
+        }
+
+        [Fact]
+        public async Task SignedUrl400_FailsWithoutRetrying()
+        {
+            using var harness = new ServiceHarness();
+            harness.Api.Override = FailSignedUrls(HttpStatusCode.BadRequest);
+            var file = harness.WriteFile("cube.stl");
+
+            var results = await harness.Service.UploadFilesOnce(new[] { file });
+
+            Assert.False(results[file]);
+            Assert.Equal(1, harness.Api.SignedUrlRequestCount);
+            Assert.Empty(harness.Storage.Puts);
+            var failed = Assert.Single(harness.Service.GetFailedUploads());
+            Assert.Equal(400, failed.HttpStatus);
+            Assert.Contains("Signed URL refused", failed.Reason);
+        }
+
+        [Fact]
+        public async Task SignedUrl401_PausesUploadsAndFailsTheOneShotUpload()
+        {
+            using var harness = new ServiceHarness();
+            harness.Api.Override = FailSignedUrls(HttpStatusCode.Unauthorized);
+            var file = harness.WriteFile("cube.stl");
+
+            var upload = harness.Service.UploadFilesOnce(new[] { file });
+            var finished = await Task.WhenAny(upload, Task.Delay(TimeSpan.FromSeconds(10)));
+
+            Assert.Same(upload, finished);
+            Assert.False(upload.Result[file]);
+            Assert.Equal(1, harness.Api.SignedUrlRequestCount);
+            Assert.Contains("HTTP 401", harness.Service.UploadsPausedReason);
+            Assert.Contains(harness.Notifications, n => n.title == "Uploads paused" && n.isError);
+            // A rejected key isn't the file's fault - it isn't put on the failed list
+            Assert.Empty(harness.Service.GetFailedUploads());
+        }
+
+        [Fact]
+        public async Task SignedUrl503_IsRetried()
+        {
+            using var harness = new ServiceHarness();
+            harness.Api.Override = FailSignedUrls(HttpStatusCode.ServiceUnavailable, times: 1);
+            var file = harness.WriteFile("cube.stl");
+
+            var results = await harness.Service.UploadFilesOnce(new[] { file });
+
+            Assert.True(results[file]);
+            Assert.Equal(2, harness.Api.SignedUrlRequestCount);
+            Assert.Equal(new[] { "cube.stl" }, harness.Storage.UploadedNames);
+            Assert.Empty(harness.Service.GetFailedUploads());
+        }
+
+        [Fact]
+        public async Task SignedUrl429_WaitsForRetryAfter()
+        {
+            using var harness = new ServiceHarness();
+            var limited = false;
+            harness.Api.Override = request =>
+            {
+                if (!request.Path.EndsWith("/storage/signed-upload-urls") || limited)
+                    return null;
+                limited = true;
+                var response = FakeHttpHandler.Json(HttpStatusCode.TooManyRequests, "{}");
+                response.Headers.RetryAfter = new RetryConditionHeaderValue(TimeSpan.FromSeconds(1));
+                return response;
+            };
+            var file = harness.WriteFile("cube.stl");
+
+            var results = await harness.Service.UploadFilesOnce(new[] { file });
+
+            Assert.True(results[file]);
+            var requests = harness.Api.RequestsTo(HttpMethod.Post, "/storage/signed-upload-urls");
+            Assert.Equal(2, requests.Count);
+            Assert.True(requests[1].ReceivedUtc - requests[0].ReceivedUtc >= TimeSpan.FromMilliseconds(900));
+            Assert.Contains(harness.Notifications, n => n.title == "Rate limited");
+            Assert.Equal(new[] { "cube.stl" }, harness.Storage.UploadedNames);
+        }
+    }
+}
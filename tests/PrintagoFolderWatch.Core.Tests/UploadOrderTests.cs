@@ -0,0 +1,79 @@
+using System;
+using System.Collections.Generic;
+using System.Linq;
+using System.Threading.Tasks;
+using PrintagoFolderWatch.Core.Tests.Fakes;
+using Xunit;
+
+namespace PrintagoFolderWatch.Core.Tests
+{
+    public class UploadOrderTests
+    {
+        [Fact]
+        public void LiveChangeIsDequeuedBeforeTheScanBacklog()
+        {
+            using var harness = new ServiceHarness();
+            var scanned = Enumerable.Range(0, 100).Select(i => harness.WriteFile($"scan/part{i:000}.stl")).ToList();
+            var changed = harness.WriteFile("changed.stl");
+
+            foreach (var path in scanned)
+            {
+                harness.Service.EnqueueUpload(path, fromScan: true);
+            }
+            harness.Service.EnqueueUpload(changed);
+
+            var order = Dequeue(harness.Service);
+            Assert.Equal(101, order.Count);
+            Assert.Equal(changed, order[0]);
+            Assert.Equal(scanned, order.Skip(1));
+        }
+
+        [Fact]
+        public void ScannedFileThatChangesMovesUpOnce()
+        {
+            using var harness = new ServiceHarness();
+            var scanned = Enumerable.Range(0, 10).Select(i => harness.WriteFile($"part{i}.stl")).ToList();
+            foreach (var path in scanned)
+            {
+                harness.Service.EnqueueUpload(path, fromScan: true);
+            }
+
+            Assert.True(harness.Service.EnqueueUpload(scanned[7]));
+
+            var order = Dequeue(harness.Service);
+            Assert.Equal(scanned[7], order[0]);
+            Assert.Equal(scanned.Where((_, i) => i != 7), order.Skip(1));
+        }
+
+        [Fact]
+        public async Task ChangeWhileTheInitialSyncIsUploading_GoesNext()
+        {
+            using var harness = new ServiceHarness(config => config.MaxParallelUploads = 1);
+            for (int i = 0; i < 20; i++)
+            {
+                harness.WriteFile($"backlog/part{i:00}.stl", $"solid part{i}\n");
+            }
+            await Task.Delay(harness.Config.DebounceMs); // So the scan finds them already settled
+
+            await harness.StartAsync();
+            await TestEnvironment.WaitUntil(() => harness.Storage.StartedCount > 0, TimeSpan.FromSeconds(15), "the first backlog upload");
+            harness.WriteFile("changed.stl");
+
+            await TestEnvironment.WaitUntil(() => harness.Storage.UploadedNames.Contains("changed.stl"), TimeSpan.FromSeconds(30), "the changed file's upload");
+
+            // At most one more backlog file had started before the change was queued
+            var position = Array.IndexOf(harness.Storage.UploadedNames, "changed.stl");
+            Assert.InRange(position, 1, 2);
+        }
+
+        private static List<string> Dequeue(FileWatcherService service)
+        {
+            var order = new List<string>();
+            while (service.TryDequeueUpload(out var path))
+            {
+                order.Add(path);
+            }
+            return order;
+        }
+    }
+}